@@ -0,0 +1,198 @@
+package flextime
+
+import (
+	"context"
+	"time"
+)
+
+// Units of time, from the least significant to the most significant.
+const (
+	unitNone = iota
+	unitNanosecond
+	unitSecond
+	unitMinute
+	unitHour
+	unitDay
+	unitMonth
+	unitYear
+)
+
+// fieldUnits maps field kinds, see fieldKinds, to the most significant unit they set.
+// Fields not listed, e.g. day of week or time zone, set no unit by themselves.
+var fieldUnits = map[string]int{
+	"year":              unitYear,
+	"ISO year":          unitYear,
+	"epoch":             unitYear,
+	"month":             unitMonth,
+	"quarter":           unitMonth,
+	"day of year":       unitMonth,
+	"ISO week":          unitMonth,
+	"day of month":      unitDay,
+	"hour":              unitHour,
+	"time of day":       unitHour,
+	"minute":            unitMinute,
+	"second":            unitSecond,
+	"fractional second": unitNanosecond,
+}
+
+// hasDayOfYear reports whether chunks contain a day of year token, e.g. DDD.
+func hasDayOfYear(chunks []layoutChunk) bool {
+	for _, c := range chunks {
+		if c.isToken() && (fieldKindOf(c.token) == "day of year" || c.goFmt == "002") {
+			return true
+		}
+	}
+	return false
+}
+
+// dateOfYearDay returns month and day of the yday-th day of year.
+// ok is false if year has no such day, i.e. 366 in a common year.
+func dateOfYearDay(year, yday int) (month time.Month, day int, ok bool) {
+	d := time.Date(year, time.January, yday, 0, 0, 0, 0, time.UTC)
+	return d.Month(), d.Day(), d.Year() == year
+}
+
+// mostSignificantUnit returns the most significant unit set by chunks.
+func mostSignificantUnit(chunks []layoutChunk) int {
+	most := unitNone
+	for _, c := range chunks {
+		if !c.isToken() {
+			continue
+		}
+		kind := fieldKindOf(c.token)
+		if kind == "" && c.goFmt != "" {
+			// tokens registered to a Dialect are known by their Go reference layouts.
+			for token, goFmt := range tokenTable {
+				if string(goFmt) == c.goFmt {
+					kind = fieldKindOf(token)
+					break
+				}
+			}
+		}
+		if unit := fieldUnits[kind]; unit > most {
+			most = unit
+		}
+	}
+	return most
+}
+
+// ParseWithDefault is like Parse but takes fields the layout does not specify from base.
+// Fields more significant than any field the layout sets are those of base,
+// e.g. HH:mm is on the date of base and MM-DD is in the year of base.
+// Less significant fields are zero as Parse does, e.g. YYYY-MM-DD is at midnight.
+// Values without time zone information are parsed in the location of base.
+//
+// It returns *time.ParseError if the day does not exist in the month taken from base, e.g. DD of 31 in June.
+func (l *Layout) ParseWithDefault(value string, base time.Time) (time.Time, error) {
+	loc := base.Location()
+	t, c, err := l.parseCandidate(context.Background(), value, loc, loc, l.parseOpts)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	most := mostSignificantUnit(c.chunks)
+	year, month, day := t.Date()
+	hour, min, sec := t.Clock()
+	nsec := t.Nanosecond()
+	baseYear, baseMonth, baseDay := base.Date()
+	baseHour, baseMin, baseSec := base.Clock()
+	if most < unitYear {
+		year = baseYear
+		if hasDayOfYear(c.chunks) {
+			// t is in year 0, a leap year. Count the day of year again in the year of base.
+			var ok bool
+			if month, day, ok = dateOfYearDay(year, t.YearDay()); !ok {
+				return time.Time{}, &time.ParseError{
+					Layout:  l.flexLayout,
+					Value:   value,
+					Message: ": day-of-year out of range",
+				}
+			}
+		}
+	}
+	if most < unitMonth {
+		month = baseMonth
+	}
+	if most < unitDay {
+		day = baseDay
+	}
+	if most < unitHour {
+		hour = baseHour
+	}
+	if most < unitMinute {
+		min = baseMin
+	}
+	if most < unitSecond {
+		sec = baseSec
+	}
+	if most < unitNanosecond {
+		nsec = base.Nanosecond()
+	}
+	if day > daysIn(month, year) {
+		return time.Time{}, &time.ParseError{
+			Layout:  l.flexLayout,
+			Value:   value,
+			Message: ": day out of range",
+		}
+	}
+	return time.Date(year, month, day, hour, min, sec, nsec, t.Location()), nil
+}
+
+// ParseWithDefault parses value by flexLayout, taking fields the layout does not specify from base.
+// See (*Layout).ParseWithDefault.
+func ParseWithDefault(flexLayout, value string, base time.Time) (time.Time, error) {
+	l, err := compileCached(flexLayout)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return l.ParseWithDefault(value, base)
+}
+
+// ParseInLocationWithBase is like ParseInLocation but the year is that of base in loc
+// if the layout has no year, instead of year 0.
+// Other fields are not taken from base, e.g. MMM DD HH:mm:ss, the timestamp of syslog, is on the date in the year of base.
+// It does not guess the previous year for a date after base, e.g. Dec 31 with base of Jan 1,
+// so compare the result with base if it may be.
+//
+// It returns *time.ParseError if the day does not exist in the year of base, i.e. Feb 29 in a common year.
+func (l *Layout) ParseInLocationWithBase(value string, loc *time.Location, base time.Time) (time.Time, error) {
+	t, c, err := l.parseCandidate(context.Background(), value, loc, loc, l.parseOpts)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if mostSignificantUnit(c.chunks) == unitYear {
+		return t, nil
+	}
+	year := base.In(loc).Year()
+	_, month, day := t.Date()
+	if hasDayOfYear(c.chunks) {
+		// t is in year 0, a leap year. Count the day of year again in the year of base.
+		var ok bool
+		if month, day, ok = dateOfYearDay(year, t.YearDay()); !ok {
+			return time.Time{}, &time.ParseError{
+				Layout:  l.flexLayout,
+				Value:   value,
+				Message: ": day-of-year out of range",
+			}
+		}
+	}
+	if day > daysIn(month, year) {
+		return time.Time{}, &time.ParseError{
+			Layout:  l.flexLayout,
+			Value:   value,
+			Message: ": day out of range",
+		}
+	}
+	hour, min, sec := t.Clock()
+	return time.Date(year, month, day, hour, min, sec, t.Nanosecond(), t.Location()), nil
+}
+
+// ParseInLocationWithBase parses value by flexLayout in loc, taking the year from base if the layout has none.
+// See (*Layout).ParseInLocationWithBase.
+func ParseInLocationWithBase(flexLayout, value string, loc *time.Location, base time.Time) (time.Time, error) {
+	l, err := compileCached(flexLayout)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return l.ParseInLocationWithBase(value, loc, base)
+}
@@ -0,0 +1,154 @@
+package flextime_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ngicks/flextime"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseWithDefault(t *testing.T) {
+	base := time.Date(2022, time.October, 20, 23, 16, 22, 168000000, jst)
+
+	cases := []struct {
+		flexLayout string
+		value      string
+		expected   time.Time
+	}{
+		// time only: on the date of base.
+		{"HH:mm", "14:30", time.Date(2022, time.October, 20, 14, 30, 0, 0, jst)},
+		{"HH:mm:ss.SSS", "14:30:01.123", time.Date(2022, time.October, 20, 14, 30, 1, 123000000, jst)},
+		{"mm:ss", "30:01", time.Date(2022, time.October, 20, 23, 30, 1, 0, jst)},
+		// date only: at midnight.
+		{"YYYY-MM-DD", "2021-03-04", time.Date(2021, time.March, 4, 0, 0, 0, 0, jst)},
+		{"YYYY-MM", "2021-03", time.Date(2021, time.March, 1, 0, 0, 0, 0, jst)},
+		// partial.
+		{"MM-DD", "03-04", time.Date(2022, time.March, 4, 0, 0, 0, 0, jst)},
+		{"DD HH:mm", "04 14:30", time.Date(2022, time.October, 4, 14, 30, 0, 0, jst)},
+		{"Do h:mm A", "4th 2:30 PM", time.Date(2022, time.October, 4, 14, 30, 0, 0, jst)},
+		{"MM-DD[ HH:mm]", "03-04 14:30", time.Date(2022, time.March, 4, 14, 30, 0, 0, jst)},
+		// zone in value wins over the location of base.
+		{"HH:mmZ", "14:30Z", time.Date(2022, time.October, 20, 14, 30, 0, 0, time.UTC)},
+		// nothing is specified.
+		{"'now'", "now", base},
+	}
+
+	for _, testCase := range cases {
+		parsed, err := flextime.ParseWithDefault(testCase.flexLayout, testCase.value, base)
+		require.NoError(t, err, "layout = %s", testCase.flexLayout)
+		assert.True(t, testCase.expected.Equal(parsed), "layout = %s, expected = %s, parsed = %s", testCase.flexLayout, testCase.expected, parsed)
+		assert.Equal(t, testCase.expected.Location().String(), parsed.Location().String(), "layout = %s", testCase.flexLayout)
+	}
+
+	// the day does not exist in the month of base.
+	_, err := flextime.ParseWithDefault("DD", "31", time.Date(2022, time.June, 1, 0, 0, 0, 0, time.UTC))
+	var parseErr *time.ParseError
+	assert.ErrorAs(t, err, &parseErr)
+
+	_, err = flextime.ParseWithDefault("HH:mm", "14-30", base)
+	assert.ErrorAs(t, err, &parseErr)
+}
+
+func TestParseWithDefaultDayOfYear(t *testing.T) {
+	common := time.Date(2023, time.June, 1, 12, 0, 0, 0, time.UTC)
+	leap := time.Date(2024, time.June, 1, 12, 0, 0, 0, time.UTC)
+
+	cases := []struct {
+		flexLayout string
+		value      string
+		base       time.Time
+		expected   time.Time
+	}{
+		{"DDD", "060", common, time.Date(2023, time.March, 1, 0, 0, 0, 0, time.UTC)},
+		{"DDD", "100", common, time.Date(2023, time.April, 10, 0, 0, 0, 0, time.UTC)},
+		{"DDD", "365", common, time.Date(2023, time.December, 31, 0, 0, 0, 0, time.UTC)},
+		{"DDD", "060", leap, time.Date(2024, time.February, 29, 0, 0, 0, 0, time.UTC)},
+		{"DDD", "100", leap, time.Date(2024, time.April, 9, 0, 0, 0, 0, time.UTC)},
+		{"DDD", "366", leap, time.Date(2024, time.December, 31, 0, 0, 0, 0, time.UTC)},
+		{"ddd HH:mm", "100 14:30", common, time.Date(2023, time.April, 10, 14, 30, 0, 0, time.UTC)},
+		// the year in value wins.
+		{"YYYY-DDD", "2024-100", common, time.Date(2024, time.April, 9, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, testCase := range cases {
+		parsed, err := flextime.ParseWithDefault(testCase.flexLayout, testCase.value, testCase.base)
+		require.NoError(t, err, "layout = %s, value = %s", testCase.flexLayout, testCase.value)
+		assert.True(t, testCase.expected.Equal(parsed), "layout = %s, value = %s, parsed = %s", testCase.flexLayout, testCase.value, parsed)
+	}
+
+	_, err := flextime.ParseWithDefault("DDD", "366", common)
+	var parseErr *time.ParseError
+	assert.ErrorAs(t, err, &parseErr)
+}
+
+func TestParseInLocationWithBase(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	require.NoError(t, err)
+	// 2023-01-01 05:00 in Tokyo.
+	base := time.Date(2022, time.December, 31, 20, 0, 0, 0, time.UTC)
+
+	cases := []struct {
+		flexLayout string
+		value      string
+		loc        *time.Location
+		expected   time.Time
+	}{
+		// syslog timestamp. the year is of base in loc.
+		{"MMM _D HH:mm:ss", "Jan  1 04:59:59", tokyo, time.Date(2023, time.January, 1, 4, 59, 59, 0, tokyo)},
+		{"MMM _D HH:mm:ss", "Dec 31 23:59:59", time.UTC, time.Date(2022, time.December, 31, 23, 59, 59, 0, time.UTC)},
+		// no guess of the previous year.
+		{"MMM _D HH:mm:ss", "Dec 31 23:59:59", tokyo, time.Date(2023, time.December, 31, 23, 59, 59, 0, tokyo)},
+		// other fields are not taken from base.
+		{"HH:mm", "14:30", tokyo, time.Date(2023, time.January, 1, 14, 30, 0, 0, tokyo)},
+		// the year in value wins.
+		{"YYYY MMM _D", "2021 Mar  4", tokyo, time.Date(2021, time.March, 4, 0, 0, 0, 0, tokyo)},
+		// zone in value wins over loc.
+		{"MMM _D HH:mmZ", "Jan  1 04:59Z", tokyo, time.Date(2023, time.January, 1, 4, 59, 0, 0, time.UTC)},
+	}
+
+	for _, testCase := range cases {
+		parsed, err := flextime.ParseInLocationWithBase(testCase.flexLayout, testCase.value, testCase.loc, base)
+		require.NoError(t, err, "layout = %s, value = %s", testCase.flexLayout, testCase.value)
+		assert.True(t, testCase.expected.Equal(parsed), "layout = %s, expected = %s, parsed = %s", testCase.flexLayout, testCase.expected, parsed)
+		assert.Equal(t, testCase.expected.Location().String(), parsed.Location().String(), "layout = %s", testCase.flexLayout)
+	}
+
+	// Feb 29 in a common year.
+	_, err = flextime.ParseInLocationWithBase("MMM DD", "Feb 29", time.UTC, base)
+	var parseErr *time.ParseError
+	assert.ErrorAs(t, err, &parseErr)
+	parsed, err := flextime.ParseInLocationWithBase("MMM DD", "Feb 29", time.UTC, base.AddDate(2, 0, 0))
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2024, time.February, 29, 0, 0, 0, 0, time.UTC), parsed)
+
+	_, err = flextime.ParseInLocationWithBase("MMM DD", "Foo 29", time.UTC, base)
+	assert.Error(t, err)
+}
+
+func TestParseInLocationWithBaseDayOfYear(t *testing.T) {
+	common := time.Date(2023, time.June, 1, 12, 0, 0, 0, time.UTC)
+	leap := time.Date(2024, time.June, 1, 12, 0, 0, 0, time.UTC)
+
+	cases := []struct {
+		value    string
+		base     time.Time
+		expected time.Time
+	}{
+		{"059 10:00", common, time.Date(2023, time.February, 28, 10, 0, 0, 0, time.UTC)},
+		{"060 10:00", common, time.Date(2023, time.March, 1, 10, 0, 0, 0, time.UTC)},
+		{"100 10:00", common, time.Date(2023, time.April, 10, 10, 0, 0, 0, time.UTC)},
+		{"060 10:00", leap, time.Date(2024, time.February, 29, 10, 0, 0, 0, time.UTC)},
+		{"100 10:00", leap, time.Date(2024, time.April, 9, 10, 0, 0, 0, time.UTC)},
+		{"366 10:00", leap, time.Date(2024, time.December, 31, 10, 0, 0, 0, time.UTC)},
+	}
+	for _, testCase := range cases {
+		parsed, err := flextime.ParseInLocationWithBase("DDD HH:mm", testCase.value, time.UTC, testCase.base)
+		require.NoError(t, err, "value = %s", testCase.value)
+		assert.True(t, testCase.expected.Equal(parsed), "value = %s, parsed = %s", testCase.value, parsed)
+	}
+
+	_, err := flextime.ParseInLocationWithBase("DDD HH:mm", "366 10:00", time.UTC, common)
+	var parseErr *time.ParseError
+	assert.ErrorAs(t, err, &parseErr)
+}
@@ -0,0 +1,94 @@
+package flextime_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ngicks/flextime"
+)
+
+// benchCases are representative layouts and values for benchmarks.
+// Times are in fixed zones so that results do not depend on tzdata.
+var benchCases = []struct {
+	name       string
+	flexLayout string
+	value      string
+}{
+	{"date", `YYYY-MM-DD`, "2022-10-20"},
+	{"rfc3339", `YYYY-MM-DDTHH:mm:ss.SSSZ`, "2022-10-20T23:16:22.168+09:00"},
+	{"optional", `YYYY-MM-DD[THH[:mm[:ss.SSS]]][Z]`, "2022-10-20T23:16"},
+	{"alternation", `YYYY(-|/)MM(-|/)DD[ HH:mm]`, "2022/10/20 23:16"},
+	{"computed", `ww, Do MMMM YYYY 'at' h:mm aa`, "Thursday, 20th October 2022 at 11:16 p.m."},
+	{"names", `w, DD MMM YYYY HH:mm:ss -0700`, "Thu, 20 Oct 2022 23:16:22 +0900"},
+}
+
+// benchOptionalHeavy has 2^12 combinations, which is costly to enumerate.
+var benchOptionalHeavy = strings.Repeat(`[HH:]`, 12)
+
+var benchTime = time.Date(2022, time.October, 20, 23, 16, 22, 168123456, time.FixedZone("", 9*60*60))
+
+func BenchmarkParseLayouts(b *testing.B) {
+	for _, bc := range benchCases {
+		b.Run(bc.name, func(b *testing.B) {
+			l, err := flextime.Compile(bc.flexLayout)
+			if err != nil {
+				b.Fatal(err)
+			}
+			if _, err := l.Parse(bc.value); err != nil {
+				b.Fatal(err)
+			}
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				_, _ = l.Parse(bc.value)
+			}
+		})
+	}
+}
+
+func BenchmarkParseFailure(b *testing.B) {
+	l, err := flextime.Compile(`YYYY-MM-DD[THH[:mm[:ss.SSS]]][Z]`)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = l.Parse("2022-10-20T23:16:xx")
+	}
+}
+
+func BenchmarkFormat(b *testing.B) {
+	for _, bc := range benchCases {
+		b.Run(bc.name, func(b *testing.B) {
+			l, err := flextime.Compile(bc.flexLayout)
+			if err != nil {
+				b.Fatal(err)
+			}
+			buf := make([]byte, 0, 64)
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				buf = l.AppendFormat(buf[:0], benchTime)
+			}
+		})
+	}
+}
+
+func BenchmarkCompile(b *testing.B) {
+	compile := func(flexLayout string) func(b *testing.B) {
+		return func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := flextime.Compile(flexLayout); err != nil {
+					b.Fatal(err)
+				}
+			}
+		}
+	}
+	for _, bc := range benchCases {
+		b.Run(bc.name, compile(bc.flexLayout))
+	}
+	b.Run("optional_heavy", compile(benchOptionalHeavy))
+}
@@ -0,0 +1,232 @@
+package flextime
+
+import (
+	"strings"
+)
+
+// Builder builds a flextime layout programmatically.
+// Each method appends a token or a literal and returns the Builder itself for chaining.
+// The zero value is an empty layout ready to use.
+//
+//	layout := new(flextime.Builder).
+//		Year4().Literal("-").Month2().Literal("-").Day2().
+//		Optional(func(b *flextime.Builder) {
+//			b.Literal("T").Hour2().Literal(":").Minute2()
+//		}).
+//		String() // YYYY-MM-DD['T'HH:mm]
+type Builder struct {
+	buf strings.Builder
+	// literal is the pending literal, not yet escaped.
+	// Consecutive literals are merged and escaped at once.
+	literal string
+	// last is the last piece written to buf, a token or an escaped literal.
+	// It is used to separate pieces which would be read as another token if adjacent.
+	last string
+}
+
+// String returns the built flextime layout.
+func (b *Builder) String() string {
+	if b.literal == "" {
+		return b.buf.String()
+	}
+	return b.buf.String() + b.separator(escapeLiteral(b.literal)) + escapeLiteral(b.literal)
+}
+
+// Literal appends s as a literal.
+// Characters which could be read as tokens, optional parts or alternations are quoted.
+func (b *Builder) Literal(s string) *Builder {
+	b.literal += s
+	return b
+}
+
+// Optional appends an optional part built by fn.
+func (b *Builder) Optional(fn func(b *Builder)) *Builder {
+	b.flush()
+	inner := &Builder{last: b.last}
+	fn(inner)
+	inner.flush()
+	b.buf.WriteString("[" + inner.String() + "]")
+	b.last = inner.last
+	return b
+}
+
+// Alternation appends an alternation whose branches are built by each of branches.
+func (b *Builder) Alternation(branches ...func(b *Builder)) *Builder {
+	b.flush()
+	built := make([]string, len(branches))
+	last := b.last
+	for i, fn := range branches {
+		inner := &Builder{last: b.last}
+		fn(inner)
+		inner.flush()
+		built[i] = inner.String()
+		if i == 0 {
+			last = inner.last
+		}
+	}
+	b.buf.WriteString("(" + strings.Join(built, "|") + ")")
+	b.last = last
+	return b
+}
+
+func (b *Builder) token(token timeFormatToken) *Builder {
+	b.flush()
+	b.write(string(token))
+	return b
+}
+
+// flush writes the pending literal.
+func (b *Builder) flush() {
+	if b.literal != "" {
+		b.write(escapeLiteral(b.literal))
+		b.literal = ""
+	}
+}
+
+func (b *Builder) write(piece string) {
+	b.buf.WriteString(b.separator(piece))
+	b.buf.WriteString(piece)
+	b.last = piece
+}
+
+// separator returns an empty quote if the last piece and piece would be read differently when adjacent,
+// e.g. M followed by MMM, or . followed by SSS.
+func (b *Builder) separator(piece string) string {
+	if b.last != "" && !splitsAt(b.last+piece, len(b.last)) {
+		return "''"
+	}
+	return ""
+}
+
+// splitsAt reports whether layout is split into chunks at offset.
+func splitsAt(layout string, offset int) bool {
+	chunks, err := splitChunks(layout)
+	if err != nil {
+		return false
+	}
+	for _, c := range chunks {
+		if c.offset == offset {
+			return true
+		}
+	}
+	return false
+}
+
+// Year4 appends YYYY, 4 digit year.
+func (b *Builder) Year4() *Builder { return b.token("YYYY") }
+
+// Year2 appends YY, 2 digit year.
+func (b *Builder) Year2() *Builder { return b.token("YY") }
+
+// Era appends G, AD or BC.
+func (b *Builder) Era() *Builder { return b.token("G") }
+
+// Month2 appends MM, zero padded month.
+func (b *Builder) Month2() *Builder { return b.token("MM") }
+
+// Month appends M, month without padding.
+func (b *Builder) Month() *Builder { return b.token("M") }
+
+// MonthShort appends MMM, abbreviated month name, e.g. Jan.
+func (b *Builder) MonthShort() *Builder { return b.token("MMM") }
+
+// MonthLong appends MMMM, full month name, e.g. January.
+func (b *Builder) MonthLong() *Builder { return b.token("MMMM") }
+
+// Day2 appends DD, zero padded day of month.
+func (b *Builder) Day2() *Builder { return b.token("DD") }
+
+// Day appends D, day of month without padding.
+func (b *Builder) Day() *Builder { return b.token("D") }
+
+// DaySpacePadded appends _D, space padded day of month.
+func (b *Builder) DaySpacePadded() *Builder { return b.token("_D") }
+
+// DayOrdinal appends Do, day of month with English ordinal suffix, e.g. 1st.
+func (b *Builder) DayOrdinal() *Builder { return b.token("Do") }
+
+// YearDay3 appends DDD, zero padded day of year.
+func (b *Builder) YearDay3() *Builder { return b.token("DDD") }
+
+// YearDaySpacePadded appends __D, space padded day of year.
+func (b *Builder) YearDaySpacePadded() *Builder { return b.token("__D") }
+
+// WeekdayShort appends w, abbreviated weekday name, e.g. Mon.
+func (b *Builder) WeekdayShort() *Builder { return b.token("w") }
+
+// WeekdayLong appends ww, full weekday name, e.g. Monday.
+func (b *Builder) WeekdayLong() *Builder { return b.token("ww") }
+
+// Hour2 appends HH, zero padded hour of 24-hour clock.
+func (b *Builder) Hour2() *Builder { return b.token("HH") }
+
+// Hour appends H, hour of 24-hour clock without padding.
+func (b *Builder) Hour() *Builder { return b.token("H") }
+
+// ClockHour2 appends hh, zero padded hour of 12-hour clock.
+func (b *Builder) ClockHour2() *Builder { return b.token("hh") }
+
+// ClockHour appends h, hour of 12-hour clock without padding.
+func (b *Builder) ClockHour() *Builder { return b.token("h") }
+
+// Meridiem appends A, AM or PM.
+func (b *Builder) Meridiem() *Builder { return b.token("A") }
+
+// MeridiemLower appends a, am or pm.
+func (b *Builder) MeridiemLower() *Builder { return b.token("a") }
+
+// Minute2 appends mm, zero padded minute.
+func (b *Builder) Minute2() *Builder { return b.token("mm") }
+
+// Minute appends m, minute without padding.
+func (b *Builder) Minute() *Builder { return b.token("m") }
+
+// Second2 appends ss, zero padded second.
+func (b *Builder) Second2() *Builder { return b.token("ss") }
+
+// Second appends s, second without padding.
+func (b *Builder) Second() *Builder { return b.token("s") }
+
+// Fraction appends fractional second with a leading dot and exactly digits digits, e.g. .SSS for 3.
+// digits is clamped to 1 to 9.
+func (b *Builder) Fraction(digits int) *Builder {
+	return b.token(timeFormatToken("." + strings.Repeat("S", clampDigits(digits))))
+}
+
+// FractionTrimmed appends fractional second with a leading dot and up to digits digits, e.g. .999 for 3.
+// Trailing zeros are omitted on format, and the dot is omitted if the fraction is zero.
+// digits is clamped to 1 to 9.
+func (b *Builder) FractionTrimmed(digits int) *Builder {
+	return b.token(timeFormatToken("." + strings.Repeat("9", clampDigits(digits))))
+}
+
+func clampDigits(digits int) int {
+	if digits < 1 {
+		return 1
+	}
+	if digits > 9 {
+		return 9
+	}
+	return digits
+}
+
+// ZoneAbbrev appends MST, time zone abbreviation.
+func (b *Builder) ZoneAbbrev() *Builder { return b.token("MST") }
+
+// ZoneName appends zzzz, IANA time zone name.
+func (b *Builder) ZoneName() *Builder { return b.token("zzzz") }
+
+// ZoneOffset appends Z, numeric offset with colon, or Z for UTC, e.g. +09:00.
+func (b *Builder) ZoneOffset() *Builder { return b.token("Z") }
+
+// ZoneOffsetNoColon appends ZZ, numeric offset without colon, or Z for UTC, e.g. +0900.
+func (b *Builder) ZoneOffsetNoColon() *Builder { return b.token("ZZ") }
+
+// NumericOffset appends -07:00, numeric offset with colon, never Z.
+func (b *Builder) NumericOffset() *Builder { return b.token("-07:00") }
+
+// Unix appends X, Unix time in seconds.
+func (b *Builder) Unix() *Builder { return b.token("X") }
+
+// UnixMilli appends x, Unix time in milliseconds.
+func (b *Builder) UnixMilli() *Builder { return b.token("x") }
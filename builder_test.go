@@ -0,0 +1,79 @@
+package flextime_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ngicks/flextime"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuilder(t *testing.T) {
+	cases := []struct {
+		built    string
+		expected string
+	}{
+		{
+			new(flextime.Builder).
+				Year4().Literal("-").Month2().Literal("-").Day2().
+				Optional(func(b *flextime.Builder) {
+					b.Literal("T").Hour2().
+						Optional(func(b *flextime.Builder) {
+							b.Literal(":").Minute2().
+								Optional(func(b *flextime.Builder) { b.Literal(":").Second2().Fraction(3) })
+						})
+				}).
+				Optional(func(b *flextime.Builder) { b.ZoneOffset() }).
+				String(),
+			`YYYY-MM-DD['T'HH[:mm[:ss.SSS]]][Z]`,
+		},
+		{
+			new(flextime.Builder).
+				WeekdayShort().Literal(", ").Day2().Literal(" ").MonthShort().Literal(" ").Year4().
+				Literal(" ").Hour2().Literal(":").Minute2().Literal(":").Second2().Literal(" ").ZoneAbbrev().
+				String(),
+			`w, DD MMM YYYY HH:mm:ss MST`,
+		},
+		{
+			new(flextime.Builder).
+				Alternation(
+					func(b *flextime.Builder) { b.Month2().Literal("/").Day2() },
+					func(b *flextime.Builder) { b.Day2().Literal(".").Month2() },
+				).
+				Literal("/").Year4().
+				String(),
+			`(MM/DD|DD.MM)/YYYY`,
+		},
+		{
+			// literals which could be read as tokens or syntax are quoted.
+			new(flextime.Builder).ClockHour().Literal(" o'clock [sharp] (|)").String(),
+			`h 'o'\''clock' '[sharp]' '(|)'`,
+		},
+		{
+			// adjacent pieces which would be read as another token are separated.
+			new(flextime.Builder).Month().MonthShort().Literal(".").FractionTrimmed(3).Literal(".").String(),
+			`M''MMM..999.`,
+		},
+		{
+			new(flextime.Builder).Second2().Literal(".").String(),
+			`ss.`,
+		},
+		{
+			new(flextime.Builder).MonthShort().Literal(" ").DaySpacePadded().Literal("_").Day().YearDaySpacePadded().String(),
+			`MMM _D'_'D__D`,
+		},
+	}
+
+	for _, testCase := range cases {
+		assert.Equal(t, testCase.expected, testCase.built)
+		_, err := flextime.Compile(testCase.built)
+		assert.NoError(t, err, "layout = %s", testCase.built)
+	}
+
+	target := time.Date(2022, time.October, 20, 23, 16, 22, 168000000, time.UTC)
+	separated := new(flextime.Builder).Month().MonthShort().Literal(".").Second2().Literal(".").Fraction(3).String()
+	formatted, err := flextime.Format(target, separated)
+	require.NoError(t, err)
+	assert.Equal(t, "10Oct.22..168", formatted)
+}
@@ -0,0 +1,84 @@
+package flextime
+
+import "strings"
+
+// canonicalTokens maps aliases of tokens to their canonical spellings.
+// Tokens not listed are canonical.
+var canonicalTokens = map[timeFormatToken]timeFormatToken{
+	"yyyy":   "YYYY",
+	"yy":     "YY",
+	"dd":     "DD",
+	"d":      "D",
+	"ddd":    "DDD",
+	"_d":     "_D",
+	"__d":    "__D",
+	"Z0700":  "ZZ",
+	"Z07:00": "Z",
+}
+
+// canonicalToken returns the canonical spelling of token.
+// Fractional seconds of 0, e.g. .000, are spelled by S, e.g. .SSS, since they are same.
+func canonicalToken(token string) string {
+	if canonical, ok := canonicalTokens[timeFormatToken(token)]; ok {
+		return string(canonical)
+	}
+	if isFracToken(timeFormatToken(token)) && token[1] == '0' {
+		return token[:1] + strings.Repeat("S", len(token)-1)
+	}
+	return token
+}
+
+// Canonicalize returns flexLayout with tokens re-emitted in their canonical spellings,
+// so that layouts meaning the same are spelled the same, e.g. yyyy-MM-dd HH:mm Z07:00 is YYYY-MM-DD HH:mm Z.
+//
+// Canonical spellings are YYYY and YY for years, DD, D, DDD, _D and __D for days,
+// ZZ and Z for Z0700 and Z07:00, and S for fractional seconds of 0, e.g. .SSS for .000.
+// Literals, including optional parts, alternations, quotes and escapes, are kept as they are.
+// A token keeps its spelling if the canonical one would be read differently along with the text after it,
+// e.g. Z0700 of Z0700Z, since ZZZ is another token.
+//
+// It returns *FormatError if flexLayout contains an invalid token.
+func Canonicalize(flexLayout string) (string, error) {
+	tokens, err := Tokenize(flexLayout)
+	if err != nil {
+		return "", err
+	}
+	expected := canonicalSequence(tokens)
+	var b strings.Builder
+	for _, t := range tokens {
+		canonical := canonicalToken(t.Value)
+		if !t.IsTimeToken || canonical == t.Value {
+			b.WriteString(t.Raw)
+			continue
+		}
+		candidate := b.String() + canonical + flexLayout[t.Offset+len(t.Raw):]
+		if replaced, err := Tokenize(candidate); err == nil && canonicalSequence(replaced) == expected {
+			b.WriteString(canonical)
+		} else {
+			b.WriteString(t.Raw)
+		}
+	}
+	return b.String(), nil
+}
+
+// canonicalSequence returns a string which is same for token sequences meaning the same:
+// tokens in canonical spellings and literals joined, each prefixed by its kind.
+func canonicalSequence(tokens []Token) string {
+	var b strings.Builder
+	literal := false
+	for _, t := range tokens {
+		switch {
+		case t.IsTimeToken:
+			b.WriteString("\x00t")
+			b.WriteString(canonicalToken(t.Value))
+			literal = false
+		default:
+			if !literal {
+				b.WriteString("\x00l")
+			}
+			b.WriteString(t.Value)
+			literal = true
+		}
+	}
+	return b.String()
+}
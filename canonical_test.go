@@ -0,0 +1,54 @@
+package flextime_test
+
+import (
+	"testing"
+
+	"github.com/ngicks/flextime"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCanonicalize(t *testing.T) {
+	type testCase struct {
+		input    string
+		expected string
+	}
+	for _, tc := range []testCase{
+		{`YYYY-MM-DD`, `YYYY-MM-DD`},
+		{`yyyy-MM-dd`, `YYYY-MM-DD`},
+		{`yy/M/d`, `YY/M/D`},
+		{`yyyy ddd`, `YYYY DDD`},
+		{`__d _d`, `__D _D`},
+		{`HH:mm Z07:00`, `HH:mm Z`},
+		{`HH:mmZ0700`, `HH:mmZZ`},
+		{`HH:mm:ss.000`, `HH:mm:ss.SSS`},
+		{`HH:mm:ss,000`, `HH:mm:ss,SSS`},
+		{`HH:mm:ss.999`, `HH:mm:ss.999`},
+		// literals, optional parts and alternations are kept.
+		{`yyyy-MM-dd['T'HH[:mm]](Z07:00|MST)`, `YYYY-MM-DD['T'HH[:mm]](Z|MST)`},
+		{`yyyy\y 'yyyy'`, `YYYY\y 'yyyy'`},
+		// ZZ followed by Z would be ZZZ.
+		{`Z0700Z`, `Z0700Z`},
+	} {
+		canonical, err := flextime.Canonicalize(tc.input)
+		require.NoError(t, err, "input = %s", tc.input)
+		assert.Equal(t, tc.expected, canonical, "input = %s", tc.input)
+
+		// canonicalization is idempotent and keeps the meaning.
+		again, err := flextime.Canonicalize(canonical)
+		require.NoError(t, err)
+		assert.Equal(t, canonical, again)
+		assert.Equal(t, flextime.MustCompile(tc.input).GoLayouts(), flextime.MustCompile(canonical).GoLayouts())
+	}
+
+	// aliases canonicalize consistently.
+	for _, input := range []string{`yyyy-MM-dd HH:mm:ss.000 Z07:00`, `YYYY-MM-dd HH:mm:ss.SSS Z`, `yyyy-MM-DD HH:mm:ss.SSS Z07:00`} {
+		canonical, err := flextime.Canonicalize(input)
+		require.NoError(t, err)
+		assert.Equal(t, `YYYY-MM-DD HH:mm:ss.SSS Z`, canonical, "input = %s", input)
+	}
+
+	_, err := flextime.Canonicalize(`YYYY-MM-DD SSSS`)
+	var formatErr *flextime.FormatError
+	assert.ErrorAs(t, err, &formatErr)
+}
@@ -0,0 +1,285 @@
+package flextime
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	optionalstring "github.com/ngicks/flextime/optional_string"
+)
+
+// layoutChunk is a piece of a flextime layout without optional parts.
+// It is either a literal string or a time token.
+type layoutChunk struct {
+	// offset is the byte offset of the chunk in the layout it is split from.
+	offset  int
+	literal string
+	// quoted is true if literal is quoted by single quotes or escaped by a backslash.
+	quoted bool
+	token  timeFormatToken
+	// goFmt is Go reference layout of token, looked up when split.
+	// It is empty if token is computed.
+	goFmt string
+	// computed is callbacks of token if it is registered by RegisterComputedToken, looked up when split.
+	computed *computedToken
+}
+
+func (c layoutChunk) isToken() bool {
+	return c.token != ""
+}
+
+// computedToken returns callbacks of c if c is a computed token, built-in or registered.
+func (c layoutChunk) computedToken() (computedToken, bool) {
+	if c.computed != nil {
+		return *c.computed, true
+	}
+	computed, ok := computedTokenTable[c.token]
+	return computed, ok
+}
+
+func (c layoutChunk) isComputed() bool {
+	_, ok := c.computedToken()
+	return ok
+}
+
+// splitChunks is like (*tokenTables).splitChunks with the default tables.
+func splitChunks(layout string) ([]layoutChunk, error) {
+	return defaultTables().splitChunks(layout)
+}
+
+// splitChunksRaw is like (*tokenTables).splitChunksRaw with the default tables.
+func splitChunksRaw(input optionalstring.RawString) ([]layoutChunk, error) {
+	return defaultTables().splitChunksRaw(input)
+}
+
+// splitChunks splits input, a flextime layout without optional parts, into chunks.
+func (tables *tokenTables) splitChunks(layout string) ([]layoutChunk, error) {
+	var chunks []layoutChunk
+	var offset int
+	input := layout
+	for len(input) > 0 {
+		prefix, found, suffix, isToken, err := tables.nextChunk(input)
+		if err != nil {
+			return nil, relocateFormatError(err, offset, layout)
+		}
+		if prefix != "" {
+			chunks = append(chunks, layoutChunk{offset: offset, literal: prefix})
+		}
+		if isToken {
+			c := layoutChunk{offset: offset + len(prefix), token: timeFormatToken(found)}
+			c.computed = tables.computed[c.token]
+			if !c.isComputed() {
+				c.goFmt = tables.toGoFmt(c.token)
+			}
+			chunks = append(chunks, c)
+		} else if found != "" {
+			chunks = append(chunks, layoutChunk{offset: offset + len(prefix), literal: found, quoted: true})
+		}
+		offset += len(input) - len(suffix)
+		input = suffix
+	}
+	if err := checkExclusive(layout, chunks); err != nil {
+		return nil, err
+	}
+	return chunks, nil
+}
+
+// splitChunksRaw is like splitChunks but splits an enumerated optional string.
+func (tables *tokenTables) splitChunksRaw(input optionalstring.RawString) ([]layoutChunk, error) {
+	var chunks []layoutChunk
+	var offset int
+	for _, vv := range input {
+		switch vv.Typ() {
+		case optionalstring.SingleQuoteEscaped, optionalstring.SlashEscaped:
+			chunks = append(chunks, layoutChunk{offset: offset, literal: vv.Unescaped(), quoted: true})
+		case optionalstring.Normal:
+			split, err := tables.splitChunks(vv.Unescaped())
+			if err != nil {
+				return nil, relocateFormatError(err, offset, input.String())
+			}
+			for _, c := range split {
+				c.offset += offset
+				chunks = append(chunks, c)
+			}
+		}
+		offset += vv.Len()
+	}
+	if err := checkExclusive(input.String(), chunks); err != nil {
+		return nil, err
+	}
+	return chunks, nil
+}
+
+// relocateFormatError shifts the index of err by offset and sets layout to it
+// if err is *FormatError, so that it points to the position in layout.
+func relocateFormatError(err error, offset int, layout string) error {
+	if formatErr, ok := err.(*FormatError); ok {
+		formatErr.idx += offset
+		formatErr.layout = layout
+	}
+	return err
+}
+
+// checkExclusive returns *FormatError
+// if chunks split from layout contain an exclusive token, e.g. X, along with other tokens.
+func checkExclusive(layout string, chunks []layoutChunk) error {
+	var exclusive, other *layoutChunk
+	for i := range chunks {
+		if !chunks[i].isToken() {
+			continue
+		}
+		if exclusiveTokens[chunks[i].token] && exclusive == nil {
+			exclusive = &chunks[i]
+		} else if other == nil {
+			other = &chunks[i]
+		}
+	}
+	if exclusive == nil || other == nil {
+		return nil
+	}
+	return &FormatError{
+		layout:   layout,
+		idx:      other.offset,
+		expected: fmt.Sprintf("must not be used with %s", exclusive.token),
+		actual:   string(other.token),
+		msg:      fmt.Sprintf("%s represents a whole instant by itself.", exclusive.token),
+	}
+}
+
+// hasComputed reports whether chunks contain a token
+// which is not expressible in Go reference layout.
+func hasComputed(chunks []layoutChunk) bool {
+	for _, c := range chunks {
+		if c.isComputed() {
+			return true
+		}
+	}
+	return false
+}
+
+// hasCrossCheckedFields reports whether chunks contain day-of-year token along with month or day token.
+func hasCrossCheckedFields(chunks []layoutChunk) bool {
+	var yday, monthOrDay bool
+	for _, c := range chunks {
+		if !c.isToken() || c.isComputed() {
+			continue
+		}
+		switch c.goFmt {
+		case "002":
+			yday = true
+		case "January", "Jan", "1", "01", "2", "02":
+			monthOrDay = true
+		}
+	}
+	return yday && monthOrDay
+}
+
+// hasEra reports whether chunks has the era token G.
+func hasEra(chunks []layoutChunk) bool {
+	for _, c := range chunks {
+		if c.token == "G" {
+			return true
+		}
+	}
+	return false
+}
+
+// hasLongYear reports whether chunks has a 4 digit year token, YYYY or yyyy.
+func hasLongYear(chunks []layoutChunk) bool {
+	for _, c := range chunks {
+		if c.isToken() && !c.isComputed() && c.goFmt == "2006" {
+			return true
+		}
+	}
+	return false
+}
+
+// chunksToGoLayout converts chunks split from layout into Go reference layout.
+// It returns *FormatError if chunks contain a computed token.
+func chunksToGoLayout(layout string, chunks []layoutChunk) (string, error) {
+	var output strings.Builder
+	// Go reference layout is usually as long as, or a bit longer than, the flextime layout.
+	output.Grow(len(layout) + len(layout)/4)
+	for _, c := range chunks {
+		if !c.isToken() {
+			output.WriteString(c.literal)
+			continue
+		}
+		if c.isComputed() {
+			return "", &FormatError{
+				layout:   layout,
+				idx:      c.offset,
+				expected: "must be a token which has Go reference layout equivalent",
+				actual:   string(c.token),
+				msg: fmt.Sprintf(
+					"%s is only supported by flextime Format and Parse.",
+					c.token,
+				),
+			}
+		}
+		output.WriteString(c.goFmt)
+	}
+	return output.String(), nil
+}
+
+// goLayoutMatches reports whether the Go reference layout converted from chunks
+// is split back into the same tokens by the time package.
+// Literals may form Go reference layout tokens on their own or together with adjacent tokens,
+// e.g. a quoted -07 or a quoted 0 followed by D.
+func goLayoutMatches(goLayout string, chunks []layoutChunk) bool {
+	rest := goLayout
+	var literal strings.Builder
+	for _, c := range chunks {
+		if !c.isToken() {
+			literal.WriteString(c.literal)
+			continue
+		}
+		prefix, std, suffix := NextStdChunk(rest)
+		if std == 0 || prefix != literal.String() ||
+			rest[len(prefix):len(rest)-len(suffix)] != string(c.goFmt) {
+			return false
+		}
+		literal.Reset()
+		rest = suffix
+	}
+	_, std, _ := NextStdChunk(rest)
+	return std == 0
+}
+
+// appendChunks formats t by chunks and appends it to b.
+// Month and weekday names are taken from locale if it is non nil.
+func appendChunks(b []byte, t time.Time, chunks []layoutChunk, locale *LocaleNames) []byte {
+	era := hasEra(chunks)
+	for _, c := range chunks {
+		if !c.isToken() {
+			b = append(b, c.literal...)
+			continue
+		}
+		if era {
+			// years are counted in the era, e.g. 0044 BC rather than -0043.
+			switch {
+			case c.token == "YYYYYY":
+				b = appendInt(b, yearOfEra(t.Year()), 6)
+				continue
+			case c.goFmt == "2006":
+				b = appendInt(b, yearOfEra(t.Year()), 4)
+				continue
+			case c.goFmt == "06":
+				b = appendInt(b, yearOfEra(t.Year())%100, 2)
+				continue
+			}
+		}
+		if computed, ok := c.computedToken(); ok {
+			b = computed.format(b, t)
+			continue
+		}
+		if locale != nil {
+			var ok bool
+			if b, ok = locale.appendName(b, t, c.goFmt); ok {
+				continue
+			}
+		}
+		b = t.AppendFormat(b, c.goFmt)
+	}
+	return b
+}
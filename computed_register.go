@@ -0,0 +1,141 @@
+package flextime
+
+import (
+	"fmt"
+	"time"
+)
+
+// ComputedFields are fields of time read by a parse callback of RegisterComputedToken.
+// Only fields in Set are used.
+type ComputedFields struct {
+	Set        FieldSet
+	Year       int
+	Month      time.Month
+	Day        int
+	YearDay    int
+	Hour       int
+	Minute     int
+	Second     int
+	Nanosecond int
+	// Weekday is checked against the date, like E and c.
+	Weekday time.Weekday
+	// Location is the time zone for FieldZone.
+	Location *time.Location
+}
+
+// RegisterComputedToken registers flexToken as a computed token of the default tokens.
+// Computed tokens have no Go reference layout equivalent:
+// format returns the text for a time,
+// and parse reads a value starting with the token and returns the fields it read
+// and the number of bytes it consumed.
+//
+// Registration affects every user of the default tokens in the program, e.g. Parse and Compile.
+// Prefer (*Dialect).RegisterComputedToken to keep tokens to layouts compiled by CompileWith.
+// RegisterComputedToken is safe for concurrent use, but is meant to be called at init time:
+// layouts compiled before registration, including those cached by package level functions,
+// and Dialects created before registration are not affected.
+// Like a token registered to a Dialect,
+// the first character of flexToken starts a token after registration.
+func RegisterComputedToken(
+	flexToken string,
+	format func(time.Time) string,
+	parse func(s string) (ComputedFields, int, error),
+) error {
+	registerMu.Lock()
+	defer registerMu.Unlock()
+	tables, err := defaultTables().withComputed(flexToken, format, parse)
+	if err != nil {
+		return err
+	}
+	defaultTablesPtr.Store(tables)
+	return nil
+}
+
+// RegisterComputedToken is like the package level RegisterComputedToken
+// but registers flexToken to d only.
+func (d *Dialect) RegisterComputedToken(
+	flexToken string,
+	format func(time.Time) string,
+	parse func(s string) (ComputedFields, int, error),
+) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	tables, err := d.tables.withComputed(flexToken, format, parse)
+	if err != nil {
+		return err
+	}
+	d.tables = tables
+	return nil
+}
+
+// withComputed returns a copy of tables with flexToken registered as a computed token.
+func (tables *tokenTables) withComputed(
+	flexToken string,
+	format func(time.Time) string,
+	parse func(s string) (ComputedFields, int, error),
+) (*tokenTables, error) {
+	if err := validateToken(flexToken); err != nil {
+		return nil, err
+	}
+	if format == nil || parse == nil {
+		return nil, fmt.Errorf("flextime: token %q: format and parse must not be nil", flexToken)
+	}
+	tt := timeFormatToken(flexToken)
+	if _, ok := tables.goFmt[tt]; ok || tables.isComputed(tt) {
+		return nil, fmt.Errorf("flextime: token %q is already registered", flexToken)
+	}
+
+	cloned := tables.clone()
+	cloned.computed[tt] = &computedToken{
+		format: func(b []byte, t time.Time) []byte { return append(b, format(t)...) },
+		parse: func(value string, f *parsedFields) (string, error) {
+			fields, n, err := parse(value)
+			if err != nil {
+				return value, err
+			}
+			if n < 0 || len(value) < n {
+				return value, fmt.Errorf("token %q consumed %d bytes of %d", flexToken, n, len(value))
+			}
+			f.setComputed(tt, fields)
+			return value[n:], nil
+		},
+	}
+	cloned.addSearch(tt)
+	return cloned, nil
+}
+
+// setComputed stores fields parsed by the registered token to f.
+func (f *parsedFields) setComputed(token timeFormatToken, fields ComputedFields) {
+	set := fields.Set
+	if set.Has(FieldYear) {
+		f.year = fields.Year
+	}
+	if set.Has(FieldMonth) {
+		f.month = int(fields.Month)
+	}
+	if set.Has(FieldDay) {
+		f.day = fields.Day
+	}
+	if set.Has(FieldYearDay) {
+		f.yday = fields.YearDay
+		f.ydayToken = token
+	}
+	if set.Has(FieldHour) {
+		f.hour = fields.Hour
+	}
+	if set.Has(FieldMinute) {
+		f.min = fields.Minute
+	}
+	if set.Has(FieldSecond) {
+		f.sec = fields.Second
+	}
+	if set.Has(FieldNano) {
+		f.nsec = fields.Nanosecond
+	}
+	if set.Has(FieldWeekday) {
+		f.weekday = int(fields.Weekday)
+	}
+	if set.Has(FieldZone) && fields.Location != nil {
+		f.z = fields.Location
+	}
+}
@@ -0,0 +1,152 @@
+package flextime_test
+
+import (
+	"errors"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ngicks/flextime"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// hhmmFormat and hhmmParse are callbacks of ~hhmm, a compact 24-hour clock.
+func hhmmFormat(t time.Time) string { return t.Format("1504") }
+
+func hhmmParse(s string) (flextime.ComputedFields, int, error) {
+	if len(s) < 4 {
+		return flextime.ComputedFields{}, 0, errors.New("too short")
+	}
+	n, err := strconv.Atoi(s[:4])
+	if err != nil {
+		return flextime.ComputedFields{}, 0, err
+	}
+	return flextime.ComputedFields{
+		Set:    flextime.FieldHour | flextime.FieldMinute,
+		Hour:   n / 100,
+		Minute: n % 100,
+	}, 4, nil
+}
+
+// newTestDialect returns a Dialect with ~hhmm and ~over, which claims more bytes than given.
+func newTestDialect(t *testing.T) *flextime.Dialect {
+	t.Helper()
+	d := flextime.NewDialect()
+	require.NoError(t, d.RegisterComputedToken("~hhmm", hhmmFormat, hhmmParse))
+	require.NoError(t, d.RegisterComputedToken(
+		"~over",
+		func(t time.Time) string { return "" },
+		func(s string) (flextime.ComputedFields, int, error) {
+			return flextime.ComputedFields{}, len(s) + 1, nil
+		},
+	))
+	return d
+}
+
+func TestDialectRegisterComputedToken(t *testing.T) {
+	d := newTestDialect(t)
+
+	target := time.Date(2022, time.October, 20, 23, 16, 0, 0, time.UTC)
+
+	l, err := flextime.CompileWith(d, "YYYY-MM-DDT~hhmm")
+	require.NoError(t, err)
+	assert.Equal(t, "2022-10-20T2316", l.Format(target))
+
+	l, err = flextime.CompileWith(d, "YYYY-MM-DD[T~hhmm]")
+	require.NoError(t, err)
+	for _, value := range []string{"2022-10-20T2316", "2022-10-20"} {
+		parsed, err := l.Parse(value)
+		require.NoError(t, err, value)
+		if value == "2022-10-20" {
+			assert.True(t, target.Truncate(24*time.Hour).Equal(parsed), parsed)
+		} else {
+			assert.True(t, target.Equal(parsed), parsed)
+		}
+	}
+
+	l, err = flextime.CompileWith(d, "~hhmm")
+	require.NoError(t, err)
+	_, err = l.Parse("23")
+	assert.Error(t, err)
+	l, err = flextime.CompileWith(d, "~over")
+	require.NoError(t, err)
+	_, err = l.Parse("a")
+	assert.Error(t, err)
+
+	// no Go reference layout equivalent.
+	l, err = flextime.CompileWith(d, "~hhmm")
+	require.NoError(t, err)
+	assert.Empty(t, l.GoLayouts())
+
+	// the default tokens and other Dialects are not affected: ~ is a literal followed by hh and mm.
+	formatted, err := flextime.Format(target, "~hhmm")
+	require.NoError(t, err)
+	assert.Equal(t, "~1116", formatted)
+	l, err = flextime.CompileWith(flextime.NewDialect(), "~hhmm")
+	require.NoError(t, err)
+	assert.Equal(t, "~1116", l.Format(target))
+
+	format := func(t time.Time) string { return "" }
+	parse := func(s string) (flextime.ComputedFields, int, error) { return flextime.ComputedFields{}, 0, nil }
+	for _, invalid := range []string{"", "[x", "x|", ".x", "YYYY", "Do", "~hhmm"} {
+		assert.Error(t, d.RegisterComputedToken(invalid, format, parse), "token = %q", invalid)
+	}
+	assert.Error(t, d.RegisterComputedToken("~nil", nil, parse))
+	assert.Error(t, d.RegisterComputedToken("~nil", format, nil))
+	assert.Error(t, d.RegisterToken("~hhmm", "15"))
+
+	// a mismatch of the day of year is reported by the registered token.
+	require.NoError(t, d.RegisterComputedToken(
+		"~yday",
+		func(t time.Time) string { return strconv.Itoa(t.YearDay()) },
+		func(s string) (flextime.ComputedFields, int, error) {
+			if len(s) < 3 {
+				return flextime.ComputedFields{}, 0, errors.New("too short")
+			}
+			n, err := strconv.Atoi(s[:3])
+			return flextime.ComputedFields{Set: flextime.FieldYearDay, YearDay: n}, 3, err
+		},
+	))
+	l, err = flextime.CompileWith(d, "YYYY-MM-DD ~yday")
+	require.NoError(t, err)
+	_, err = l.Parse("2023-01-02 100")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "day-of-year ~yday does not match month")
+}
+
+var registerOnce sync.Once
+
+func TestRegisterComputedToken(t *testing.T) {
+	// the token is registered to the default tokens for the rest of the test binary.
+	// It starts with ^, which no other test uses, so that it does not change how other layouts are split.
+	registerOnce.Do(func() {
+		require.NoError(t, flextime.RegisterComputedToken("^hhmm", hhmmFormat, hhmmParse))
+	})
+
+	target := time.Date(2022, time.October, 20, 23, 16, 0, 0, time.UTC)
+
+	formatted, err := flextime.Format(target, "YYYY-MM-DDT^hhmm")
+	require.NoError(t, err)
+	assert.Equal(t, "2022-10-20T2316", formatted)
+	parsed, err := flextime.Parse("YYYY-MM-DDT^hhmm", formatted)
+	require.NoError(t, err)
+	assert.True(t, target.Equal(parsed), parsed)
+
+	tokens, err := flextime.Tokenize("^hhmm")
+	require.NoError(t, err)
+	require.Len(t, tokens, 1)
+	assert.True(t, tokens[0].Computed)
+
+	// no Go reference layout equivalent.
+	_, err = flextime.ToGoLayout("^hhmm")
+	var formatErr *flextime.FormatError
+	assert.ErrorAs(t, err, &formatErr)
+
+	format := func(t time.Time) string { return "" }
+	parse := func(s string) (flextime.ComputedFields, int, error) { return flextime.ComputedFields{}, 0, nil }
+	for _, invalid := range []string{"", "[x", "YYYY", "Do", "^hhmm"} {
+		assert.Error(t, flextime.RegisterComputedToken(invalid, format, parse), "token = %q", invalid)
+	}
+}
@@ -0,0 +1,613 @@
+package flextime
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// computedToken is a time token which Go reference layout can not express.
+// flextime formats and parses it by itself,
+// thus computed tokens are only supported by flextime Format and Parse.
+type computedToken struct {
+	// format appends formatted t to b.
+	format func(b []byte, t time.Time) []byte
+	// parse reads value, stores the result to f and returns the rest of value.
+	parse func(value string, f *parsedFields) (rest string, err error)
+}
+
+var computedTokenTable = map[timeFormatToken]computedToken{
+	"Do": {
+		format: formatOrdinalDay,
+		parse:  parseOrdinalDay,
+	},
+	"H": {
+		format: func(b []byte, t time.Time) []byte { return appendInt(b, t.Hour(), 1) },
+		parse:  parseHour,
+	},
+	"aa": {
+		format: func(b []byte, t time.Time) []byte {
+			if t.Hour() >= 12 {
+				return append(b, "p.m."...)
+			}
+			return append(b, "a.m."...)
+		},
+		parse: parseDottedMeridiem,
+	},
+	"MON": {
+		format: func(b []byte, t time.Time) []byte { return appendUpper(b, t, "Jan") },
+		parse:  func(value string, f *parsedFields) (string, error) { return f.parseStd("Jan", value, false) },
+	},
+	"MONTH": {
+		format: func(b []byte, t time.Time) []byte { return appendUpper(b, t, "January") },
+		parse:  func(value string, f *parsedFields) (string, error) { return f.parseStd("January", value, false) },
+	},
+	"WEEKDAY": {
+		format: func(b []byte, t time.Time) []byte { return appendUpper(b, t, "Monday") },
+		parse:  func(value string, f *parsedFields) (string, error) { return f.parseStd("Monday", value, false) },
+	},
+	"sod": {
+		format: func(b []byte, t time.Time) []byte { return strconv.AppendInt(b, int64(secondsOfDay(t)), 10) },
+		parse:  parseSecondsOfDay,
+	},
+	"msod": {
+		format: func(b []byte, t time.Time) []byte {
+			return strconv.AppendInt(b, int64(secondsOfDay(t))*1000+int64(t.Nanosecond()/1e6), 10)
+		},
+		parse: parseMillisecondsOfDay,
+	},
+	"mod": {
+		format: func(b []byte, t time.Time) []byte { return strconv.AppendInt(b, int64(t.Hour()*60+t.Minute()), 10) },
+		parse:  parseMinutesOfDay,
+	},
+	"G": {
+		format: func(b []byte, t time.Time) []byte {
+			if t.Year() <= 0 {
+				return append(b, "BC"...)
+			}
+			return append(b, "AD"...)
+		},
+		parse: parseEra,
+	},
+	"Q": {
+		format: func(b []byte, t time.Time) []byte { return appendInt(b, quarterOf(t), 1) },
+		parse:  func(value string, f *parsedFields) (string, error) { return parseQuarter(value, f, false) },
+	},
+	"QQ": {
+		format: func(b []byte, t time.Time) []byte { return appendInt(b, quarterOf(t), 2) },
+		parse:  func(value string, f *parsedFields) (string, error) { return parseQuarter(value, f, true) },
+	},
+	"WW": {
+		format: func(b []byte, t time.Time) []byte {
+			_, week := t.ISOWeek()
+			return appendInt(b, week, 2)
+		},
+		parse: parseISOWeek,
+	},
+	"GGGG": {
+		format: func(b []byte, t time.Time) []byte {
+			year, _ := t.ISOWeek()
+			return appendInt(b, year, 4)
+		},
+		parse: parseISOYear,
+	},
+	"e": {
+		format: func(b []byte, t time.Time) []byte { return appendInt(b, isoWeekday(t.Weekday()), 1) },
+		parse:  parseISOWeekday,
+	},
+	"YYYYYY": {
+		format: func(b []byte, t time.Time) []byte { return appendInt(b, t.Year(), 6) },
+		parse: func(value string, f *parsedFields) (rest string, err error) {
+			f.year, rest, err = parseYearWidth(value, 6)
+			return rest, err
+		},
+	},
+	"ZZZ": {
+		format: func(b []byte, t time.Time) []byte { return t.AppendFormat(b, "Z0700") },
+		parse:  parseFlexibleOffset,
+	},
+	"W": {
+		format: func(b []byte, t time.Time) []byte { return appendInt(b, weekOfMonth(t.Day()), 1) },
+		parse:  parseWeekOfMonth,
+	},
+	"E": {
+		format: func(b []byte, t time.Time) []byte { return appendInt(b, isoWeekday(t.Weekday()), 1) },
+		parse:  func(value string, f *parsedFields) (string, error) { return parseWeekday(value, f, true) },
+	},
+	"c": {
+		format: func(b []byte, t time.Time) []byte { return appendInt(b, int(t.Weekday()), 1) },
+		parse:  func(value string, f *parsedFields) (string, error) { return parseWeekday(value, f, false) },
+	},
+	"X": {
+		format: func(b []byte, t time.Time) []byte { return strconv.AppendInt(b, t.Unix(), 10) },
+		parse:  func(value string, f *parsedFields) (string, error) { return parseEpoch(value, f, time.Second) },
+	},
+	"x": {
+		format: func(b []byte, t time.Time) []byte { return strconv.AppendInt(b, t.UnixMilli(), 10) },
+		parse:  func(value string, f *parsedFields) (string, error) { return parseEpoch(value, f, time.Millisecond) },
+	},
+	"SSS": {
+		format: func(b []byte, t time.Time) []byte { return appendFraction(b, t, 3) },
+		parse:  func(value string, f *parsedFields) (string, error) { return parseFraction(value, f, 3) },
+	},
+	"SSSSSS": {
+		format: func(b []byte, t time.Time) []byte { return appendFraction(b, t, 6) },
+		parse:  func(value string, f *parsedFields) (string, error) { return parseFraction(value, f, 6) },
+	},
+	"SSSSSSSSS": {
+		format: func(b []byte, t time.Time) []byte { return appendFraction(b, t, 9) },
+		parse:  func(value string, f *parsedFields) (string, error) { return parseFraction(value, f, 9) },
+	},
+	"zzzz": {
+		format: formatZoneName,
+		parse:  parseZoneName,
+	},
+	"zz": {
+		format: func(b []byte, t time.Time) []byte { return t.AppendFormat(b, "MST") },
+		parse:  parseZoneAbbreviationOrOffset,
+	},
+	"zm": {
+		format: func(b []byte, t time.Time) []byte {
+			_, offset := t.Zone()
+			return strconv.AppendInt(b, int64(offset/60), 10)
+		},
+		parse: func(value string, f *parsedFields) (string, error) { return parseOffsetCount(value, f, 60) },
+	},
+	"zs": {
+		format: func(b []byte, t time.Time) []byte {
+			_, offset := t.Zone()
+			return strconv.AppendInt(b, int64(offset), 10)
+		},
+		parse: func(value string, f *parsedFields) (string, error) { return parseOffsetCount(value, f, 1) },
+	},
+}
+
+// exclusiveTokens are tokens which can not be used with other time tokens in a layout.
+var exclusiveTokens = map[timeFormatToken]bool{
+	"X": true,
+	"x": true,
+}
+
+func (tt timeFormatToken) isComputed() bool {
+	_, ok := computedTokenTable[tt]
+	return ok
+}
+
+func formatOrdinalDay(b []byte, t time.Time) []byte {
+	day := t.Day()
+	b = strconv.AppendInt(b, int64(day), 10)
+	return append(b, ordinalSuffix(day)...)
+}
+
+func ordinalSuffix(n int) string {
+	switch n % 100 {
+	case 11, 12, 13:
+		return "th"
+	}
+	switch n % 10 {
+	case 1:
+		return "st"
+	case 2:
+		return "nd"
+	case 3:
+		return "rd"
+	}
+	return "th"
+}
+
+// parseOrdinalDay reads day of month followed by English ordinal suffix.
+// The suffix is accepted but ignored, so 1th is also parsed as first day of month.
+func parseOrdinalDay(value string, f *parsedFields) (rest string, err error) {
+	f.day, rest, err = getnum(value, false)
+	if err != nil {
+		return value, err
+	}
+	if _, rest, err = lookup([]string{"st", "nd", "rd", "th"}, rest); err != nil {
+		return value, err
+	}
+	return rest, nil
+}
+
+// parseHour reads hour of 24-hour clock, 0 to 23, in 1 or 2 digits.
+func parseHour(value string, f *parsedFields) (rest string, err error) {
+	f.hour, rest, err = getnum(value, false)
+	if err != nil {
+		return value, err
+	}
+	if f.hour < 0 || 23 < f.hour {
+		return value, rangeError("hour")
+	}
+	return rest, nil
+}
+
+// appendUpper appends t formatted by Go reference layout goFmt in upper case.
+// It is for names, e.g. JAN for Jan, which the time package only formats in title case.
+// Parsing names is case insensitive, thus the standard parse applies.
+func appendUpper(b []byte, t time.Time, goFmt string) []byte {
+	n := len(b)
+	b = t.AppendFormat(b, goFmt)
+	for i := n; i < len(b); i++ {
+		if 'a' <= b[i] && b[i] <= 'z' {
+			b[i] -= 'a' - 'A'
+		}
+	}
+	return b
+}
+
+// parseDottedMeridiem reads a.m. or p.m. in any case.
+func parseDottedMeridiem(value string, f *parsedFields) (rest string, err error) {
+	if len(value) < 4 {
+		return value, errBad
+	}
+	switch {
+	case strings.EqualFold(value[0:4], "p.m."):
+		f.pmSet = true
+	case strings.EqualFold(value[0:4], "a.m."):
+		f.amSet = true
+	default:
+		return value, errBad
+	}
+	return value[4:], nil
+}
+
+func secondsOfDay(t time.Time) int {
+	return t.Hour()*3600 + t.Minute()*60 + t.Second()
+}
+
+// parseDigits reads a run of up to maxDigits digits.
+func parseDigits(value string, maxDigits int) (n int, rest string, err error) {
+	i := 0
+	for ; i < len(value) && i < maxDigits && isDigit(value, i); i++ {
+	}
+	if i == 0 {
+		return 0, value, errBad
+	}
+	n, err = atoi(value[:i])
+	if err != nil {
+		return 0, value, err
+	}
+	return n, value[i:], nil
+}
+
+// parseSecondsOfDay reads seconds since midnight, 0 to 86399, and sets hour, minute and second.
+func parseSecondsOfDay(value string, f *parsedFields) (rest string, err error) {
+	sod, rest, err := parseDigits(value, 5)
+	if err != nil {
+		return value, err
+	}
+	if sod >= 86400 {
+		return value, rangeError("seconds of day")
+	}
+	f.hour, f.min, f.sec = sod/3600, sod/60%60, sod%60
+	return rest, nil
+}
+
+// parseMinutesOfDay reads minutes since midnight, 0 to 1439, and sets hour and minute.
+func parseMinutesOfDay(value string, f *parsedFields) (rest string, err error) {
+	mod, rest, err := parseDigits(value, 4)
+	if err != nil {
+		return value, err
+	}
+	if mod >= 1440 {
+		return value, rangeError("minutes of day")
+	}
+	f.hour, f.min = mod/60, mod%60
+	return rest, nil
+}
+
+// parseMillisecondsOfDay reads milliseconds since midnight, 0 to 86399999,
+// and sets hour, minute, second and fractional second.
+func parseMillisecondsOfDay(value string, f *parsedFields) (rest string, err error) {
+	msod, rest, err := parseDigits(value, 8)
+	if err != nil {
+		return value, err
+	}
+	if msod >= 86400000 {
+		return value, rangeError("milliseconds of day")
+	}
+	sod := msod / 1000
+	f.hour, f.min, f.sec, f.nsec = sod/3600, sod/60%60, sod%60, msod%1000*1e6
+	return rest, nil
+}
+
+// appendInt appends the decimal form of x to b, zero-padded to width.
+func appendInt(b []byte, x int, width int) []byte {
+	if x < 0 {
+		b = append(b, '-')
+		x = -x
+	}
+	formatted := strconv.Itoa(x)
+	for i := len(formatted); i < width; i++ {
+		b = append(b, '0')
+	}
+	return append(b, formatted...)
+}
+
+// eraNames are era names accepted on parse. Ones before index 2 are before Christ.
+var eraNames = []string{"BCE", "BC", "CE", "AD"}
+
+// parseEra reads an era name and stores whether the year is before Christ.
+func parseEra(value string, f *parsedFields) (rest string, err error) {
+	i, rest, err := lookup(eraNames, value)
+	if err != nil {
+		return value, err
+	}
+	f.bc = i < 2
+	return rest, nil
+}
+
+// yearOfEra converts a proleptic Gregorian year, as returned by time.Time.Year,
+// into the year counted in its era. Year 0 is 1 BC, year -1 is 2 BC and so on.
+func yearOfEra(year int) int {
+	if year <= 0 {
+		return 1 - year
+	}
+	return year
+}
+
+func quarterOf(t time.Time) int {
+	return (int(t.Month())-1)/3 + 1
+}
+
+// parseQuarter reads quarter of year, 1 to 4.
+// It reads exactly 2 digits if zeroPadded is true, exactly 1 digit otherwise.
+// Month is set to the first month of the quarter unless a month token is present.
+func parseQuarter(value string, f *parsedFields, zeroPadded bool) (rest string, err error) {
+	if zeroPadded {
+		f.quarter, rest, err = getnum(value, true)
+		if err != nil {
+			return value, err
+		}
+	} else {
+		if !isDigit(value, 0) {
+			return value, errBad
+		}
+		f.quarter, rest = int(value[0]-'0'), value[1:]
+	}
+	if f.quarter < 1 || 4 < f.quarter {
+		return value, rangeError("quarter")
+	}
+	return rest, nil
+}
+
+// parseFlexibleOffset reads a numeric offset with or without minutes, e.g. +0900 or +09, or Z for UTC.
+// The longer form is tried first.
+func parseFlexibleOffset(value string, f *parsedFields) (rest string, err error) {
+	if rest, err = f.parseStd("Z0700", value, false); err == nil {
+		return rest, nil
+	}
+	return f.parseStd("Z07", value, false)
+}
+
+// weekOfMonth returns the week of month day is in.
+// Weeks are counted from the first day of the month, not aligned to weekdays:
+// days 1 to 7 are in week 1, 8 to 14 in week 2 and so on, up to week 5.
+func weekOfMonth(day int) int {
+	return (day-1)/7 + 1
+}
+
+// parseWeekOfMonth reads a week of month, 1 to 5.
+// It is informational unless combined with a day of week, see (*parsedFields).time.
+func parseWeekOfMonth(value string, f *parsedFields) (rest string, err error) {
+	if !isDigit(value, 0) {
+		return value, errBad
+	}
+	f.weekOfMonth, rest = int(value[0]-'0'), value[1:]
+	if f.weekOfMonth < 1 || 5 < f.weekOfMonth {
+		return value, rangeError("week of month")
+	}
+	return rest, nil
+}
+
+// isoWeekday converts wd into ISO 8601 weekday number, 1 = Monday to 7 = Sunday.
+func isoWeekday(wd time.Weekday) int {
+	if wd == time.Sunday {
+		return 7
+	}
+	return int(wd)
+}
+
+// parseISOWeek reads zero padded ISO 8601 week number, 01 to 53.
+func parseISOWeek(value string, f *parsedFields) (rest string, err error) {
+	f.isoWeek, rest, err = getnum(value, true)
+	if err != nil {
+		return value, err
+	}
+	if f.isoWeek < 1 || 53 < f.isoWeek {
+		return value, rangeError("ISO week")
+	}
+	return rest, nil
+}
+
+// parseISOYear reads 4 digits ISO 8601 week-numbering year.
+func parseISOYear(value string, f *parsedFields) (rest string, err error) {
+	f.isoYear, rest, err = parseLongYear(value)
+	f.isoYearSet = err == nil
+	return rest, err
+}
+
+// parseISOWeekday reads ISO 8601 weekday number, 1 = Monday to 7 = Sunday.
+func parseISOWeekday(value string, f *parsedFields) (rest string, err error) {
+	if !isDigit(value, 0) {
+		return value, errBad
+	}
+	f.isoWeekday = int(value[0] - '0')
+	if f.isoWeekday < 1 || 7 < f.isoWeekday {
+		return value, rangeError("ISO weekday")
+	}
+	return value[1:], nil
+}
+
+// parseWeekday reads numeric day of week,
+// 1 = Monday to 7 = Sunday if iso is true, 0 = Sunday to 6 = Saturday otherwise.
+func parseWeekday(value string, f *parsedFields, iso bool) (rest string, err error) {
+	if !isDigit(value, 0) {
+		return value, errBad
+	}
+	n := int(value[0] - '0')
+	if iso {
+		if n < 1 || 7 < n {
+			return value, rangeError("day-of-week")
+		}
+		n %= 7
+	} else if 6 < n {
+		return value, rangeError("day-of-week")
+	}
+	f.weekday = n
+	return value[1:], nil
+}
+
+// parseEpoch reads an optionally signed run of digits as Unix time in unit.
+func parseEpoch(value string, f *parsedFields, unit time.Duration) (rest string, err error) {
+	i := 0
+	if len(value) > 0 && value[0] == '-' {
+		i++
+	}
+	start := i
+	for ; i < len(value) && isDigit(value, i); i++ {
+	}
+	if i == start {
+		return value, errBad
+	}
+	f.epoch, err = strconv.ParseInt(value[:i], 10, 64)
+	if err != nil {
+		return value, rangeError("epoch")
+	}
+	f.epochUnit = unit
+	return value[i:], nil
+}
+
+// appendFraction appends fractional second of t truncated to digits, without a leading dot.
+func appendFraction(b []byte, t time.Time, digits int) []byte {
+	frac := t.Nanosecond()
+	for i := digits; i < 9; i++ {
+		frac /= 10
+	}
+	return appendInt(b, frac, digits)
+}
+
+// parseFraction reads exactly digits digits as fractional second, without a leading dot.
+func parseFraction(value string, f *parsedFields, digits int) (rest string, err error) {
+	if len(value) < digits {
+		return value, errBad
+	}
+	for i := 0; i < digits; i++ {
+		if !isDigit(value, i) {
+			return value, errBad
+		}
+	}
+	f.nsec, err = atoi(value[:digits])
+	if err != nil {
+		return value, err
+	}
+	for i := digits; i < 9; i++ {
+		f.nsec *= 10
+	}
+	return value[digits:], nil
+}
+
+// formatZoneName appends IANA time zone name of t, e.g. America/New_York.
+// If location of t has no IANA name, e.g. made by time.FixedZone,
+// it appends the numeric offset in -07:00 form instead.
+func formatZoneName(b []byte, t time.Time) []byte {
+	if name := t.Location().String(); isIANAName(name) {
+		return append(b, name...)
+	}
+	return t.AppendFormat(b, "-07:00")
+}
+
+// maxZoneNameLen is the max length of time zone names read by parseZoneName.
+// The longest IANA name, e.g. America/Argentina/ComodRivadavia, is far shorter.
+const maxZoneNameLen = 64
+
+// parseZoneName reads IANA time zone name, or numeric offset in -07:00 form.
+func parseZoneName(value string, f *parsedFields) (rest string, err error) {
+	if len(value) > 0 && (value[0] == '+' || value[0] == '-') {
+		return f.parseNumTZ(StdNumColonTZ, value)
+	}
+	i := 0
+	for ; i < len(value) && isZoneNameChar(value[i]); i++ {
+	}
+	if i == 0 {
+		return value, errBad
+	}
+	if i > maxZoneNameLen {
+		return value, errors.New("unknown time zone " + value[:maxZoneNameLen] + "...")
+	}
+	loc, err := loadLocation(value[:i])
+	if err != nil {
+		return value, errors.New("unknown time zone " + value[:i])
+	}
+	f.z = loc
+	return value[i:], nil
+}
+
+// parseZoneAbbreviationOrOffset reads a numeric offset, -0700, -07:00 or -07, if value starts with a sign.
+// Otherwise it reads a time zone abbreviation as the MST token does.
+func parseZoneAbbreviationOrOffset(value string, f *parsedFields) (rest string, err error) {
+	if len(value) > 0 && (value[0] == '+' || value[0] == '-') {
+		for _, std := range []int{StdNumTZ, StdNumColonTZ, StdNumShortTZ} {
+			if rest, err = f.parseNumTZ(std, value); err == nil {
+				return rest, nil
+			}
+		}
+		return value, err
+	}
+	return f.parseStd("MST", value, false)
+}
+
+// parseOffsetCount reads an optionally signed integer count of unit seconds as the zone offset,
+// e.g. -480 for -08:00 if unit is 60, and sets a fixed zone of the offset.
+// Offsets beyond 24 hours either way are rejected.
+func parseOffsetCount(value string, f *parsedFields, unit int) (rest string, err error) {
+	i := 0
+	if len(value) > 0 && (value[0] == '-' || value[0] == '+') {
+		i++
+	}
+	start := i
+	for ; i < len(value) && isDigit(value, i); i++ {
+	}
+	if i == start {
+		return value, errBad
+	}
+	count, err := strconv.Atoi(value[:i])
+	if err != nil || count < -86400/unit || 86400/unit < count {
+		return value, rangeError("time zone offset")
+	}
+	f.z = time.FixedZone("", count*unit)
+	return value[i:], nil
+}
+
+func isZoneNameChar(c byte) bool {
+	return ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') || ('0' <= c && c <= '9') ||
+		c == '/' || c == '_' || c == '-' || c == '+'
+}
+
+// isIANAName reports whether name is a time zone name loadable by time.LoadLocation.
+// "Local" is not since it does not tell where the time is.
+func isIANAName(name string) bool {
+	if name == "" || name == "Local" {
+		return false
+	}
+	_, err := loadLocation(name)
+	return err == nil
+}
+
+// locationCache caches locations loaded by time.LoadLocation, which reads the time zone database on every call.
+// Failures are not cached, so that names taken from values can not grow it;
+// it is bounded by the number of names in the database.
+var locationCache sync.Map // map[string]*time.Location
+
+func loadLocation(name string) (*time.Location, error) {
+	if cached, ok := locationCache.Load(name); ok {
+		return cached.(*time.Location), nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, err
+	}
+	locationCache.Store(name, loc)
+	return loc, nil
+}
@@ -0,0 +1,783 @@
+package flextime_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ngicks/flextime"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrdinalDay(t *testing.T) {
+	expected := map[int]string{
+		1:  "1st",
+		2:  "2nd",
+		3:  "3rd",
+		4:  "4th",
+		11: "11th",
+		12: "12th",
+		13: "13th",
+		21: "21st",
+		22: "22nd",
+		23: "23rd",
+		31: "31st",
+	}
+	for day, ordinal := range expected {
+		target := time.Date(2022, time.October, day, 0, 0, 0, 0, time.UTC)
+		formatted, err := flextime.Format(target, "MMMM Do YYYY")
+		require.NoError(t, err)
+		assert.Equal(t, "October "+ordinal+" 2022", formatted)
+
+		parsed, err := flextime.Parse("MMMM Do YYYY", formatted)
+		require.NoError(t, err)
+		assert.True(t, target.Equal(parsed), "expected = %s, actual = %s", target, parsed)
+	}
+
+	// suffix is ignored.
+	parsed, err := flextime.Parse("MMMM Do[/YYYY]", "October 2th/2022")
+	require.NoError(t, err)
+	assert.True(t, time.Date(2022, time.October, 2, 0, 0, 0, 0, time.UTC).Equal(parsed))
+
+	parsed, err = flextime.ParseInLocation("MMMM Do[/YYYY]", "October 3RD", jst)
+	require.NoError(t, err)
+	assert.True(t, time.Date(0, time.October, 3, 0, 0, 0, 0, jst).Equal(parsed))
+
+	var parseErr *time.ParseError
+	for _, invalid := range []string{"October 2 2022", "October 32nd 2022", "October nd 2022"} {
+		_, err = flextime.Parse("MMMM Do YYYY", invalid)
+		assert.ErrorAs(t, err, &parseErr)
+	}
+}
+
+func TestComputedTokenHasNoGoLayout(t *testing.T) {
+	var formatErr *flextime.FormatError
+
+	_, err := flextime.ReplaceTimeToken("MMMM Do YYYY")
+	assert.ErrorAs(t, err, &formatErr)
+
+	_, err = flextime.ToGoLayout("MMMM Do[/YYYY]")
+	assert.ErrorAs(t, err, &formatErr)
+
+	l, err := flextime.Compile("[MMMM Do/]YYYY")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"2006"}, l.GoLayouts())
+}
+
+func TestQuarter(t *testing.T) {
+	for month := time.January; month <= time.December; month++ {
+		target := time.Date(2022, month, 1, 0, 0, 0, 0, time.UTC)
+		quarter := (int(month)-1)/3 + 1
+
+		formatted, err := flextime.Format(target, "YYYY-'Q'Q QQ")
+		require.NoError(t, err)
+		assert.Equal(t, fmt.Sprintf("2022-Q%d 0%d", quarter, quarter), formatted)
+
+		parsed, err := flextime.Parse("YYYY-'Q'Q", fmt.Sprintf("2022-Q%d", quarter))
+		require.NoError(t, err)
+		assert.Equal(t, time.Date(2022, time.Month((quarter-1)*3+1), 1, 0, 0, 0, 0, time.UTC), parsed)
+
+		// month token takes precedence.
+		parsed, err = flextime.Parse("QQ YYYY-MM", fmt.Sprintf("0%d 2022-%02d", quarter, month))
+		require.NoError(t, err)
+		assert.Equal(t, target, parsed)
+	}
+
+	parsed, err := flextime.Parse("QYYYY", "32022")
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2022, time.July, 1, 0, 0, 0, 0, time.UTC), parsed)
+
+	var parseErr *time.ParseError
+	for _, invalid := range []string{"2022-Q0", "2022-Q5", "2022-Qa"} {
+		_, err = flextime.Parse("YYYY-'Q'Q", invalid)
+		assert.ErrorAs(t, err, &parseErr)
+	}
+	_, err = flextime.Parse("Q YYYY-MM", "1 2022-04")
+	assert.ErrorAs(t, err, &parseErr)
+	assert.Contains(t, err.Error(), "quarter does not match month")
+}
+
+type isoWeekTestCase struct {
+	date      time.Time
+	formatted string
+}
+
+func TestISOWeek(t *testing.T) {
+	cases := []isoWeekTestCase{
+		{
+			date:      time.Date(2023, time.February, 1, 0, 0, 0, 0, time.UTC),
+			formatted: "2023-W05-3",
+		},
+		{
+			// ISO week-year is previous year.
+			date:      time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC),
+			formatted: "2020-W53-5",
+		},
+		{
+			// ISO week-year is next year.
+			date:      time.Date(2024, time.December, 30, 0, 0, 0, 0, time.UTC),
+			formatted: "2025-W01-1",
+		},
+		{
+			date:      time.Date(2022, time.January, 2, 0, 0, 0, 0, time.UTC),
+			formatted: "2021-W52-7",
+		},
+	}
+
+	for _, testCase := range cases {
+		formatted, err := flextime.Format(testCase.date, "GGGG-'W'WW-e")
+		require.NoError(t, err)
+		assert.Equal(t, testCase.formatted, formatted)
+
+		parsed, err := flextime.Parse("GGGG-'W'WW-e", testCase.formatted)
+		require.NoError(t, err)
+		assert.Equal(t, testCase.date, parsed)
+
+		// consistent calendar date is accepted.
+		parsed, err = flextime.Parse(
+			"GGGG-'W'WW-e YYYY-MM-DD",
+			testCase.formatted+" "+testCase.date.Format("2006-01-02"),
+		)
+		require.NoError(t, err)
+		assert.Equal(t, testCase.date, parsed)
+	}
+
+	// weekday defaults to Monday.
+	parsed, err := flextime.Parse("GGGG-'W'WW", "2020-W53")
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2020, time.December, 28, 0, 0, 0, 0, time.UTC), parsed)
+
+	var parseErr *time.ParseError
+	for _, invalid := range []string{"2021-W53-1", "2020-W00-1", "2020-W54-1", "2020-W10-8", "2020-W10-0"} {
+		_, err = flextime.Parse("GGGG-'W'WW-e", invalid)
+		assert.ErrorAs(t, err, &parseErr, "value = %s", invalid)
+	}
+	_, err = flextime.Parse("GGGG-'W'WW-e YYYY-MM-DD", "2023-W05-3 2023-02-02")
+	assert.ErrorAs(t, err, &parseErr)
+}
+
+func TestEpoch(t *testing.T) {
+	target := time.Date(2022, time.October, 20, 16, 22, 46, 123000000, time.UTC)
+
+	formatted, err := flextime.Format(target, "X")
+	require.NoError(t, err)
+	assert.Equal(t, "1666282966", formatted)
+	formatted, err = flextime.Format(target, "'ts='x")
+	require.NoError(t, err)
+	assert.Equal(t, "ts=1666282966123", formatted)
+
+	parsed, err := flextime.Parse("X", "1666282966")
+	require.NoError(t, err)
+	assert.True(t, target.Truncate(time.Second).Equal(parsed))
+	parsed, err = flextime.ParseInLocation("'ts='x", "ts=1666282966123", jst)
+	require.NoError(t, err)
+	assert.True(t, target.Equal(parsed))
+	assert.Equal(t, jst, parsed.Location())
+	parsed, err = flextime.Parse("X", "-1")
+	require.NoError(t, err)
+	assert.True(t, time.Unix(-1, 0).Equal(parsed))
+
+	var parseErr *time.ParseError
+	for _, invalid := range []string{"", "-", "abc", "16662a82966", "99999999999999999999"} {
+		_, err = flextime.Parse("X", invalid)
+		assert.ErrorAs(t, err, &parseErr, "value = %s", invalid)
+	}
+
+	var formatErr *flextime.FormatError
+	for _, invalid := range []string{"X YYYY", "HH:mm x", "X x", "x[-MM]"} {
+		_, err = flextime.Compile(invalid)
+		assert.ErrorAs(t, err, &formatErr, "layout = %s", invalid)
+		_, err = flextime.Format(target, invalid)
+		assert.Error(t, err)
+	}
+}
+
+func TestZoneName(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+
+	layout := "YYYY-MM-DDTHH:mm:ss zzzz"
+	for _, target := range []time.Time{
+		time.Date(2022, time.October, 20, 23, 16, 22, 0, jst),
+		time.Date(2022, time.October, 20, 23, 16, 22, 0, newYork),
+		time.Date(2022, time.October, 20, 23, 16, 22, 0, time.UTC),
+	} {
+		formatted, err := flextime.Format(target, layout)
+		require.NoError(t, err)
+		assert.Equal(t, target.Format("2006-01-02T15:04:05 ")+target.Location().String(), formatted)
+
+		parsed, err := flextime.Parse(layout, formatted)
+		require.NoError(t, err)
+		assert.True(t, target.Equal(parsed), "value = %s", formatted)
+		assert.Equal(t, target.Location().String(), parsed.Location().String())
+	}
+
+	// fixed zone has no IANA name.
+	fixed := time.Date(2022, time.October, 20, 23, 16, 22, 0, time.FixedZone("", -(3*3600+30*60)))
+	formatted, err := flextime.Format(fixed, layout)
+	require.NoError(t, err)
+	assert.Equal(t, "2022-10-20T23:16:22 -03:30", formatted)
+	parsed, err := flextime.Parse(layout, formatted)
+	require.NoError(t, err)
+	assert.True(t, fixed.Equal(parsed))
+
+	var parseErr *time.ParseError
+	for _, invalid := range []string{
+		"2022-10-20T23:16:22 ",
+		"2022-10-20T23:16:22 Mars/Olympus_Mons",
+		"2022-10-20T23:16:22 Asia/" + strings.Repeat("Tokyo", 100),
+		"2022-10-20T23:16:22 +0900",
+	} {
+		_, err = flextime.Parse(layout, invalid)
+		assert.ErrorAs(t, err, &parseErr, "value = %s", invalid)
+	}
+}
+
+func TestFractionWithoutDot(t *testing.T) {
+	parsed, err := flextime.Parse("HHmmssSSS", "210057012")
+	require.NoError(t, err)
+	assert.True(t, time.Date(0, time.January, 1, 21, 0, 57, 12000000, time.UTC).Equal(parsed))
+
+	target := time.Date(2022, time.October, 20, 21, 0, 57, 12345678, time.UTC)
+	for _, testCase := range []struct {
+		layout    string
+		formatted string
+		precision time.Duration
+	}{
+		{"HHmmssSSS", "210057012", time.Millisecond},
+		{"HHmmssSSSSSS", "210057012345", time.Microsecond},
+		{"YYYYMMDDHHmmssSSSSSSSSS", "20221020210057012345678", time.Nanosecond},
+	} {
+		formatted, err := flextime.Format(target, testCase.layout)
+		require.NoError(t, err)
+		assert.Equal(t, testCase.formatted, formatted)
+
+		parsed, err := flextime.Parse(testCase.layout, formatted)
+		require.NoError(t, err)
+		assert.Equal(t, target.Nanosecond()/int(testCase.precision)*int(testCase.precision), parsed.Nanosecond())
+	}
+
+	var parseErr *time.ParseError
+	for _, invalid := range []string{"21005701", "2100570123", "21005701a", "210057.012"} {
+		_, err = flextime.Parse("HHmmssSSS", invalid)
+		assert.ErrorAs(t, err, &parseErr, "value = %s", invalid)
+	}
+
+	// .SSS is still a Go reference layout token.
+	goLayout, err := flextime.ReplaceTimeToken("HHmmss.SSS")
+	require.NoError(t, err)
+	assert.Equal(t, "150405.000", goLayout)
+
+	var formatErr *flextime.FormatError
+	for _, invalid := range []string{"HHmmssS", "HHmmssSS", "HHmmssSSSS"} {
+		_, err = flextime.Format(target, invalid)
+		assert.ErrorAs(t, err, &formatErr, "layout = %s", invalid)
+	}
+}
+
+func TestNumericWeekday(t *testing.T) {
+	// 2022-10-23 is Sunday.
+	sunday := time.Date(2022, time.October, 23, 0, 0, 0, 0, time.UTC)
+	monday := sunday.AddDate(0, 0, 1)
+
+	for _, testCase := range []struct {
+		target   time.Time
+		layout   string
+		expected string
+	}{
+		{sunday, "YYYY-MM-DD E", "2022-10-23 7"},
+		{sunday, "YYYY-MM-DD c", "2022-10-23 0"},
+		{monday, "YYYY-MM-DD E", "2022-10-24 1"},
+		{monday, "YYYY-MM-DD c", "2022-10-24 1"},
+	} {
+		formatted, err := flextime.Format(testCase.target, testCase.layout)
+		require.NoError(t, err)
+		assert.Equal(t, testCase.expected, formatted)
+
+		parsed, err := flextime.Parse(testCase.layout, formatted)
+		require.NoError(t, err)
+		assert.True(t, testCase.target.Equal(parsed))
+	}
+
+	var parseErr *time.ParseError
+	for _, testCase := range []struct {
+		layout string
+		value  string
+	}{
+		{"YYYY-MM-DD E", "2022-10-23 1"},
+		{"YYYY-MM-DD c", "2022-10-23 6"},
+		{"YYYY-DDD c", "2022-296 1"},
+	} {
+		_, err := flextime.Parse(testCase.layout, testCase.value)
+		require.ErrorAs(t, err, &parseErr)
+		assert.Contains(t, err.Error(), "day-of-week does not match")
+	}
+	for _, testCase := range []struct {
+		layout string
+		value  string
+	}{
+		{"YYYY-MM-DD E", "2022-10-23 0"},
+		{"YYYY-MM-DD E", "2022-10-23 8"},
+		{"YYYY-MM-DD c", "2022-10-23 7"},
+		{"YYYY-MM-DD c", "2022-10-23 x"},
+	} {
+		_, err := flextime.Parse(testCase.layout, testCase.value)
+		assert.ErrorAs(t, err, &parseErr, "value = %s", testCase.value)
+	}
+
+	// without the day, there is nothing to check against.
+	_, err := flextime.Parse("YYYY-MM E", "2022-10 3")
+	require.NoError(t, err)
+
+	// E selects the day in ISO week.
+	parsed, err := flextime.Parse("GGGG-'W'WW-E", "2022-W42-7")
+	require.NoError(t, err)
+	assert.True(t, sunday.Equal(parsed))
+}
+
+func TestHourWithoutPadding(t *testing.T) {
+	for _, testCase := range []struct {
+		target    time.Time
+		formatted string
+	}{
+		{time.Date(2022, time.October, 20, 9, 5, 0, 0, time.UTC), "2022-10-20 9:05"},
+		{time.Date(2022, time.October, 20, 0, 5, 0, 0, time.UTC), "2022-10-20 0:05"},
+		{time.Date(2022, time.October, 20, 23, 5, 0, 0, time.UTC), "2022-10-20 23:05"},
+	} {
+		formatted, err := flextime.Format(testCase.target, "YYYY-MM-DD H:mm")
+		require.NoError(t, err)
+		assert.Equal(t, testCase.formatted, formatted)
+
+		parsed, err := flextime.Parse("YYYY-MM-DD H:mm", formatted)
+		require.NoError(t, err)
+		assert.True(t, testCase.target.Equal(parsed))
+	}
+
+	parsed, err := flextime.Parse("YYYY-MM-DD H:mm", "2022-10-20 09:05")
+	require.NoError(t, err)
+	assert.Equal(t, 9, parsed.Hour())
+
+	var parseErr *time.ParseError
+	for _, invalid := range []string{"2022-10-20 24:00", "2022-10-20 :00", "2022-10-20 123:00"} {
+		_, err := flextime.Parse("YYYY-MM-DD H:mm", invalid)
+		assert.ErrorAs(t, err, &parseErr, "value = %s", invalid)
+	}
+	_, err = flextime.Parse("YYYY-MM-DD H:mm", "2022-10-20 24:00")
+	assert.Contains(t, err.Error(), "hour out of range")
+}
+
+func TestTimeOfDay(t *testing.T) {
+	target := time.Date(2022, time.October, 20, 23, 16, 22, 168123456, time.UTC)
+
+	for _, testCase := range []struct {
+		layout    string
+		formatted string
+		parsed    time.Time
+	}{
+		{"YYYY-MM-DD sod", "2022-10-20 83782", target.Truncate(time.Second)},
+		{"YYYY-MM-DD msod", "2022-10-20 83782168", target.Truncate(time.Millisecond)},
+		{"YYYY-MM-DD mod", "2022-10-20 1396", target.Truncate(time.Minute)},
+		{"YYYY-MM-DD mod:ss", "2022-10-20 1396:22", target.Truncate(time.Second)},
+	} {
+		formatted, err := flextime.Format(target, testCase.layout)
+		require.NoError(t, err)
+		assert.Equal(t, testCase.formatted, formatted)
+
+		parsed, err := flextime.Parse(testCase.layout, formatted)
+		require.NoError(t, err)
+		assert.True(t, testCase.parsed.Equal(parsed), "parsed = %s", parsed)
+	}
+
+	midnight := time.Date(2022, time.October, 20, 0, 0, 0, 0, time.UTC)
+	formatted, err := flextime.Format(midnight, "sod/msod/mod")
+	require.NoError(t, err)
+	assert.Equal(t, "0/0/0", formatted)
+
+	// departures of transit schedules.
+	departure := time.Date(0, time.January, 1, 14, 30, 0, 0, time.UTC)
+	formatted, err = flextime.Format(departure, "mod")
+	require.NoError(t, err)
+	assert.Equal(t, "870", formatted)
+	parsed, err := flextime.Parse("mod", "870")
+	require.NoError(t, err)
+	assert.True(t, departure.Equal(parsed), "parsed = %s", parsed)
+	parsed, err = flextime.Parse("mod", "1439")
+	require.NoError(t, err)
+	assert.Equal(t, 23, parsed.Hour())
+	assert.Equal(t, 59, parsed.Minute())
+
+	var parseErr *time.ParseError
+	for _, testCase := range []struct {
+		layout string
+		value  string
+	}{
+		{"sod", "86400"},
+		{"msod", "86400000"},
+		{"mod", "1440"},
+		{"sod", ""},
+		{"sod", "123456"},
+	} {
+		_, err := flextime.Parse(testCase.layout, testCase.value)
+		assert.ErrorAs(t, err, &parseErr, "value = %s", testCase.value)
+	}
+	_, err = flextime.Parse("sod", "86400")
+	assert.Contains(t, err.Error(), "seconds of day out of range")
+	_, err = flextime.Parse("msod", "86400000")
+	assert.Contains(t, err.Error(), "milliseconds of day out of range")
+	_, err = flextime.Parse("mod", "1440")
+	assert.Contains(t, err.Error(), "minutes of day out of range")
+}
+
+func TestEra(t *testing.T) {
+	for _, testCase := range []struct {
+		year      int
+		formatted string
+	}{
+		{2022, "2022 AD"},
+		{1, "0001 AD"},
+		{0, "0001 BC"},
+		{-43, "0044 BC"},
+		{-9998, "9999 BC"},
+	} {
+		target := time.Date(testCase.year, time.March, 15, 0, 0, 0, 0, time.UTC)
+
+		formatted, err := flextime.Format(target, "YYYY G")
+		require.NoError(t, err)
+		assert.Equal(t, testCase.formatted, formatted)
+
+		parsed, err := flextime.Parse("YYYY G", formatted)
+		require.NoError(t, err)
+		assert.Equal(t, testCase.year, parsed.Year(), "formatted = %s", formatted)
+	}
+
+	for _, testCase := range []struct {
+		value string
+		year  int
+	}{
+		{"0044 BCE", -43},
+		{"0044 bc", -43},
+		{"2022 CE", 2022},
+	} {
+		parsed, err := flextime.Parse("YYYY G", testCase.value)
+		require.NoError(t, err)
+		assert.Equal(t, testCase.year, parsed.Year(), "value = %s", testCase.value)
+	}
+
+	_, err := flextime.Parse("YYYY G", "0044 XY")
+	var parseErr *time.ParseError
+	assert.ErrorAs(t, err, &parseErr)
+}
+
+func TestNegativeYear(t *testing.T) {
+	for year := -2000; year <= 2000; year += 7 {
+		target := time.Date(year, time.March, 15, 12, 30, 0, 0, time.UTC)
+		for _, flexLayout := range []string{"YYYY-MM-DD", "YYYY-MM-DD[THH:mm]", "YYYY-MM-DD Do"} {
+			layout, err := flextime.Compile(flexLayout)
+			require.NoError(t, err)
+			formatted := layout.Format(target)
+			assert.True(t, strings.HasPrefix(formatted, target.Format("2006-01-02")), "formatted = %s", formatted)
+
+			parsed, err := layout.Parse(formatted)
+			require.NoError(t, err, "layout = %s, formatted = %s", flexLayout, formatted)
+			assert.Equal(t, year, parsed.Year(), "layout = %s, formatted = %s", flexLayout, formatted)
+		}
+	}
+
+	parsed, err := flextime.Parse("YYYY-MM-DD", "-0044-03-15")
+	require.NoError(t, err)
+	assert.True(t, time.Date(-44, time.March, 15, 0, 0, 0, 0, time.UTC).Equal(parsed))
+
+	_, err = flextime.Parse("YYYY-MM-DD", "-044-03-15")
+	var parseErr *time.ParseError
+	assert.ErrorAs(t, err, &parseErr)
+}
+
+func TestDottedMeridiem(t *testing.T) {
+	for _, testCase := range []struct {
+		target    time.Time
+		formatted string
+	}{
+		{time.Date(2022, time.October, 20, 23, 16, 0, 0, time.UTC), "11:16 p.m."},
+		{time.Date(2022, time.October, 20, 0, 16, 0, 0, time.UTC), "12:16 a.m."},
+		{time.Date(2022, time.October, 20, 12, 0, 0, 0, time.UTC), "12:00 p.m."},
+		{time.Date(2022, time.October, 20, 9, 5, 0, 0, time.UTC), "09:05 a.m."},
+	} {
+		formatted, err := flextime.Format(testCase.target, "hh:mm aa")
+		require.NoError(t, err)
+		assert.Equal(t, testCase.formatted, formatted)
+
+		parsed, err := flextime.Parse("YYYY-MM-DD hh:mm aa", "2022-10-20 "+formatted)
+		require.NoError(t, err)
+		assert.True(t, testCase.target.Equal(parsed), "parsed = %s", parsed)
+	}
+
+	for _, value := range []string{"11:16 P.M.", "11:16 p.M."} {
+		parsed, err := flextime.Parse("hh:mm aa", value)
+		require.NoError(t, err)
+		assert.Equal(t, 23, parsed.Hour())
+	}
+
+	var parseErr *time.ParseError
+	for _, value := range []string{"11:16 pm", "11:16 p.m", "11:16 x.m."} {
+		_, err := flextime.Parse("hh:mm aa", value)
+		assert.ErrorAs(t, err, &parseErr, "value = %s", value)
+	}
+}
+
+func TestUpperCaseMonth(t *testing.T) {
+	target := time.Date(2022, time.February, 3, 4, 5, 6, 0, time.UTC)
+
+	formatted, err := flextime.Format(target, "DD-MON-YYYY")
+	require.NoError(t, err)
+	assert.Equal(t, "03-FEB-2022", formatted)
+
+	for _, value := range []string{"03-FEB-2022", "03-Feb-2022", "03-feb-2022"} {
+		parsed, err := flextime.Parse("DD-MON-YYYY", value)
+		require.NoError(t, err, "value = %s", value)
+		assert.True(t, time.Date(2022, time.February, 3, 0, 0, 0, 0, time.UTC).Equal(parsed))
+	}
+
+	_, err = flextime.Parse("DD-MON-YYYY", "03-FEX-2022")
+	var parseErr *time.ParseError
+	assert.ErrorAs(t, err, &parseErr)
+}
+
+func TestUpperCaseNames(t *testing.T) {
+	target := time.Date(2022, time.January, 5, 0, 0, 0, 0, time.UTC)
+
+	formatted, err := flextime.Format(target, "WEEKDAY, MONTH D YYYY")
+	require.NoError(t, err)
+	assert.Equal(t, "WEDNESDAY, JANUARY 5 2022", formatted)
+
+	for _, value := range []string{"WEDNESDAY, JANUARY 5 2022", "Wednesday, January 5 2022", "wednesday, january 5 2022"} {
+		parsed, err := flextime.Parse("WEEKDAY, MONTH D YYYY", value)
+		require.NoError(t, err, "value = %s", value)
+		assert.True(t, target.Equal(parsed))
+	}
+
+	// MONTH is not read as MON followed by TH.
+	formatted, err = flextime.Format(target, "MONTH MON")
+	require.NoError(t, err)
+	assert.Equal(t, "JANUARY JAN", formatted)
+}
+
+func TestWeekOfMonth(t *testing.T) {
+	cases := []struct {
+		date     time.Time
+		expected string
+	}{
+		{time.Date(2022, time.January, 1, 0, 0, 0, 0, time.UTC), "2022-01 W1"},
+		{time.Date(2022, time.January, 7, 0, 0, 0, 0, time.UTC), "2022-01 W1"},
+		{time.Date(2022, time.January, 8, 0, 0, 0, 0, time.UTC), "2022-01 W2"},
+		{time.Date(2022, time.January, 28, 0, 0, 0, 0, time.UTC), "2022-01 W4"},
+		{time.Date(2022, time.January, 29, 0, 0, 0, 0, time.UTC), "2022-01 W5"},
+		{time.Date(2022, time.January, 31, 0, 0, 0, 0, time.UTC), "2022-01 W5"},
+		// the next month starts over from week 1, regardless of the weekday.
+		{time.Date(2022, time.February, 1, 0, 0, 0, 0, time.UTC), "2022-02 W1"},
+		{time.Date(2022, time.February, 28, 0, 0, 0, 0, time.UTC), "2022-02 W4"},
+		{time.Date(2024, time.February, 29, 0, 0, 0, 0, time.UTC), "2024-02 W5"},
+		{time.Date(2022, time.March, 1, 0, 0, 0, 0, time.UTC), "2022-03 W1"},
+		{time.Date(2022, time.December, 31, 0, 0, 0, 0, time.UTC), "2022-12 W5"},
+	}
+
+	for _, testCase := range cases {
+		formatted, err := flextime.Format(testCase.date, "YYYY-MM 'W'W")
+		require.NoError(t, err)
+		assert.Equal(t, testCase.expected, formatted, "date = %s", testCase.date)
+	}
+
+	// with a day of week, W decides the day: the third Thursday of October 2022.
+	for _, flexLayout := range []string{"YYYY-MM 'W'W ww", "YYYY-MM 'W'W E", "YYYY-MM 'W'W w"} {
+		target := time.Date(2022, time.October, 20, 0, 0, 0, 0, time.UTC)
+		formatted, err := flextime.Format(target, flexLayout)
+		require.NoError(t, err)
+		parsed, err := flextime.Parse(flexLayout, formatted)
+		require.NoError(t, err, "layout = %s", flexLayout)
+		assert.True(t, target.Equal(parsed), "layout = %s, parsed = %s", flexLayout, parsed)
+	}
+
+	// informational without a day of week.
+	parsed, err := flextime.Parse("YYYY-MM-DD 'W'W", "2022-10-20 W1")
+	require.NoError(t, err)
+	assert.True(t, time.Date(2022, time.October, 20, 0, 0, 0, 0, time.UTC).Equal(parsed))
+	parsed, err = flextime.Parse("YYYY-MM 'W'W", "2022-10 W3")
+	require.NoError(t, err)
+	assert.True(t, time.Date(2022, time.October, 1, 0, 0, 0, 0, time.UTC).Equal(parsed))
+
+	var parseErr *time.ParseError
+	for _, invalid := range []string{"2022-10 W0 Monday", "2022-10 W6 Monday", "2022-02 W5 Tuesday"} {
+		_, err = flextime.Parse("YYYY-MM 'W'W ww", invalid)
+		assert.ErrorAs(t, err, &parseErr, "value = %s", invalid)
+	}
+}
+
+func TestFlexibleOffset(t *testing.T) {
+	l, err := flextime.Compile("YYYY-MM-DDTHH:mmZZZ")
+	require.NoError(t, err)
+
+	for value, offset := range map[string]int{
+		"2022-10-20T23:16+0900": 9 * 60 * 60,
+		"2022-10-20T23:16+09":   9 * 60 * 60,
+		"2022-10-20T23:16+0530": 5*60*60 + 30*60,
+		"2022-10-20T23:16-05":   -5 * 60 * 60,
+		"2022-10-20T23:16Z":     0,
+	} {
+		parsed, err := l.Parse(value)
+		require.NoError(t, err, "value = %s", value)
+		_, actual := parsed.Zone()
+		assert.Equal(t, offset, actual, "value = %s", value)
+		assert.True(t, time.Date(2022, time.October, 20, 23, 16, 0, 0, time.FixedZone("", offset)).Equal(parsed))
+	}
+
+	for _, invalid := range []string{"2022-10-20T23:16+9", "2022-10-20T23:16+09:00", "2022-10-20T23:16"} {
+		_, err := l.Parse(invalid)
+		assert.Error(t, err, "value = %s", invalid)
+	}
+
+	assert.Equal(t, "2022-10-20T23:16+0900", l.Format(time.Date(2022, time.October, 20, 23, 16, 0, 0, jst)))
+	assert.Equal(t, "2022-10-20T23:16Z", l.Format(time.Date(2022, time.October, 20, 23, 16, 0, 0, time.UTC)))
+}
+
+func TestOffsetCount(t *testing.T) {
+	for _, testCase := range []struct {
+		offset  int
+		minutes string
+		seconds string
+	}{
+		{9 * 60 * 60, "540", "32400"},
+		{-8 * 60 * 60, "-480", "-28800"},
+		{5*60*60 + 30*60, "330", "19800"},
+		{-(3*60*60 + 30*60), "-210", "-12600"},
+		{0, "0", "0"},
+	} {
+		target := time.Date(2022, time.October, 20, 23, 16, 22, 0, time.FixedZone("", testCase.offset))
+		for flexLayout, expected := range map[string]string{
+			"YYYY-MM-DD HH:mm:ss zm": "2022-10-20 23:16:22 " + testCase.minutes,
+			"YYYY-MM-DD HH:mm:ss zs": "2022-10-20 23:16:22 " + testCase.seconds,
+		} {
+			formatted, err := flextime.Format(target, flexLayout)
+			require.NoError(t, err)
+			assert.Equal(t, expected, formatted)
+
+			parsed, err := flextime.Parse(flexLayout, formatted)
+			require.NoError(t, err, "value = %s", formatted)
+			assert.True(t, target.Equal(parsed), "value = %s, parsed = %s", formatted, parsed)
+			_, offset := parsed.Zone()
+			assert.Equal(t, testCase.offset, offset)
+		}
+	}
+
+	parsed, err := flextime.Parse("HH:mm zm", "23:16 +540")
+	require.NoError(t, err)
+	_, offset := parsed.Zone()
+	assert.Equal(t, 9*60*60, offset)
+
+	for layout, invalid := range map[string][]string{
+		"HH:mm zm": {"23:16 1441", "23:16 -1441", "23:16 +", "23:16 ", "23:16 99999999999999999999"},
+		"HH:mm zs": {"23:16 86401", "23:16 -86401", "23:16 -"},
+	} {
+		for _, value := range invalid {
+			_, err := flextime.Parse(layout, value)
+			assert.Error(t, err, "layout = %s, value = %s", layout, value)
+		}
+	}
+	for layout, value := range map[string]string{"HH:mm zm": "23:16 -1440", "HH:mm zs": "23:16 86400"} {
+		_, err := flextime.Parse(layout, value)
+		assert.NoError(t, err, "layout = %s, value = %s", layout, value)
+	}
+}
+
+func TestYearWidth(t *testing.T) {
+	cases := []struct {
+		year       int
+		flexLayout string
+		formatted  string
+	}{
+		{2022, "YYYY-MM-DD", "2022-10-20"},
+		{2022, "YYYYYY-MM-DD", "002022-10-20"},
+		{12, "YYYY-MM-DD", "0012-10-20"},
+		{12, "YYYYYY-MM-DD", "000012-10-20"},
+		// the sign is counted outside the width, as %04d and %06d do.
+		{-44, "YYYY-MM-DD", "-0044-10-20"},
+		{-44, "YYYYYY-MM-DD", "-000044-10-20"},
+		{-43, "YYYYYY-MM-DD G", "000044-10-20 BC"},
+		{123456, "YYYYYY-MM-DD", "123456-10-20"},
+	}
+
+	for _, testCase := range cases {
+		target := time.Date(testCase.year, time.October, 20, 0, 0, 0, 0, time.UTC)
+		formatted, err := flextime.Format(target, testCase.flexLayout)
+		require.NoError(t, err)
+		assert.Equal(t, testCase.formatted, formatted)
+
+		parsed, err := flextime.Parse(testCase.flexLayout, formatted)
+		require.NoError(t, err, "layout = %s, value = %s", testCase.flexLayout, formatted)
+		assert.True(t, target.Equal(parsed), "layout = %s, parsed = %s", testCase.flexLayout, parsed)
+	}
+
+	// exactly 6 digits are consumed.
+	for _, invalid := range []string{"2022-10-20", "02022-10-20", "0002022-10-20"} {
+		_, err := flextime.Parse("YYYYYY-MM-DD", invalid)
+		assert.Error(t, err, "value = %s", invalid)
+	}
+	parsed, err := flextime.Parse("YYYYYYMMDD", "0020221020")
+	require.NoError(t, err)
+	assert.True(t, time.Date(2022, time.October, 20, 0, 0, 0, 0, time.UTC).Equal(parsed))
+}
+
+func TestZoneAbbreviationOrOffset(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+
+	for _, testCase := range []struct {
+		loc       *time.Location
+		formatted string
+	}{
+		// named zones.
+		{time.UTC, "2022-10-20 23:16:22 UTC"},
+		{jst, "2022-10-20 23:16:22 JST"},
+		{newYork, "2022-10-20 23:16:22 EDT"},
+		// unnamed zones fall back to the numeric offset.
+		{time.FixedZone("", 9*60*60), "2022-10-20 23:16:22 +0900"},
+		{time.FixedZone("", -(3*60*60 + 30*60)), "2022-10-20 23:16:22 -0330"},
+		{time.FixedZone("", 0), "2022-10-20 23:16:22 +0000"},
+	} {
+		target := time.Date(2022, time.October, 20, 23, 16, 22, 0, testCase.loc)
+		formatted, err := flextime.Format(target, "YYYY-MM-DD HH:mm:ss zz")
+		require.NoError(t, err)
+		assert.Equal(t, testCase.formatted, formatted)
+	}
+
+	for value, offset := range map[string]int{
+		"23:16 +0900":  9 * 60 * 60,
+		"23:16 +09:00": 9 * 60 * 60,
+		"23:16 -03":    -3 * 60 * 60,
+		"23:16 UTC":    0,
+	} {
+		parsed, err := flextime.Parse("HH:mm zz", value)
+		require.NoError(t, err, "value = %s", value)
+		_, parsedOffset := parsed.Zone()
+		assert.Equal(t, offset, parsedOffset, "value = %s", value)
+	}
+
+	// abbreviations are parsed as the MST token does: known ones in loc get its offset.
+	parsed, err := flextime.ParseInLocation("YYYY HH:mm zz", "2022 23:16 JST", jst)
+	require.NoError(t, err)
+	_, offset := parsed.Zone()
+	assert.Equal(t, 9*60*60, offset)
+	parsed, err = flextime.Parse("HH:mm zz", "23:16 JST")
+	require.NoError(t, err)
+	name, _ := parsed.Zone()
+	assert.Equal(t, "JST", name)
+
+	for _, invalid := range []string{"23:16 +9", "23:16 ", "23:16 +", "23:16 09:00"} {
+		_, err := flextime.Parse("HH:mm zz", invalid)
+		assert.Error(t, err, "value = %s", invalid)
+	}
+
+	_, err = flextime.ToGoLayout("HH:mm zz")
+	var formatErr *flextime.FormatError
+	assert.ErrorAs(t, err, &formatErr)
+}
@@ -0,0 +1,39 @@
+package flextime
+
+import "time"
+
+// CommonLayouts are flextime layouts tried by DetectLayout, in order.
+// Append or prepend layouts to extend detection.
+var CommonLayouts = []string{
+	// RFC 3339 and ISO 8601 extended format.
+	"YYYY-MM-DDTHH:mm:ss[.999999999](Z|-0700)",
+	"YYYY-MM-DD[THH:mm[:ss[.999999999]]]",
+	"YYYY-MM-DD HH:mm[:ss[(.|,)999999999]][ ](Z|-0700)",
+	"YYYY-MM-DD HH:mm[:ss[(.|,)999999999]]",
+	// ISO 8601 basic format.
+	"YYYYMMDD[THHmmss[Z]]",
+	// RFC 1123, RFC 850, RFC 822 and Unix date(1).
+	"w, DD MMM YYYY HH:mm:ss (MST|-0700)",
+	"ww, DD-MMM-YY HH:mm:ss MST",
+	"DD MMM YY HH:mm (MST|-0700)",
+	"w MMM D HH:mm:ss[ MST] YYYY",
+	// Common Log Format of web servers.
+	"DD/MMM/YYYY:HH:mm:ss -0700",
+	// syslog, which has no year.
+	"MMM D HH:mm:ss",
+	"YYYY/MM/DD[ HH:mm[:ss]]",
+	"MM/DD/YYYY[ HH:mm[:ss]]",
+	"DD.MM.YYYY[ HH:mm[:ss]]",
+	// Unix time in seconds.
+	// x, Unix time in milliseconds, is not listed since X also parses such values, as seconds.
+	"X",
+}
+
+// DetectLayout parses value by CommonLayouts and returns the layout which parsed it.
+// Layouts are tried in order as ParseAny does,
+// thus an ambiguous value, e.g. 01/02/2006, is parsed by the first matching layout in the list.
+// If none of them matches, it returns *ParseAnyError.
+func DetectLayout(value string) (string, time.Time, error) {
+	t, layout, err := ParseAny(CommonLayouts, value)
+	return layout, t, err
+}
@@ -0,0 +1,88 @@
+package flextime_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ngicks/flextime"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectLayout(t *testing.T) {
+	target := time.Date(2022, time.October, 20, 23, 16, 22, 168000000, jst)
+
+	for _, testCase := range []struct {
+		value    string
+		layout   string
+		expected time.Time
+	}{
+		{
+			"2022-10-20T23:16:22.168+09:00",
+			"YYYY-MM-DDTHH:mm:ss[.999999999](Z|-0700)",
+			target,
+		},
+		{
+			"2022-10-20T23:16:22+0900",
+			"YYYY-MM-DDTHH:mm:ss[.999999999](Z|-0700)",
+			target.Truncate(time.Second),
+		},
+		{
+			"2022-10-20",
+			"YYYY-MM-DD[THH:mm[:ss[.999999999]]]",
+			time.Date(2022, time.October, 20, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			"2022-10-20 23:16:22,168 +09:00",
+			"YYYY-MM-DD HH:mm[:ss[(.|,)999999999]][ ](Z|-0700)",
+			target,
+		},
+		{
+			"2022-10-20 23:16",
+			"YYYY-MM-DD HH:mm[:ss[(.|,)999999999]]",
+			time.Date(2022, time.October, 20, 23, 16, 0, 0, time.UTC),
+		},
+		{
+			"20221020T231622Z",
+			"YYYYMMDD[THHmmss[Z]]",
+			time.Date(2022, time.October, 20, 23, 16, 22, 0, time.UTC),
+		},
+		{
+			"Thu, 20 Oct 2022 23:16:22 +0900",
+			"w, DD MMM YYYY HH:mm:ss (MST|-0700)",
+			target.Truncate(time.Second),
+		},
+		{
+			"20/Oct/2022:23:16:22 +0900",
+			"DD/MMM/YYYY:HH:mm:ss -0700",
+			target.Truncate(time.Second),
+		},
+		{
+			// ambiguous, the first match in the list wins.
+			"10/11/2022",
+			"MM/DD/YYYY[ HH:mm[:ss]]",
+			time.Date(2022, time.October, 11, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			"1666275382",
+			"X",
+			target.Truncate(time.Second),
+		},
+	} {
+		layout, parsed, err := flextime.DetectLayout(testCase.value)
+		require.NoError(t, err, "value = %s", testCase.value)
+		assert.Equal(t, testCase.layout, layout, "value = %s", testCase.value)
+		assert.True(t, testCase.expected.Equal(parsed), "value = %s, parsed = %s", testCase.value, parsed)
+	}
+
+	_, _, err := flextime.DetectLayout("not a time")
+	var parseAnyErr *flextime.ParseAnyError
+	assert.ErrorAs(t, err, &parseAnyErr)
+}
+
+func TestCommonLayoutsAreValid(t *testing.T) {
+	for _, layout := range flextime.CommonLayouts {
+		_, err := flextime.Compile(layout)
+		assert.NoError(t, err, "layout = %s", layout)
+	}
+}
@@ -0,0 +1,160 @@
+package flextime
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// tokenTables are tables to look up flextime tokens.
+// They are never mutated once used: Dialect replaces them as a whole on registration.
+type tokenTables struct {
+	// search maps the first byte of tokens to tokens starting with it, longer first.
+	search map[byte][]timeFormatToken
+	// goFmt maps tokens to Go reference layouts.
+	goFmt map[timeFormatToken]goTimeFmtToken
+	// computed maps computed tokens registered by RegisterComputedToken to their callbacks.
+	// Built-in computed tokens are in computedTokenTable.
+	computed map[timeFormatToken]*computedToken
+	// literalUnknown makes characters starting no token literals, see LiteralUnknownTokens.
+	literalUnknown bool
+}
+
+var (
+	// defaultTablesPtr holds the default tables.
+	// They are replaced as a whole by RegisterComputedToken, serialized by registerMu.
+	// It is initialized along with variables rather than in init, since predefined layouts are compiled with it.
+	defaultTablesPtr = newTablesPointer(&tokenTables{
+		search: tokenSerachTable,
+		goFmt:  tokenTable,
+	})
+	registerMu sync.Mutex
+)
+
+func newTablesPointer(tables *tokenTables) *atomic.Pointer[tokenTables] {
+	var p atomic.Pointer[tokenTables]
+	p.Store(tables)
+	return &p
+}
+
+// defaultTables returns the current default tables.
+func defaultTables() *tokenTables {
+	return defaultTablesPtr.Load()
+}
+
+// isComputed reports whether tt is a computed token, built-in or registered to tables.
+func (tables *tokenTables) isComputed(tt timeFormatToken) bool {
+	return tt.isComputed() || tables.computed[tt] != nil
+}
+
+// withLiteralUnknown returns tables which treat characters starting no token as literals.
+func (tables *tokenTables) withLiteralUnknown() *tokenTables {
+	lenient := *tables
+	lenient.literalUnknown = true
+	return &lenient
+}
+
+// clone returns a copy of tables which can be modified without affecting tables.
+func (tables *tokenTables) clone() *tokenTables {
+	cloned := &tokenTables{
+		search:   make(map[byte][]timeFormatToken, len(tables.search)),
+		goFmt:    make(map[timeFormatToken]goTimeFmtToken, len(tables.goFmt)),
+		computed: make(map[timeFormatToken]*computedToken, len(tables.computed)),
+	}
+	for k, v := range tables.search {
+		cloned.search[k] = append([]timeFormatToken(nil), v...)
+	}
+	for k, v := range tables.goFmt {
+		cloned.goFmt[k] = v
+	}
+	for k, v := range tables.computed {
+		cloned.computed[k] = v
+	}
+	return cloned
+}
+
+// Dialect is a set of flextime tokens.
+// It starts with the default tokens and additional tokens can be registered by RegisterToken.
+// Pass it to CompileWith to compile layouts with its tokens.
+// Package level functions, e.g. Parse or Compile, always use the default tokens.
+//
+// A Dialect is safe for concurrent use.
+// Layouts already compiled are not affected by tokens registered later.
+type Dialect struct {
+	mu     sync.RWMutex
+	tables *tokenTables
+}
+
+// NewDialect returns a new Dialect which has the default tokens.
+func NewDialect() *Dialect {
+	return &Dialect{tables: defaultTables()}
+}
+
+// snapshot returns the current tables of d.
+func (d *Dialect) snapshot() *tokenTables {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.tables
+}
+
+// RegisterToken registers token as an alias of goLayout, a single Go reference layout element,
+// e.g. "Monday", "Z0700" or ".000". An already registered token is overwritten.
+//
+// Once registered, the first character of token starts a token in layouts compiled with d,
+// thus it must be quoted to be used literally if no token it starts matches.
+// token must not contain characters special to the layout syntax,
+// i.e. brackets, parentheses, pipes, single quotes and backslashes,
+// must not start with '.' or ',' and must not be a computed token, e.g. Do.
+func (d *Dialect) RegisterToken(token, goLayout string) error {
+	if err := validateToken(token); err != nil {
+		return err
+	}
+	if prefix, std, suffix := NextStdChunk(goLayout); prefix != "" || std == 0 || suffix != "" {
+		return fmt.Errorf("flextime: %q is not a single Go reference layout element", goLayout)
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.tables.isComputed(timeFormatToken(token)) {
+		return fmt.Errorf("flextime: token %q is a computed token", token)
+	}
+	tables := d.tables.clone()
+	tt := timeFormatToken(token)
+	if _, ok := tables.goFmt[tt]; !ok {
+		tables.addSearch(tt)
+	}
+	tables.goFmt[tt] = goTimeFmtToken(goLayout)
+	d.tables = tables
+	return nil
+}
+
+// validateToken checks token is usable as a flextime token, see RegisterToken.
+func validateToken(token string) error {
+	if token == "" {
+		return errors.New("flextime: empty token")
+	}
+	if strings.ContainsAny(token, `[]()|'\`) || token[0] == '.' || token[0] == ',' {
+		return fmt.Errorf("flextime: token %q contains a special character", token)
+	}
+	return nil
+}
+
+// addSearch adds tt to the search table.
+func (tables *tokenTables) addSearch(tt timeFormatToken) {
+	candidates := append(tables.search[tt[0]], tt)
+	// longer tokens must be tried first, as the default table lists them.
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return len(candidates[i]) > len(candidates[j])
+	})
+	tables.search[tt[0]] = candidates
+}
+
+// CompileWith is like Compile but recognizes tokens of d.
+// Tokens of d are looked up once when compiling.
+func CompileWith(d *Dialect, flexLayout string, opts ...Option) (*Layout, error) {
+	return compile(context.Background(), d.snapshot(), flexLayout, opts...)
+}
@@ -0,0 +1,61 @@
+package flextime_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ngicks/flextime"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDialect(t *testing.T) {
+	target := time.Date(2022, time.October, 20, 23, 16, 22, 0, jst)
+
+	d := flextime.NewDialect()
+	require.NoError(t, d.RegisterToken("dddd", "Monday"))
+
+	l, err := flextime.CompileWith(d, "dddd, YYYY-MM-DD[ HH:mm]")
+	require.NoError(t, err)
+	assert.Equal(t, "Thursday, 2022-10-20 23:16", l.Format(target))
+	parsed, err := l.Parse("Thursday, 2022-10-20")
+	require.NoError(t, err)
+	assert.True(t, time.Date(2022, time.October, 20, 0, 0, 0, 0, time.UTC).Equal(parsed))
+
+	// the default tokens are not affected: dddd is ddd followed by d.
+	formatted, err := flextime.Format(target, "dddd")
+	require.NoError(t, err)
+	assert.Equal(t, "29320", formatted)
+
+	before, err := flextime.CompileWith(d, "HH:mmTZ")
+	require.NoError(t, err)
+	require.NoError(t, d.RegisterToken("TZ", "-0700"))
+	after, err := flextime.CompileWith(d, "HH:mmTZ")
+	require.NoError(t, err)
+	// layouts compiled before registration keep their tokens.
+	assert.Equal(t, "23:16T+09:00", before.Format(target))
+	assert.Equal(t, "23:16+0900", after.Format(target))
+
+	// the first character of a registered token starts a token.
+	_, err = flextime.CompileWith(d, "YYYY-MM-DDTHH")
+	var formatErr *flextime.FormatError
+	assert.ErrorAs(t, err, &formatErr)
+	_, err = flextime.CompileWith(d, "YYYY-MM-DD'T'HH")
+	assert.NoError(t, err)
+
+	for _, invalid := range []struct {
+		token    string
+		goLayout string
+	}{
+		{"", "Monday"},
+		{"[x", "Monday"},
+		{"x|", "Monday"},
+		{".x", "Monday"},
+		{"Do", "02"},
+		{"xx", "2006-01"},
+		{"xx", "foo"},
+		{"xx", ""},
+	} {
+		assert.Error(t, d.RegisterToken(invalid.token, invalid.goLayout), "token = %q, goLayout = %q", invalid.token, invalid.goLayout)
+	}
+}
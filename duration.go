@@ -0,0 +1,285 @@
+package flextime
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	optionalstring "github.com/ngicks/flextime/optional_string"
+)
+
+// durationUnits maps tokens of duration layouts to their units.
+var durationUnits = map[timeFormatToken]time.Duration{
+	"HH": time.Hour, "H": time.Hour,
+	"mm": time.Minute, "m": time.Minute,
+	"ss": time.Second, "s": time.Second,
+}
+
+// durationLayout is a duration layout split into chunks.
+type durationLayout struct {
+	flexLayout string
+	chunks     []layoutChunk
+	// bounds are, for each chunk, the limit of its value in its unit.
+	// The largest unit in the layout has no limit, 0.
+	bounds []int64
+}
+
+// compileDuration enumerates optional parts of flexLayout and splits them into chunks.
+// format is the first enumerated one. parse are all of them in the order parsing tries them.
+func compileDuration(flexLayout string) (format durationLayout, parse []durationLayout, err error) {
+	rawFormats, err := optionalstring.EnumerateOptionalStringRaw(flexLayout)
+	if err != nil {
+		return durationLayout{}, nil, err
+	}
+	layouts := make([]durationLayout, 0, len(rawFormats))
+	for _, raw := range rawFormats {
+		l, err := newDurationLayout(raw)
+		if err != nil {
+			return durationLayout{}, nil, err
+		}
+		layouts = append(layouts, l)
+	}
+	format = layouts[0]
+	// same as Layout: more tokens first, then longer ones.
+	sort.SliceStable(layouts, func(i, j int) bool {
+		if ti, tj := layouts[i].tokenCount(), layouts[j].tokenCount(); ti != tj {
+			return ti > tj
+		}
+		return len(layouts[i].flexLayout) > len(layouts[j].flexLayout)
+	})
+	return format, layouts, nil
+}
+
+func newDurationLayout(raw optionalstring.RawString) (durationLayout, error) {
+	chunks, err := defaultTables().splitChunksRaw(raw)
+	if err != nil {
+		return durationLayout{}, err
+	}
+	l := durationLayout{flexLayout: raw.String(), chunks: chunks, bounds: make([]int64, len(chunks))}
+	for _, c := range chunks {
+		if !c.isToken() || isFracToken(c.token) {
+			continue
+		}
+		if _, ok := durationUnits[c.token]; !ok {
+			return durationLayout{}, &FormatError{
+				layout:   l.flexLayout,
+				idx:      c.offset,
+				expected: "must be a duration token, H, HH, m, mm, s, ss or fractional seconds",
+				actual:   string(c.token),
+				msg:      fmt.Sprintf("%s has no meaning in durations.", c.token),
+			}
+		}
+	}
+	for i, c := range chunks {
+		unit, ok := durationUnits[c.token]
+		if !ok {
+			continue
+		}
+		// bounded by the next larger unit in the layout, e.g. ss by mm, or by HH if no mm.
+		var larger time.Duration
+		for _, other := range chunks {
+			if u, ok := durationUnits[other.token]; ok && u > unit && (larger == 0 || u < larger) {
+				larger = u
+			}
+		}
+		l.bounds[i] = int64(larger / unit)
+	}
+	return l, nil
+}
+
+func (l durationLayout) tokenCount() int {
+	var count int
+	for _, c := range l.chunks {
+		if c.isToken() {
+			count++
+		}
+	}
+	return count
+}
+
+func (l durationLayout) appendFormat(b []byte, d time.Duration) []byte {
+	// uint64 so that the absolute value of math.MinInt64 does not overflow.
+	abs := uint64(d)
+	if d < 0 {
+		b = append(b, '-')
+		abs = -abs
+	}
+	for i, c := range l.chunks {
+		switch {
+		case !c.isToken():
+			b = append(b, c.literal...)
+		case isFracToken(c.token):
+			b = appendDurationFrac(b, c.token, abs%uint64(time.Second))
+		default:
+			v := abs / uint64(durationUnits[c.token])
+			if l.bounds[i] > 0 {
+				v %= uint64(l.bounds[i])
+			}
+			if len(c.token) == 2 && v < 10 {
+				b = append(b, '0')
+			}
+			b = strconv.AppendUint(b, v, 10)
+		}
+	}
+	return b
+}
+
+// appendDurationFrac appends nsec as a fractional second token, e.g. .SSS or .999.
+func appendDurationFrac(b []byte, token timeFormatToken, nsec uint64) []byte {
+	digits := len(token) - 1
+	frac := fmt.Sprintf("%09d", nsec)[:digits]
+	if token[1] == '9' {
+		frac = strings.TrimRight(frac, "0")
+		if frac == "" {
+			return b
+		}
+	}
+	b = append(b, token[0])
+	return append(b, frac...)
+}
+
+func (l durationLayout) parse(value string) (time.Duration, error) {
+	neg, rest := l.cutSign(value)
+	var total uint64
+	for i, c := range l.chunks {
+		switch {
+		case !c.isToken():
+			if !strings.HasPrefix(rest, c.literal) {
+				return 0, fmt.Errorf("expected %q but %q", c.literal, rest)
+			}
+			rest = rest[len(c.literal):]
+		case isFracToken(c.token):
+			var nsec uint64
+			var err error
+			nsec, rest, err = parseDurationFrac(c.token, rest)
+			if err != nil {
+				return 0, err
+			}
+			total += nsec
+		default:
+			n := 0
+			for n < len(rest) && n < 19 && isDigit(rest, n) {
+				n++
+			}
+			if n == 0 || (len(c.token) == 2 && n < 2) {
+				return 0, fmt.Errorf("expected %s but %q", c.token, rest)
+			}
+			var v uint64
+			for _, digit := range rest[:n] {
+				v = v*10 + uint64(digit-'0')
+			}
+			rest = rest[n:]
+			if l.bounds[i] > 0 && v >= uint64(l.bounds[i]) {
+				return 0, fmt.Errorf("%s out of range", c.token)
+			}
+			unit := uint64(durationUnits[c.token])
+			if v > math.MaxInt64/unit || total+v*unit > math.MaxInt64 {
+				return 0, fmt.Errorf("%s out of range", c.token)
+			}
+			total += v * unit
+		}
+	}
+	if rest != "" {
+		return 0, fmt.Errorf("extra text: %q", rest)
+	}
+	if total > math.MaxInt64 {
+		return 0, fmt.Errorf("out of range")
+	}
+	if neg {
+		return -time.Duration(total), nil
+	}
+	return time.Duration(total), nil
+}
+
+// cutSign cuts a leading '-' or '+' off value, as appendFormat writes '-' before the whole layout.
+// A sign which is the start of the leading literal of the layout is kept,
+// e.g. -05 by '-'HH is 5 hours while --05 is -5 hours.
+func (l durationLayout) cutSign(value string) (neg bool, rest string) {
+	if value == "" || (value[0] != '-' && value[0] != '+') {
+		return false, value
+	}
+	if len(l.chunks) > 0 && !l.chunks[0].isToken() {
+		literal := l.chunks[0].literal
+		if strings.HasPrefix(value, literal) && !strings.HasPrefix(value[1:], literal) {
+			return false, value
+		}
+	}
+	return value[0] == '-', value[1:]
+}
+
+// parseDurationFrac reads a fractional second token, e.g. .SSS or .999, in nanoseconds.
+// Either '.' or ',' is accepted as the separator.
+// Tokens of 9 read any number of digits and may be absent as a whole. Others read exactly their number of digits.
+func parseDurationFrac(token timeFormatToken, value string) (nsec uint64, rest string, err error) {
+	fixed := token[1] != '9'
+	if value == "" || (value[0] != '.' && value[0] != ',') {
+		if fixed {
+			return 0, value, fmt.Errorf("expected %s but %q", token, value)
+		}
+		return 0, value, nil
+	}
+	n := 1
+	for n < len(value) && isDigit(value, n) {
+		n++
+	}
+	digits := value[1:n]
+	if fixed && len(digits) != len(token)-1 {
+		return 0, value, fmt.Errorf("expected %s but %q", token, value)
+	}
+	if len(digits) > 9 {
+		digits = digits[:9]
+	}
+	for i := 0; i < 9; i++ {
+		nsec *= 10
+		if i < len(digits) {
+			nsec += uint64(digits[i] - '0')
+		}
+	}
+	return nsec, value[n:], nil
+}
+
+// FormatDuration returns a textual representation of d by flexLayout.
+// See ParseDuration for tokens of duration layouts.
+// If flexLayout has optional parts, all of them are present and the first branch is taken for each alternation.
+func FormatDuration(flexLayout string, d time.Duration) (string, error) {
+	format, _, err := compileDuration(flexLayout)
+	if err != nil {
+		return "", err
+	}
+	return string(format.appendFormat(nil, d)), nil
+}
+
+// ParseDuration parses value by flexLayout, a layout of durations, e.g. HH'h'mm'm' for 01h30m.
+//
+// Tokens of duration layouts are:
+//   - HH and H: hours. HH is zero padded to 2 digits.
+//   - mm and m: minutes. mm is zero padded to 2 digits.
+//   - ss and s: seconds. ss is zero padded to 2 digits.
+//   - fractional seconds, e.g. .SSS, .000 or .999, as in time layouts.
+//
+// The largest unit in the layout carries the whole of it, e.g. 90 minutes is 90:00 by mm:ss,
+// and smaller ones are up to the next larger unit in the layout, e.g. 0 to 59 for mm of HH:mm.
+// Other tokens, e.g. YYYY, are *FormatError.
+// A negative duration is formatted with a leading '-'.
+// A leading '-' or '+' of value is accepted as the sign, even if flexLayout starts with a literal.
+// Optional parts are enumerated as Parse does and the most specific one which parses value wins.
+func ParseDuration(flexLayout, value string) (time.Duration, error) {
+	_, layouts, err := compileDuration(flexLayout)
+	if err != nil {
+		return 0, err
+	}
+	var firstErr error
+	for _, l := range layouts {
+		d, err := l.parse(value)
+		if err == nil {
+			return d, nil
+		}
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+	return 0, fmt.Errorf("flextime: parsing duration %q as %q: %w", value, flexLayout, firstErr)
+}
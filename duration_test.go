@@ -0,0 +1,71 @@
+package flextime_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ngicks/flextime"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDuration(t *testing.T) {
+	for _, testCase := range []struct {
+		flexLayout string
+		d          time.Duration
+		formatted  string
+	}{
+		{`HH'h'mm'm'`, 90 * time.Minute, "01h30m"},
+		{`HH:mm`, 9 * time.Hour, "09:00"},
+		{`HH:mm`, -(9*time.Hour + 30*time.Minute), "-09:30"},
+		{`HH:mm`, 100 * time.Hour, "100:00"},
+		{`H:mm:ss`, time.Hour + 2*time.Minute + 3*time.Second, "1:02:03"},
+		{`mm:ss`, 90 * time.Minute, "90:00"},
+		{`m'm'ss's'`, 61 * time.Second, "1m01s"},
+		{`ss.SSS`, 61*time.Second + 12*time.Millisecond, "61.012"},
+		{`ss.999`, 61*time.Second + 120*time.Millisecond, "61.12"},
+		{`ss.999`, 61 * time.Second, "61"},
+		{`HH:ss`, time.Hour + 61*time.Second, "01:61"},
+		{`'T'HH:mm`, -(9*time.Hour + 30*time.Minute), "-T09:30"},
+		{`'-'HH`, 5 * time.Hour, "-05"},
+		{`'-'HH`, -5 * time.Hour, "--05"},
+	} {
+		formatted, err := flextime.FormatDuration(testCase.flexLayout, testCase.d)
+		require.NoError(t, err)
+		assert.Equal(t, testCase.formatted, formatted, "layout = %s", testCase.flexLayout)
+		parsed, err := flextime.ParseDuration(testCase.flexLayout, formatted)
+		require.NoError(t, err, "layout = %s", testCase.flexLayout)
+		assert.Equal(t, testCase.d, parsed, "layout = %s", testCase.flexLayout)
+	}
+
+	// optional parts.
+	for _, testCase := range []struct {
+		value    string
+		expected time.Duration
+	}{
+		{"01:30", 90 * time.Minute},
+		{"01:30:15", 90*time.Minute + 15*time.Second},
+		{"+09:00", 9 * time.Hour},
+		{"-01:30:15.5", -(90*time.Minute + 15*time.Second + 500*time.Millisecond)},
+	} {
+		parsed, err := flextime.ParseDuration(`HH:mm[:ss[.999]]`, testCase.value)
+		require.NoError(t, err, testCase.value)
+		assert.Equal(t, testCase.expected, parsed, testCase.value)
+	}
+	formatted, err := flextime.FormatDuration(`HH:mm[:ss]`, 90*time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, "01:30:00", formatted)
+
+	for _, invalid := range []string{"1:30", "01:60", "01:30:", "01:30x", "", "-", "99999999999:00"} {
+		_, err := flextime.ParseDuration(`HH:mm[:ss]`, invalid)
+		assert.Error(t, err, invalid)
+	}
+
+	var formatErr *flextime.FormatError
+	for _, invalid := range []string{"YYYY", "hh:mm", "HH:mm Z"} {
+		_, err := flextime.ParseDuration(invalid, "")
+		assert.ErrorAs(t, err, &formatErr, invalid)
+		_, err = flextime.FormatDuration(invalid, 0)
+		assert.ErrorAs(t, err, &formatErr, invalid)
+	}
+}
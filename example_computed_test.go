@@ -0,0 +1,64 @@
+package flextime_test
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/ngicks/flextime"
+)
+
+// bmt is Biel Mean Time, the time zone of Swatch Internet Time.
+var bmt = time.FixedZone("BMT", 60*60)
+
+func ExampleRegisterComputedToken() {
+	// @beat is Swatch Internet Time: a day in BMT is divided into 1000 beats.
+	// Registering it to a Dialect keeps it to layouts compiled with the Dialect.
+	d := flextime.NewDialect()
+	err := d.RegisterComputedToken(
+		"@beat",
+		func(t time.Time) string {
+			t = t.In(bmt)
+			ms := (t.Hour()*3600+t.Minute()*60+t.Second())*1000 + t.Nanosecond()/1e6
+			return fmt.Sprintf("@%03d", ms/86400)
+		},
+		func(s string) (flextime.ComputedFields, int, error) {
+			if len(s) < 4 || s[0] != '@' {
+				return flextime.ComputedFields{}, 0, fmt.Errorf("bad beat")
+			}
+			beat, err := strconv.Atoi(s[1:4])
+			if err != nil || beat < 0 {
+				return flextime.ComputedFields{}, 0, fmt.Errorf("bad beat")
+			}
+			ms := beat * 86400
+			return flextime.ComputedFields{
+				Set:        flextime.FieldHour | flextime.FieldMinute | flextime.FieldSecond | flextime.FieldNano | flextime.FieldZone,
+				Hour:       ms / 3600000,
+				Minute:     ms / 60000 % 60,
+				Second:     ms / 1000 % 60,
+				Nanosecond: ms % 1000 * 1e6,
+				Location:   bmt,
+			}, 4, nil
+		},
+	)
+	if err != nil {
+		panic(err)
+	}
+
+	l, err := flextime.CompileWith(d, "YYYY-MM-DD @beat")
+	if err != nil {
+		panic(err)
+	}
+	target := time.Date(2022, time.October, 20, 12, 0, 0, 0, time.UTC)
+	formatted := l.Format(target)
+	fmt.Println(formatted)
+
+	parsed, err := l.Parse(formatted)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(parsed.UTC())
+	// Output:
+	// 2022-10-20 @541
+	// 2022-10-20 11:59:02.4 +0000 UTC
+}
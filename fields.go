@@ -0,0 +1,98 @@
+package flextime
+
+import "strings"
+
+// FieldSet is a set of fields of time.
+type FieldSet uint16
+
+const (
+	FieldYear FieldSet = 1 << iota
+	FieldMonth
+	FieldDay
+	FieldHour
+	FieldMinute
+	FieldSecond
+	FieldNano
+	FieldZone
+	FieldWeekday
+	FieldYearDay
+)
+
+var fieldNames = [...]string{
+	"Year", "Month", "Day", "Hour", "Minute", "Second", "Nano", "Zone", "Weekday", "YearDay",
+}
+
+// Has reports whether s contains all fields of f.
+func (s FieldSet) Has(f FieldSet) bool {
+	return s&f == f
+}
+
+func (s FieldSet) String() string {
+	if s == 0 {
+		return "0"
+	}
+	var names []string
+	for i, name := range fieldNames {
+		if s&(1<<i) != 0 {
+			names = append(names, name)
+		}
+	}
+	return strings.Join(names, "|")
+}
+
+// fieldSets maps field kinds, see fieldKinds, to the fields they populate.
+var fieldSets = map[string]FieldSet{
+	"year":              FieldYear,
+	"ISO year":          FieldYear,
+	"era":               FieldYear,
+	"month":             FieldMonth,
+	"quarter":           FieldMonth,
+	"ISO week":          FieldMonth | FieldDay,
+	"day of month":      FieldDay,
+	"week of month":     FieldDay,
+	"day of year":       FieldYearDay,
+	"day of week":       FieldWeekday,
+	"hour":              FieldHour,
+	"AM/PM":             FieldHour,
+	"time of day":       FieldHour | FieldMinute | FieldSecond,
+	"minute":            FieldMinute,
+	"second":            FieldSecond,
+	"fractional second": FieldNano,
+	"epoch":             FieldYear | FieldMonth | FieldDay | FieldHour | FieldMinute | FieldSecond,
+	"time zone":         FieldZone,
+}
+
+// fieldsOf returns the fields token populates.
+func fieldsOf(token timeFormatToken) FieldSet {
+	fields := fieldSets[fieldKindOf(token)]
+	switch token {
+	case "msod", "x":
+		// milliseconds are read as well.
+		fields |= FieldNano
+	case "mod":
+		fields &^= FieldSecond
+	}
+	return fields
+}
+
+// Fields returns the set of fields flexLayout populates on Parse, derived from tokens returned by Tokenize.
+// Tokens in optional parts and in all branches of alternations are included.
+// Tokens which set a field partially are reported as the field,
+// e.g. A for FieldHour, Q for FieldMonth and G for FieldYear,
+// and tokens which decide several fields are reported as all of them,
+// e.g. sod for FieldHour, FieldMinute and FieldSecond, mod for FieldHour and FieldMinute, and X for all but FieldNano, FieldZone, FieldWeekday and FieldYearDay.
+// Computed tokens registered by RegisterComputedToken are not known to populate any field.
+// It returns *FormatError if flexLayout contains an invalid token.
+func Fields(flexLayout string) (FieldSet, error) {
+	tokens, err := Tokenize(flexLayout)
+	if err != nil {
+		return 0, err
+	}
+	var fields FieldSet
+	for _, t := range tokens {
+		if t.IsTimeToken {
+			fields |= fieldsOf(timeFormatToken(t.Value))
+		}
+	}
+	return fields, nil
+}
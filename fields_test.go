@@ -0,0 +1,40 @@
+package flextime_test
+
+import (
+	"testing"
+
+	"github.com/ngicks/flextime"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFields(t *testing.T) {
+	fields, err := flextime.Fields(`HH:mm`)
+	require.NoError(t, err)
+	assert.Equal(t, flextime.FieldHour|flextime.FieldMinute, fields)
+	assert.Equal(t, "Hour|Minute", fields.String())
+
+	type testCase struct {
+		layout   string
+		expected flextime.FieldSet
+	}
+	for _, tc := range []testCase{
+		{`YYYY-MM-DD`, flextime.FieldYear | flextime.FieldMonth | flextime.FieldDay},
+		{`YYYY-MM-DD[THH:mm[:ss[.SSS]]][Z]`, flextime.FieldYear | flextime.FieldMonth | flextime.FieldDay |
+			flextime.FieldHour | flextime.FieldMinute | flextime.FieldSecond | flextime.FieldNano | flextime.FieldZone},
+		{`ww, DD MMM YYYY`, flextime.FieldWeekday | flextime.FieldDay | flextime.FieldMonth | flextime.FieldYear},
+		{`YYYY-DDD`, flextime.FieldYear | flextime.FieldYearDay},
+		{`h:mm A`, flextime.FieldHour | flextime.FieldMinute},
+		{`sod`, flextime.FieldHour | flextime.FieldMinute | flextime.FieldSecond},
+		{`HH(:|.)mm`, flextime.FieldHour | flextime.FieldMinute},
+		{`'HH:mm'`, 0},
+	} {
+		fields, err := flextime.Fields(tc.layout)
+		require.NoError(t, err)
+		assert.Equal(t, tc.expected, fields, "layout = %s, fields = %s", tc.layout, fields)
+	}
+
+	_, err = flextime.Fields(`YYYY-MM-DD SSSS`)
+	var formatErr *flextime.FormatError
+	assert.ErrorAs(t, err, &formatErr)
+}
@@ -0,0 +1,49 @@
+package flextime
+
+import (
+	"errors"
+	"fmt"
+	"time"
+	"unicode/utf8"
+)
+
+// ErrTimeNotFound is returned from FindTime when no part of the text matches the layout.
+var ErrTimeNotFound = errors.New("flextime: no time found")
+
+// FindTime searches text for the first substring which is parsed by flexLayout
+// and returns the parsed time along with its byte span, text[start:end].
+// See (*Layout).FindTime for the matching strategy.
+func FindTime(flexLayout, text string) (t time.Time, start, end int, err error) {
+	l, err := compileCached(flexLayout)
+	if err != nil {
+		return time.Time{}, -1, -1, err
+	}
+	return l.FindTime(text)
+}
+
+// FindTime searches text for the first substring which is parsed by l
+// and returns the parsed time along with its byte span, text[start:end].
+//
+// The search is a sliding window: starting from each rune of text from left to right,
+// enumerated layouts are tried in the order Parse tries them
+// against the rest of text, allowing trailing text after the time.
+// Thus the leftmost match wins, and among matches at the same position,
+// the most specific enumerated layout wins rather than the longest one.
+// Note that a match may start in the middle of a run of digits or a word,
+// e.g. YY-MM-DD finds 22-10-20 in 2022-10-20.
+//
+// If nothing matches, it returns an error wrapping ErrTimeNotFound, with start and end set to -1.
+func (l *Layout) FindTime(text string) (t time.Time, start, end int, err error) {
+	for start = 0; start <= len(text); {
+		t, rest, err := l.parsePrefix(text[start:])
+		if err == nil {
+			return t, start, len(text) - len(rest), nil
+		}
+		if start == len(text) {
+			break
+		}
+		_, size := utf8.DecodeRuneInString(text[start:])
+		start += size
+	}
+	return time.Time{}, -1, -1, fmt.Errorf("%w: layout %q, text %q", ErrTimeNotFound, l.flexLayout, text)
+}
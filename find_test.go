@@ -0,0 +1,86 @@
+package flextime_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ngicks/flextime"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindTime(t *testing.T) {
+	cases := []struct {
+		flexLayout string
+		text       string
+		expected   time.Time
+		found      string
+	}{
+		{
+			"YYYY-MM-DD HH:mm:ss",
+			"[INFO] 2022-10-20 23:16:22 started",
+			time.Date(2022, time.October, 20, 23, 16, 22, 0, time.UTC),
+			"2022-10-20 23:16:22",
+		},
+		{
+			// the most specific enumerated layout wins.
+			"YYYY-MM-DD[THH:mm[:ss]][Z]",
+			"id=42 at=2022-10-20T23:16+09:00 msg=ok",
+			time.Date(2022, time.October, 20, 23, 16, 0, 0, jst),
+			"2022-10-20T23:16+09:00",
+		},
+		{
+			"YYYY-MM-DD[THH:mm[:ss]][Z]",
+			"id=42 at=2022-10-20 msg=ok",
+			time.Date(2022, time.October, 20, 0, 0, 0, 0, time.UTC),
+			"2022-10-20",
+		},
+		{
+			// offsets are in bytes.
+			"DD MMM YYYY",
+			"日付: 20 Oct 2022です",
+			time.Date(2022, time.October, 20, 0, 0, 0, 0, time.UTC),
+			"20 Oct 2022",
+		},
+		{
+			// the whole text.
+			"HH:mm",
+			"23:16",
+			time.Date(0, time.January, 1, 23, 16, 0, 0, time.UTC),
+			"23:16",
+		},
+		{
+			// the leftmost match wins.
+			"HH:mm",
+			"from 09:30 to 17:45",
+			time.Date(0, time.January, 1, 9, 30, 0, 0, time.UTC),
+			"09:30",
+		},
+	}
+
+	for _, testCase := range cases {
+		found, start, end, err := flextime.FindTime(testCase.flexLayout, testCase.text)
+		require.NoError(t, err, "text = %s", testCase.text)
+		assert.True(t, testCase.expected.Equal(found), "text = %s, found = %s", testCase.text, found)
+		assert.Equal(t, testCase.found, testCase.text[start:end], "text = %s", testCase.text)
+	}
+
+	l := flextime.MustCompile("YYYY-MM-DD")
+	for _, text := range []string{"", "no time here", "2022-13-45"} {
+		_, start, end, err := l.FindTime(text)
+		assert.ErrorIs(t, err, flextime.ErrTimeNotFound, "text = %s", text)
+		assert.Equal(t, -1, start)
+		assert.Equal(t, -1, end)
+	}
+
+	_, _, _, err := flextime.FindTime("YYYY-MM-DD[", "2022-10-20")
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, flextime.ErrTimeNotFound)
+}
+
+func TestFindTimeInsideDigits(t *testing.T) {
+	_, start, end, err := flextime.FindTime("YY-MM-DD", "2022-10-20")
+	require.NoError(t, err)
+	assert.Equal(t, 2, start)
+	assert.Equal(t, 10, end)
+}
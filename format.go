@@ -0,0 +1,31 @@
+package flextime
+
+import "time"
+
+// Format returns a textual representation of t formatted by flexLayout.
+// flexLayout must not contain optional parts.
+//
+// Tokens which have Go reference layout equivalents are formatted by time.Time.Format,
+// and computed tokens, e.g. Do, are formatted by flextime itself.
+func Format(t time.Time, flexLayout string) (string, error) {
+	b, err := AppendFormat(make([]byte, 0, len(flexLayout)+10), t, flexLayout)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// AppendFormat is like Format but appends the textual representation to b
+// and returns the extended buffer.
+func AppendFormat(b []byte, t time.Time, flexLayout string) ([]byte, error) {
+	chunks, err := splitChunks(flexLayout)
+	if err != nil {
+		return b, err
+	}
+	return appendChunks(b, t, chunks, nil), nil
+}
+
+// FormatIn is like Format but converts t into loc before formatting.
+func FormatIn(t time.Time, flexLayout string, loc *time.Location) (string, error) {
+	return Format(t.In(loc), flexLayout)
+}
@@ -0,0 +1,303 @@
+package flextime_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ngicks/flextime"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type formatTestCase struct {
+	flexLayout string
+	goLayout   string
+}
+
+func TestFormat(t *testing.T) {
+	target := time.Date(2022, time.October, 20, 23, 16, 22, 168123456, jst)
+
+	cases := []formatTestCase{
+		{
+			flexLayout: "YYYY-MM-dd HH:mm:ss",
+			goLayout:   "2006-01-02 15:04:05",
+		},
+		{
+			flexLayout: "YYYY-MM-DDTHH:mm:ss.SSSSSSSSSZ",
+			goLayout:   "2006-01-02T15:04:05.000000000Z07:00",
+		},
+		{
+			flexLayout: "ww, DD MMM YYYY hh:mm:ss A MST",
+			goLayout:   "Monday, 02 Jan 2006 03:04:05 PM MST",
+		},
+	}
+
+	for _, testCase := range cases {
+		formatted, err := flextime.Format(target, testCase.flexLayout)
+		require.NoError(t, err)
+		assert.Equal(t, target.Format(testCase.goLayout), formatted)
+
+		buf := make([]byte, 0, 64)
+		buf = append(buf, "prefix:"...)
+		appended, err := flextime.AppendFormat(buf, target, testCase.flexLayout)
+		require.NoError(t, err)
+		assert.Equal(t, "prefix:"+target.Format(testCase.goLayout), string(appended))
+	}
+}
+
+func TestFormatError(t *testing.T) {
+	var formatErr *flextime.FormatError
+	for _, invalid := range []string{"YYY-MM-DD", "YYYY-MM-DD SSSS", "Y"} {
+		_, err := flextime.Format(time.Now(), invalid)
+		assert.ErrorAs(t, err, &formatErr)
+
+		_, err = flextime.AppendFormat(nil, time.Now(), invalid)
+		assert.ErrorAs(t, err, &formatErr)
+	}
+}
+
+func TestFormatErrorCaret(t *testing.T) {
+	cases := []struct {
+		layout   string
+		expected string
+	}{
+		{
+			layout: "YYY-MM-DD",
+			expected: "index [2]: must be prefixed with one of [YYYYYY YYYY YY] but Y-MM-DD. maybe wrong len, like Y or YYY.\n" +
+				"YYY-MM-DD\n" +
+				"  ^",
+		},
+		{
+			layout: "YYYY-MM-DD SSSS",
+			expected: "index [14]: must be prefixed with one of [SSSSSSSSS SSSSSS SSS] but S. maybe wrong len, like Y or YYY.\n" +
+				"YYYY-MM-DD SSSS\n" +
+				"              ^",
+		},
+		{
+			// offset counts runes.
+			layout: "YYYY年MM月DD日 SSSS",
+			expected: "index [21]: must be prefixed with one of [SSSSSSSSS SSSSSS SSS] but S. maybe wrong len, like Y or YYY.\n" +
+				"YYYY年MM月DD日 SSSS\n" +
+				"               ^",
+		},
+	}
+
+	for _, testCase := range cases {
+		_, err := flextime.Format(time.Now(), testCase.layout)
+		require.Error(t, err)
+		assert.Equal(t, testCase.expected, err.Error())
+	}
+
+	// index points into the enumerated layout.
+	_, err := flextime.ToGoLayout("YYYY-MM[-DD]-Q")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "\nYYYY-MM-DD-Q\n           ^")
+}
+
+func TestFormatFractionalSecondSeparators(t *testing.T) {
+	target := time.Date(2022, time.October, 20, 23, 16, 22, 120000000, time.UTC)
+
+	for _, testCase := range []struct {
+		flexLayout string
+		formatted  string
+	}{
+		{"HH:mm:ss.SSS", "23:16:22.120"},
+		{"HH:mm:ss,SSS", "23:16:22,120"},
+		{"HH:mm:ss.000", "23:16:22.120"},
+		{"HH:mm:ss,000", "23:16:22,120"},
+		{"HH:mm:ss.999", "23:16:22.12"},
+		{"HH:mm:ss,999", "23:16:22,12"},
+	} {
+		formatted, err := flextime.Format(target, testCase.flexLayout)
+		require.NoError(t, err)
+		assert.Equal(t, testCase.formatted, formatted)
+
+		// either separator is accepted on parse, same as time.Parse.
+		for _, value := range []string{
+			testCase.formatted,
+			strings.NewReplacer(".", ",", ",", ".").Replace(testCase.formatted),
+		} {
+			parsed, err := flextime.Parse(testCase.flexLayout, value)
+			require.NoError(t, err, "layout = %s, value = %s", testCase.flexLayout, value)
+			assert.Equal(t, target.Nanosecond(), parsed.Nanosecond())
+		}
+	}
+}
+
+func TestFormatDoubledSingleQuote(t *testing.T) {
+	target := time.Date(2022, time.October, 20, 23, 16, 22, 0, time.UTC)
+
+	for _, testCase := range []struct {
+		layout   string
+		expected string
+	}{
+		{`h 'o''clock'`, "11 o'clock"},
+		{`'it''s'-HH:mm`, "it's-23:16"},
+		{`'''T'''`, "'T'"},
+	} {
+		formatted, err := flextime.Format(target, testCase.layout)
+		require.NoError(t, err)
+		assert.Equal(t, testCase.expected, formatted, "layout = %s", testCase.layout)
+
+		parsed, err := flextime.Parse(testCase.layout, formatted)
+		require.NoError(t, err, "layout = %s", testCase.layout)
+		reformatted, err := flextime.Format(parsed, testCase.layout)
+		require.NoError(t, err)
+		assert.Equal(t, formatted, reformatted)
+	}
+
+	layout, err := flextime.Compile(`'it''s'[-HH:mm]`)
+	require.NoError(t, err)
+	for _, value := range []string{"it's", "it's-23:16"} {
+		_, err := layout.Parse(value)
+		assert.NoError(t, err, "value = %s", value)
+	}
+}
+
+func TestFormatUnterminatedQuote(t *testing.T) {
+	for _, invalid := range []string{`YYYY 'abc`, `'`, `HH 'it''s`, `'abc\'`} {
+		var formatErr *flextime.FormatError
+		_, err := flextime.Format(time.Now(), invalid)
+		require.ErrorAs(t, err, &formatErr, "layout = %s", invalid)
+		assert.Contains(t, err.Error(), "unterminated quoted literal")
+
+		_, err = flextime.ReplaceTimeToken(invalid)
+		assert.ErrorAs(t, err, &formatErr, "layout = %s", invalid)
+	}
+
+	_, err := flextime.Format(time.Now(), `YYYY 'abc`)
+	assert.Equal(
+		t,
+		"index [5]: must be closed with ' but 'abc. unterminated quoted literal.\nYYYY 'abc\n     ^",
+		err.Error(),
+	)
+}
+
+func TestFormatBackslashEscape(t *testing.T) {
+	target := time.Date(2022, time.October, 20, 23, 16, 22, 0, time.UTC)
+
+	formatted, err := flextime.Format(target, `\Y\é YYYY`)
+	require.NoError(t, err)
+	assert.Equal(t, "Yé 2022", formatted)
+
+	// a backslash escapes a backslash and a quote, also within quotes.
+	for _, testCase := range []struct {
+		layout   string
+		expected string
+	}{
+		{`YYYY\\MM\'DD`, `2022\10'20`},
+		{`'a\\b\'c'`, `a\b'c`},
+		{`'a\''''`, `a''`},
+		{`'\\'YYYY`, `\2022`},
+		// other backslashes within quotes are literal.
+		{`'C:\dir'`, `C:\dir`},
+	} {
+		formatted, err := flextime.Format(target, testCase.layout)
+		require.NoError(t, err, "layout = %s", testCase.layout)
+		assert.Equal(t, testCase.expected, formatted, "layout = %s", testCase.layout)
+
+		parsed, err := flextime.Parse(testCase.layout, formatted)
+		require.NoError(t, err, "layout = %s", testCase.layout)
+		reformatted, err := flextime.Format(parsed, testCase.layout)
+		require.NoError(t, err)
+		assert.Equal(t, formatted, reformatted)
+	}
+
+	// escapes are kept while expanding optional parts.
+	previewed, err := flextime.PreviewExpansion(`'a\\b\'c'[\\]YYYY`)
+	require.NoError(t, err)
+	assert.Equal(t, []string{`'a\\b\'c'\\YYYY`, `'a\\b\'c'YYYY`}, previewed)
+
+	var formatErr *flextime.FormatError
+	_, err = flextime.Format(target, `YYYY\`)
+	require.ErrorAs(t, err, &formatErr)
+	assert.Contains(t, err.Error(), "trailing backslash")
+}
+
+func TestZoneOffsetTokens(t *testing.T) {
+	cases := []struct {
+		flexLayout string
+		jst        string
+		utc        string
+	}{
+		{"Z", "+09:00", "Z"},
+		{"Z07:00", "+09:00", "Z"},
+		{"ZZ", "+0900", "Z"},
+		{"Z0700", "+0900", "Z"},
+		{"Z07", "+09", "Z"},
+		{"Z070000", "+090000", "Z"},
+		{"Z07:00:00", "+09:00:00", "Z"},
+		{"-07:00", "+09:00", "+00:00"},
+		{"-0700", "+0900", "+0000"},
+		{"-07", "+09", "+00"},
+		{"-070000", "+090000", "+000000"},
+		{"-07:00:00", "+09:00:00", "+00:00:00"},
+	}
+
+	for _, testCase := range cases {
+		layout := "YYYY-MM-DDTHH:mm:ss" + testCase.flexLayout
+		tokens, err := flextime.Tokenize(layout)
+		require.NoError(t, err)
+		assert.Equal(t, testCase.flexLayout, tokens[len(tokens)-1].Value, "layout = %s", layout)
+
+		for _, target := range []time.Time{
+			time.Date(2022, time.October, 20, 23, 16, 22, 0, jst),
+			time.Date(2022, time.October, 20, 23, 16, 22, 0, time.UTC),
+		} {
+			expected := testCase.jst
+			if target.Location() == time.UTC {
+				expected = testCase.utc
+			}
+			formatted, err := flextime.Format(target, layout)
+			require.NoError(t, err)
+			assert.Equal(t, "2022-10-20T23:16:22"+expected, formatted, "layout = %s", layout)
+
+			parsed, err := flextime.Parse(layout, formatted)
+			require.NoError(t, err, "layout = %s", layout)
+			assert.True(t, target.Equal(parsed), "layout = %s, parsed = %s", layout, parsed)
+		}
+	}
+}
+
+func TestSpacePaddedDayTokens(t *testing.T) {
+	for _, testCase := range []struct {
+		flexLayout string
+		goLayout   string
+		target     time.Time
+		formatted  string
+	}{
+		{"MMM _D", "Jan _2", time.Date(2022, time.October, 4, 0, 0, 0, 0, time.UTC), "Oct  4"},
+		{"MMM _d", "Jan _2", time.Date(2022, time.October, 20, 0, 0, 0, 0, time.UTC), "Oct 20"},
+		{"YYYY __D", "2006 __2", time.Date(2022, time.January, 4, 0, 0, 0, 0, time.UTC), "2022   4"},
+		{"YYYY __d", "2006 __2", time.Date(2022, time.February, 10, 0, 0, 0, 0, time.UTC), "2022  41"},
+		{"YYYY __D", "2006 __2", time.Date(2022, time.December, 31, 0, 0, 0, 0, time.UTC), "2022 365"},
+		// _ followed by DD, DDD or Do is a literal.
+		{"YYYY_MM_DD", "2006_01_02", time.Date(2022, time.October, 4, 0, 0, 0, 0, time.UTC), "2022_10_04"},
+		{"YYYY_DDD", "2006_002", time.Date(2022, time.January, 4, 0, 0, 0, 0, time.UTC), "2022_004"},
+		{"YYYY__DD", "2006__02", time.Date(2022, time.January, 4, 0, 0, 0, 0, time.UTC), "2022__04"},
+	} {
+		goLayout, err := flextime.ReplaceTimeToken(testCase.flexLayout)
+		require.NoError(t, err)
+		assert.Equal(t, testCase.goLayout, goLayout)
+
+		formatted, err := flextime.Format(testCase.target, testCase.flexLayout)
+		require.NoError(t, err)
+		assert.Equal(t, testCase.formatted, formatted, "layout = %s", testCase.flexLayout)
+		assert.Equal(t, testCase.target.Format(testCase.goLayout), formatted)
+
+		parsed, err := flextime.Parse(testCase.flexLayout, formatted)
+		require.NoError(t, err, "layout = %s", testCase.flexLayout)
+		if !strings.HasPrefix(testCase.flexLayout, "YYYY") {
+			parsed = parsed.AddDate(testCase.target.Year(), 0, 0)
+		}
+		assert.True(t, testCase.target.Equal(parsed), "layout = %s, parsed = %s", testCase.flexLayout, parsed)
+	}
+
+	// same as time.Parse, unpadded and zero padded days are also accepted.
+	for _, value := range []string{"Oct  4", "Oct 4", "Oct 04"} {
+		parsed, err := flextime.Parse("MMM _D", value)
+		require.NoError(t, err, "value = %s", value)
+		assert.Equal(t, 4, parsed.Day())
+	}
+}
@@ -0,0 +1,157 @@
+package flextime
+
+import (
+	"strings"
+	"time"
+
+	optionalstring "github.com/ngicks/flextime/optional_string"
+)
+
+// TrimZeroOptionals makes Format omit optional parts whose fields are all zero,
+// e.g. HH:mm[:ss] formats 10:30:00 as 10:30 but 10:30:15 as 10:30:15.
+//
+// An optional part is omitted if it contains at least one token,
+// including tokens of optional parts nested in it,
+// and every token is hour, minute, second, fractional second, sod, msod or mod whose value in t is zero.
+// Thus an optional part containing any other token, e.g. a date or a time zone, or no token at all, is always present.
+// Only trailing ones are omitted: an optional part is omitted only if optional parts after it are also omitted,
+// e.g. HH:mm[:ss][.SSS] formats 10:00:00.005 as 10:00:00.005 rather than 10:00.005.
+// Fractional seconds are zero if they are zero in the digits of the token, e.g. .SSS of 100ns.
+// An optional part which is present decides its nested optional parts by the same rule,
+// e.g. YYYY-MM-DD[THH:mm[:ss]] formats midnight as the date only, and 10:30:00 as YYYY-MM-DDTHH:mm.
+// The first branch is taken for each alternation as usual.
+// It only affects Format and AppendFormat, not Parse.
+func TrimZeroOptionals() Option {
+	return func(l *Layout) {
+		l.trimZeroOptionals = true
+	}
+}
+
+// trimNode is a node of the source layout for TrimZeroOptionals, see optionalstring.TreeNode.
+// Alternations are replaced by their first branch.
+type trimNode struct {
+	optional bool
+	chunks   []layoutChunk
+	left     *trimNode
+	right    *trimNode
+}
+
+func newTrimTree(tables *tokenTables, flexLayout string) (*trimNode, error) {
+	tree, err := optionalstring.ParseTree(flexLayout)
+	if err != nil {
+		return nil, err
+	}
+	return newTrimNode(tables, tree)
+}
+
+func newTrimNode(tables *tokenTables, n *optionalstring.TreeNode) (*trimNode, error) {
+	if n == nil {
+		return nil, nil
+	}
+	if n.Kind == optionalstring.NodeAlternation {
+		if len(n.Branches) == 0 {
+			return nil, nil
+		}
+		return newTrimNode(tables, n.Branches[0])
+	}
+	chunks, err := tables.splitChunksRaw(n.Value)
+	if err != nil {
+		return nil, err
+	}
+	left, err := newTrimNode(tables, n.Left)
+	if err != nil {
+		return nil, err
+	}
+	right, err := newTrimNode(tables, n.Right)
+	if err != nil {
+		return nil, err
+	}
+	return &trimNode{
+		optional: n.Kind == optionalstring.NodeOptional,
+		chunks:   chunks,
+		left:     left,
+		right:    right,
+	}, nil
+}
+
+// appendChunks appends chunks of n and nodes following it to chunks,
+// skipping trailing optional parts omitted for t.
+func (n *trimNode) appendChunks(chunks []layoutChunk, t time.Time) []layoutChunk {
+	var level []*trimNode
+	for cur := n; cur != nil; cur = cur.right {
+		level = append(level, cur)
+	}
+	omit := make([]bool, len(level))
+	trailing := true
+	for i := len(level) - 1; i >= 0; i-- {
+		left := level[i].left
+		if left == nil || !left.optional {
+			continue
+		}
+		omit[i] = trailing && left.omitted(t)
+		trailing = omit[i]
+	}
+	for i, cur := range level {
+		chunks = append(chunks, cur.chunks...)
+		if cur.left != nil && !omit[i] {
+			chunks = cur.left.appendChunks(chunks, t)
+		}
+	}
+	return chunks
+}
+
+// omitted reports whether n has tokens and all of them are zero in t.
+func (n *trimNode) omitted(t time.Time) bool {
+	tokens, zero := n.zeroTokens(t)
+	return tokens > 0 && zero
+}
+
+// zeroTokens returns the number of tokens in n and nodes following or nested in it,
+// and whether all of them are zero in t.
+func (n *trimNode) zeroTokens(t time.Time) (tokens int, zero bool) {
+	zero = true
+	for cur := n; cur != nil; cur = cur.right {
+		for _, c := range cur.chunks {
+			if !c.isToken() {
+				continue
+			}
+			tokens++
+			if !isZeroToken(c.token, t) {
+				return tokens, false
+			}
+		}
+		if cur.left != nil {
+			leftTokens, leftZero := cur.left.zeroTokens(t)
+			tokens += leftTokens
+			if !leftZero {
+				return tokens, false
+			}
+		}
+	}
+	return tokens, zero
+}
+
+// isZeroToken reports whether token is a time of day token whose value in t is zero.
+func isZeroToken(token timeFormatToken, t time.Time) bool {
+	switch fieldKindOf(token) {
+	case "hour":
+		return t.Hour() == 0
+	case "minute":
+		return t.Minute() == 0
+	case "second":
+		return t.Second() == 0
+	case "fractional second":
+		digits := len(strings.TrimLeft(string(token), ".,"))
+		nsec := t.Nanosecond()
+		for ; digits < 9; digits++ {
+			nsec /= 10
+		}
+		return nsec == 0
+	case "time of day":
+		if t.Hour() != 0 || t.Minute() != 0 {
+			return false
+		}
+		return token == "mod" || (t.Second() == 0 && (token != "msod" || t.Nanosecond() < int(time.Millisecond)))
+	}
+	return false
+}
@@ -0,0 +1,70 @@
+package flextime_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ngicks/flextime"
+)
+
+var roundTripLayouts = []string{
+	"YYYY-MM-DDTHH:mm:ss.SSSSSSSSSZ",
+	"YYYY-MM-DD[THH[:mm[:ss.SSS]]][Z]",
+	"ww, DD MMM YYYY HH:mm:ss ZZ",
+	"dd MMMM D YY hh:mm:ss.999999 A -07:00",
+	"yyyy-MM-dd h:m:s a Z070000",
+	"YYYY DDD HH:mm:ss,SSS Z",
+	"GGGG-'W'WW-e HH:mm:ss",
+	"Do MMMM YYYY H:mm:ss",
+	"YYYY-MM-DD sod",
+	"YYYY-MM-DD msod Z",
+	"YYYY-MM-DD HH:mm:ssSSSSSS zzzz",
+	"Q YYYY-MM-DD E c",
+	"YYYY G MM DD HH:mm",
+	"X",
+	"x",
+}
+
+var (
+	minRoundTripUnix = time.Date(-9998, time.January, 1, 0, 0, 0, 0, time.UTC).Unix()
+	maxRoundTripUnix = time.Date(9999, time.December, 31, 0, 0, 0, 0, time.UTC).Unix()
+)
+
+func FuzzFormatAndParse(f *testing.F) {
+	f.Add(int64(1666275382), int32(168123456), int16(9*60), uint8(0))
+	f.Add(int64(0), int32(0), int16(0), uint8(1))
+	f.Add(int64(-62135596800), int32(999999999), int16(-14*60), uint8(2))
+	f.Add(int64(-64000000000), int32(1), int16(345), uint8(12))
+
+	compiled := make([]*flextime.Layout, len(roundTripLayouts))
+	for i, flexLayout := range roundTripLayouts {
+		layout, err := flextime.Compile(flexLayout)
+		if err != nil {
+			f.Fatalf("layout = %s, err = %v", flexLayout, err)
+		}
+		compiled[i] = layout
+	}
+
+	f.Fuzz(func(t *testing.T, sec int64, nsec int32, offsetMinutes int16, layoutIdx uint8) {
+		if sec < minRoundTripUnix || sec > maxRoundTripUnix ||
+			nsec < 0 || nsec > 999999999 ||
+			offsetMinutes < -14*60 || offsetMinutes > 14*60 {
+			return
+		}
+		layout := compiled[int(layoutIdx)%len(compiled)]
+		flexLayout := roundTripLayouts[int(layoutIdx)%len(compiled)]
+		target := time.Unix(sec, int64(nsec)).In(time.FixedZone("", int(offsetMinutes)*60))
+
+		formatted := layout.Format(target)
+		parsed, err := layout.Parse(formatted)
+		if err != nil {
+			t.Fatalf("layout = %s, target = %s, formatted = %s, err = %v", flexLayout, target, formatted, err)
+		}
+		if reformatted := layout.Format(parsed); reformatted != formatted {
+			t.Fatalf(
+				"not round-tripped: layout = %s, target = %s, formatted = %s, reformatted = %s",
+				flexLayout, target, formatted, reformatted,
+			)
+		}
+	})
+}
@@ -1,6 +1,6 @@
 module github.com/ngicks/flextime
 
-go 1.19
+go 1.23
 
 require (
 	github.com/google/go-cmp v0.5.9
@@ -8,11 +8,11 @@ require (
 	github.com/pkg/errors v0.9.1
 	github.com/prataprc/goparsec v0.0.0-20211219142520-daac0e635e7e
 	github.com/stretchr/testify v1.8.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
 	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
 	golang.org/x/exp v0.0.0-20220613132600-b0d781184e0d // indirect
-	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
@@ -0,0 +1,373 @@
+package flextime
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Std chunk kinds of Go reference layout, returned from NextStdChunk.
+// These are derived from the standard time package, and their values are stable.
+// 0 means no chunk.
+const (
+	_                        = iota
+	StdLongMonth             // "January", full English month name
+	StdMonth                 // "Jan", abbreviated English month name
+	StdNumMonth              // "1", month without padding
+	StdZeroMonth             // "01", zero padded month
+	StdLongWeekDay           // "Monday", full English weekday name
+	StdWeekDay               // "Mon", abbreviated English weekday name
+	StdDay                   // "2", day of month without padding
+	StdUnderDay              // "_2", space padded day of month
+	StdZeroDay               // "02", zero padded day of month
+	StdUnderYearDay          // "__2", space padded day of year
+	StdZeroYearDay           // "002", zero padded day of year
+	StdHour                  // "15", hour of 24-hour clock
+	StdHour12                // "3", hour of 12-hour clock without padding
+	StdZeroHour12            // "03", zero padded hour of 12-hour clock
+	StdMinute                // "4", minute without padding
+	StdZeroMinute            // "04", zero padded minute
+	StdSecond                // "5", second without padding
+	StdZeroSecond            // "05", zero padded second
+	StdLongYear              // "2006", 4 digit year
+	StdYear                  // "06", 2 digit year
+	StdPM                    // "PM", upper case AM/PM
+	StdLowerPM               // "pm", lower case am/pm
+	StdTZ                    // "MST", time zone abbreviation
+	StdISO8601TZ             // "Z0700", numeric offset, Z for UTC
+	StdISO8601SecondsTZ      // "Z070000", numeric offset with seconds, Z for UTC
+	StdISO8601ShortTZ        // "Z07", numeric offset hours, Z for UTC
+	StdISO8601ColonTZ        // "Z07:00", numeric offset with colon, Z for UTC
+	StdISO8601ColonSecondsTZ // "Z07:00:00", numeric offset with colon and seconds, Z for UTC
+	StdNumTZ                 // "-0700", always numeric offset
+	StdNumSecondsTZ          // "-070000", always numeric offset with seconds
+	StdNumShortTZ            // "-07", always numeric offset hours
+	StdNumColonTZ            // "-07:00", always numeric offset with colon
+	StdNumColonSecondsTZ     // "-07:00:00", always numeric offset with colon and seconds
+	StdFracSecond0           // ".0", ".00", ... or ",0", ",00", ..., trailing zeros included
+	StdFracSecond9           // ".9", ".99", ... or ",9", ",99", ..., trailing zeros omitted
+)
+
+// ChunkNames maps each Std chunk kind to its Go reference layout string.
+// Fractional seconds are represented by their shortest form, e.g. ".0".
+var ChunkNames = map[int]string{
+	StdLongMonth:             "January",
+	StdMonth:                 "Jan",
+	StdNumMonth:              "1",
+	StdZeroMonth:             "01",
+	StdLongWeekDay:           "Monday",
+	StdWeekDay:               "Mon",
+	StdDay:                   "2",
+	StdUnderDay:              "_2",
+	StdZeroDay:               "02",
+	StdUnderYearDay:          "__2",
+	StdZeroYearDay:           "002",
+	StdHour:                  "15",
+	StdHour12:                "3",
+	StdZeroHour12:            "03",
+	StdMinute:                "4",
+	StdZeroMinute:            "04",
+	StdSecond:                "5",
+	StdZeroSecond:            "05",
+	StdLongYear:              "2006",
+	StdYear:                  "06",
+	StdPM:                    "PM",
+	StdLowerPM:               "pm",
+	StdTZ:                    "MST",
+	StdISO8601TZ:             "Z0700",
+	StdISO8601SecondsTZ:      "Z070000",
+	StdISO8601ShortTZ:        "Z07",
+	StdISO8601ColonTZ:        "Z07:00",
+	StdISO8601ColonSecondsTZ: "Z07:00:00",
+	StdNumTZ:                 "-0700",
+	StdNumSecondsTZ:          "-070000",
+	StdNumShortTZ:            "-07",
+	StdNumColonTZ:            "-07:00",
+	StdNumColonSecondsTZ:     "-07:00:00",
+	StdFracSecond0:           ".0",
+	StdFracSecond9:           ".9",
+}
+
+// std0x records the std values for "01", "02", ..., "06".
+var std0x = [...]int{StdZeroMonth, StdZeroDay, StdZeroHour12, StdZeroMinute, StdZeroSecond, StdYear}
+
+// startsWithLowerCase reports whether the string has a lower-case letter at the beginning.
+// Its purpose is to prevent matching strings like "Month" when looking for "Mon".
+func startsWithLowerCase(str string) bool {
+	if len(str) == 0 {
+		return false
+	}
+	c := str[0]
+	return 'a' <= c && c <= 'z'
+}
+
+func isDigit(s string, i int) bool {
+	if len(s) <= i {
+		return false
+	}
+	c := s[i]
+	return '0' <= c && c <= '9'
+}
+
+// NextStdChunk finds the first occurrence of a std string in
+// layout and returns the text before, the kind of the std string, one of Std constants, and the text after.
+// The std string itself is layout[len(prefix):len(layout)-len(suffix)].
+// std is 0, prefix is layout and suffix is empty if layout has no std string.
+//
+// This is ported from the standard time package (Copyright The Go Authors, BSD-style license)
+// so that it behaves exactly as time.Format and time.Parse split layouts.
+// Unlike the standard one, std does not encode the length of fractional seconds.
+func NextStdChunk(layout string) (prefix string, std int, suffix string) {
+	for i := 0; i < len(layout); i++ {
+		switch c := int(layout[i]); c {
+		case 'J': // January, Jan
+			if len(layout) >= i+3 && layout[i:i+3] == "Jan" {
+				if len(layout) >= i+7 && layout[i:i+7] == "January" {
+					return layout[0:i], StdLongMonth, layout[i+7:]
+				}
+				if !startsWithLowerCase(layout[i+3:]) {
+					return layout[0:i], StdMonth, layout[i+3:]
+				}
+			}
+
+		case 'M': // Monday, Mon, MST
+			if len(layout) >= i+3 {
+				if layout[i:i+3] == "Mon" {
+					if len(layout) >= i+6 && layout[i:i+6] == "Monday" {
+						return layout[0:i], StdLongWeekDay, layout[i+6:]
+					}
+					if !startsWithLowerCase(layout[i+3:]) {
+						return layout[0:i], StdWeekDay, layout[i+3:]
+					}
+				}
+				if layout[i:i+3] == "MST" {
+					return layout[0:i], StdTZ, layout[i+3:]
+				}
+			}
+
+		case '0': // 01, 02, 03, 04, 05, 06, 002
+			if len(layout) >= i+2 && '1' <= layout[i+1] && layout[i+1] <= '6' {
+				return layout[0:i], std0x[layout[i+1]-'1'], layout[i+2:]
+			}
+			if len(layout) >= i+3 && layout[i+1] == '0' && layout[i+2] == '2' {
+				return layout[0:i], StdZeroYearDay, layout[i+3:]
+			}
+
+		case '1': // 15, 1
+			if len(layout) >= i+2 && layout[i+1] == '5' {
+				return layout[0:i], StdHour, layout[i+2:]
+			}
+			return layout[0:i], StdNumMonth, layout[i+1:]
+
+		case '2': // 2006, 2
+			if len(layout) >= i+4 && layout[i:i+4] == "2006" {
+				return layout[0:i], StdLongYear, layout[i+4:]
+			}
+			return layout[0:i], StdDay, layout[i+1:]
+
+		case '_': // _2, _2006, __2
+			if len(layout) >= i+2 && layout[i+1] == '2' {
+				// _2006 is really a literal _, followed by StdLongYear
+				if len(layout) >= i+5 && layout[i+1:i+5] == "2006" {
+					return layout[0 : i+1], StdLongYear, layout[i+5:]
+				}
+				return layout[0:i], StdUnderDay, layout[i+2:]
+			}
+			if len(layout) >= i+3 && layout[i+1] == '_' && layout[i+2] == '2' {
+				return layout[0:i], StdUnderYearDay, layout[i+3:]
+			}
+
+		case '3':
+			return layout[0:i], StdHour12, layout[i+1:]
+
+		case '4':
+			return layout[0:i], StdMinute, layout[i+1:]
+
+		case '5':
+			return layout[0:i], StdSecond, layout[i+1:]
+
+		case 'P': // PM
+			if len(layout) >= i+2 && layout[i+1] == 'M' {
+				return layout[0:i], StdPM, layout[i+2:]
+			}
+
+		case 'p': // pm
+			if len(layout) >= i+2 && layout[i+1] == 'm' {
+				return layout[0:i], StdLowerPM, layout[i+2:]
+			}
+
+		case '-': // -070000, -07:00:00, -0700, -07:00, -07
+			if len(layout) >= i+7 && layout[i:i+7] == "-070000" {
+				return layout[0:i], StdNumSecondsTZ, layout[i+7:]
+			}
+			if len(layout) >= i+9 && layout[i:i+9] == "-07:00:00" {
+				return layout[0:i], StdNumColonSecondsTZ, layout[i+9:]
+			}
+			if len(layout) >= i+5 && layout[i:i+5] == "-0700" {
+				return layout[0:i], StdNumTZ, layout[i+5:]
+			}
+			if len(layout) >= i+6 && layout[i:i+6] == "-07:00" {
+				return layout[0:i], StdNumColonTZ, layout[i+6:]
+			}
+			if len(layout) >= i+3 && layout[i:i+3] == "-07" {
+				return layout[0:i], StdNumShortTZ, layout[i+3:]
+			}
+
+		case 'Z': // Z070000, Z07:00:00, Z0700, Z07:00,
+			if len(layout) >= i+7 && layout[i:i+7] == "Z070000" {
+				return layout[0:i], StdISO8601SecondsTZ, layout[i+7:]
+			}
+			if len(layout) >= i+9 && layout[i:i+9] == "Z07:00:00" {
+				return layout[0:i], StdISO8601ColonSecondsTZ, layout[i+9:]
+			}
+			if len(layout) >= i+5 && layout[i:i+5] == "Z0700" {
+				return layout[0:i], StdISO8601TZ, layout[i+5:]
+			}
+			if len(layout) >= i+6 && layout[i:i+6] == "Z07:00" {
+				return layout[0:i], StdISO8601ColonTZ, layout[i+6:]
+			}
+			if len(layout) >= i+3 && layout[i:i+3] == "Z07" {
+				return layout[0:i], StdISO8601ShortTZ, layout[i+3:]
+			}
+
+		case '.', ',': // ,000, or .000, or ,999, or .999 - repeated digits for fractional seconds.
+			if i+1 < len(layout) && (layout[i+1] == '0' || layout[i+1] == '9') {
+				ch := layout[i+1]
+				j := i + 1
+				for j < len(layout) && layout[j] == ch {
+					j++
+				}
+				// String of digits must end here - only fractional second is all digits.
+				if !isDigit(layout, j) {
+					code := StdFracSecond0
+					if layout[i+1] == '9' {
+						code = StdFracSecond9
+					}
+					return layout[0:i], code, layout[j:]
+				}
+			}
+		}
+	}
+	return layout, 0, ""
+}
+
+var stdToFlexToken = map[int]timeFormatToken{
+	StdLongMonth:             "MMMM",
+	StdMonth:                 "MMM",
+	StdNumMonth:              "M",
+	StdZeroMonth:             "MM",
+	StdLongWeekDay:           "ww",
+	StdWeekDay:               "w",
+	StdDay:                   "D",
+	StdUnderDay:              "_D",
+	StdZeroDay:               "DD",
+	StdUnderYearDay:          "__D",
+	StdZeroYearDay:           "DDD",
+	StdHour:                  "HH",
+	StdHour12:                "h",
+	StdZeroHour12:            "hh",
+	StdMinute:                "m",
+	StdZeroMinute:            "mm",
+	StdSecond:                "s",
+	StdZeroSecond:            "ss",
+	StdLongYear:              "YYYY",
+	StdYear:                  "YY",
+	StdPM:                    "A",
+	StdLowerPM:               "a",
+	StdTZ:                    "MST",
+	StdISO8601TZ:             "ZZ",
+	StdISO8601SecondsTZ:      "Z070000",
+	StdISO8601ShortTZ:        "Z07",
+	StdISO8601ColonTZ:        "Z",
+	StdISO8601ColonSecondsTZ: "Z07:00:00",
+	StdNumTZ:                 "-0700",
+	StdNumSecondsTZ:          "-070000",
+	StdNumShortTZ:            "-07",
+	StdNumColonTZ:            "-07:00",
+	StdNumColonSecondsTZ:     "-07:00:00",
+}
+
+// UnsupportedChunkError is returned from ToFlexLayout
+// when Go reference layout contains a chunk which has no flextime equivalent.
+// Every chunk NextStdChunk currently returns has an equivalent.
+type UnsupportedChunkError struct {
+	Layout string
+	Chunk  string
+}
+
+func (e *UnsupportedChunkError) Error() string {
+	return fmt.Sprintf(
+		"unsupported chunk: %q in layout %q has no flextime equivalent",
+		e.Chunk,
+		e.Layout,
+	)
+}
+
+// ToFlexLayout converts Go reference layout into flextime layout.
+// Literal parts of goLayout are quoted if needed, so that they are not interpreted as tokens.
+// It returns *UnsupportedChunkError if goLayout has a chunk that flextime can not express.
+func ToFlexLayout(goLayout string) (string, error) {
+	var output string
+	rest := goLayout
+	for len(rest) > 0 {
+		prefix, std, suffix := NextStdChunk(rest)
+		output += escapeLiteral(prefix)
+		if std == 0 {
+			break
+		}
+		chunk := rest[len(prefix) : len(rest)-len(suffix)]
+		switch std {
+		case StdFracSecond0, StdFracSecond9:
+			if std == StdFracSecond0 {
+				output += chunk[:1] + strings.Repeat("S", len(chunk)-1)
+			} else {
+				output += chunk
+			}
+		default:
+			token, ok := stdToFlexToken[std]
+			if !ok {
+				return "", &UnsupportedChunkError{Layout: goLayout, Chunk: chunk}
+			}
+			output += string(token)
+		}
+		rest = suffix
+	}
+	return output, nil
+}
+
+// escapeLiteral quotes characters of literal
+// that could be interpreted as flextime tokens or escapes.
+func escapeLiteral(literal string) string {
+	var output string
+	var quoted bool
+	for i := 0; i < len(literal); i++ {
+		c := literal[i]
+		switch {
+		case c == '\'' || c == '\\':
+			if quoted {
+				output += "'"
+				quoted = false
+			}
+			output += `\` + literal[i:i+1]
+		case ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') || strings.IndexByte("[]()|", c) >= 0 ||
+			((c == '.' || c == ',') && i+1 < len(literal) && strings.IndexByte("S09", literal[i+1]) >= 0) ||
+			(c == '-' && i+1 < len(literal) && literal[i+1] == '0') ||
+			// '_' at the end could be followed by a day token, e.g. _ then DD.
+			(c == '_' && i+1 == len(literal)):
+			if !quoted {
+				output += "'"
+				quoted = true
+			}
+			output += literal[i : i+1]
+		default:
+			if quoted {
+				output += "'"
+				quoted = false
+			}
+			output += literal[i : i+1]
+		}
+	}
+	if quoted {
+		output += "'"
+	}
+	return output
+}
@@ -0,0 +1,109 @@
+package flextime_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ngicks/flextime"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestToFlexLayout(t *testing.T) {
+	cases := []replaceTimeTokenTestCase{
+		{
+			input:    time.RFC3339,
+			expected: `YYYY-MM-DD'T'HH:mm:ssZ`,
+		},
+		{
+			input:    time.RFC3339Nano,
+			expected: `YYYY-MM-DD'T'HH:mm:ss.999999999Z`,
+		},
+		{
+			input:    time.RubyDate,
+			expected: `w MMM DD HH:mm:ss -0700 YYYY`,
+		},
+		{
+			input:    time.RFC1123,
+			expected: `w, DD MMM YYYY HH:mm:ss MST`,
+		},
+		{
+			input:    time.Kitchen,
+			expected: `h:mmA`,
+		},
+		{
+			input:    "[2006]",
+			expected: `'['YYYY']'`,
+		},
+		{
+			input:    "2006-01-02 15:04:05.000 o'clock",
+			expected: `YYYY-MM-DD HH:mm:ss.SSS 'o'\''clock'`,
+		},
+		{
+			input:    "15:04:05,000",
+			expected: `HH:mm:ss,SSS`,
+		},
+		{
+			input:    "15:04:05,999",
+			expected: `HH:mm:ss,999`,
+		},
+	}
+
+	for _, testCase := range cases {
+		flexLayout, err := flextime.ToFlexLayout(testCase.input)
+		require.NoError(t, err)
+		assert.Equal(t, testCase.expected, flexLayout)
+
+		goLayout, err := flextime.ReplaceTimeToken(flexLayout)
+		require.NoError(t, err)
+		assert.Equal(t, testCase.input, goLayout)
+	}
+}
+
+func TestToFlexLayoutSpacePadded(t *testing.T) {
+	for _, testCase := range []replaceTimeTokenTestCase{
+		{input: time.UnixDate, expected: `w MMM _D HH:mm:ss MST YYYY`},
+		{input: time.ANSIC, expected: `w MMM _D HH:mm:ss YYYY`},
+		{input: time.Stamp, expected: `MMM _D HH:mm:ss`},
+		{input: "2006-__2", expected: `YYYY-__D`},
+		// a literal _ followed by a day token is quoted.
+		{input: "Jan_02", expected: `MMM'_'DD`},
+		{input: "2006_01", expected: `YYYY'_'MM`},
+	} {
+		flexLayout, err := flextime.ToFlexLayout(testCase.input)
+		require.NoError(t, err)
+		assert.Equal(t, testCase.expected, flexLayout)
+
+		goLayout, err := flextime.ReplaceTimeToken(flexLayout)
+		require.NoError(t, err)
+		assert.Equal(t, testCase.input, goLayout)
+	}
+}
+
+func TestNextStdChunk(t *testing.T) {
+	for std, name := range flextime.ChunkNames {
+		prefix, found, suffix := flextime.NextStdChunk("<" + name + ">")
+		assert.Equal(t, "<", prefix, "name = %s", name)
+		assert.Equal(t, std, found, "name = %s", name)
+		assert.Equal(t, ">", suffix, "name = %s", name)
+	}
+
+	var chunks []string
+	rest := time.RFC3339Nano
+	for {
+		prefix, std, suffix := flextime.NextStdChunk(rest)
+		if std == 0 {
+			assert.Equal(t, rest, prefix)
+			assert.Equal(t, "", suffix)
+			break
+		}
+		chunks = append(chunks, rest[len(prefix):len(rest)-len(suffix)])
+		rest = suffix
+	}
+	assert.Equal(t, []string{"2006", "01", "02", "15", "04", "05", ".999999999", "Z07:00"}, chunks)
+
+	// a lower case letter after Mon does not make a chunk.
+	prefix, std, _ := flextime.NextStdChunk("Month")
+	assert.Equal(t, "Month", prefix)
+	assert.Equal(t, 0, std)
+}
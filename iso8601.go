@@ -0,0 +1,72 @@
+package flextime
+
+import (
+	"strings"
+	"time"
+)
+
+// Optional times of ISO 8601, following the date, in the extended and the basic format.
+const (
+	iso8601ExtendedTime = `['T'HH[:mm[:ss[.999999999]]]][(Z|Z07)]`
+	iso8601BasicTime    = `['T'HH[mm[ss[.999999999]]]][(ZZ|Z07)]`
+)
+
+// Layouts of ISO 8601 date representations, see ParseISO8601.
+var (
+	iso8601Calendar      = MustCompile(`YYYY-MM[-DD]` + iso8601ExtendedTime)
+	iso8601Ordinal       = MustCompile(`YYYY-DDD` + iso8601ExtendedTime)
+	iso8601Week          = MustCompile(`GGGG-'W'WW[-e]` + iso8601ExtendedTime)
+	iso8601CalendarBasic = MustCompile(`YYYYMMDD` + iso8601BasicTime)
+	iso8601OrdinalBasic  = MustCompile(`YYYYDDD` + iso8601BasicTime)
+	iso8601WeekBasic     = MustCompile(`GGGG'W'WW[e]` + iso8601BasicTime)
+)
+
+// ParseISO8601 parses value in any of ISO 8601 date representations,
+// optionally followed by a time of day and an offset, e.g. T14:30:00+09:00.
+// Offsets are Z, ±hh:mm or ±hh, and ±hhmm instead of ±hh:mm in the basic format.
+//
+//   - calendar dates, e.g. 2023-05-17, or 2023-05 for the first day of the month.
+//   - ordinal dates, e.g. 2023-137.
+//   - week dates, e.g. 2023-W20-3, or 2023-W20 for Monday of the week.
+//
+// Each of them is also accepted in the basic format, without separators,
+// e.g. 20230517, 2023137 and 2023W203, where the time is also without separators, e.g. T143000+0900.
+// The extended and the basic format can not be mixed.
+//
+// The representation is chosen by the shape of the date, i.e. what follows the 4 digits of the year:
+// W is a week date, and otherwise 3 digits, 2023-137 or 2023137, are an ordinal date.
+// Times without an offset are in UTC.
+func ParseISO8601(value string) (time.Time, error) {
+	return iso8601LayoutOf(value).Parse(value)
+}
+
+// iso8601LayoutOf returns the layout of the ISO 8601 date representation value is in.
+// It looks at the digits after the year, not at the length of the date,
+// since a zone may follow the date without a time, e.g. 2023-137Z.
+func iso8601LayoutOf(value string) *Layout {
+	rest := value
+	if len(rest) >= len("2023") {
+		rest = rest[len("2023"):]
+	}
+	extended := strings.HasPrefix(rest, "-")
+	rest = strings.TrimPrefix(rest, "-")
+	if strings.HasPrefix(rest, "W") {
+		if extended {
+			return iso8601Week
+		}
+		return iso8601WeekBasic
+	}
+	digits := 0
+	for isDigit(rest, digits) {
+		digits++
+	}
+	switch {
+	case digits == 3 && extended:
+		return iso8601Ordinal
+	case digits == 3:
+		return iso8601OrdinalBasic
+	case extended:
+		return iso8601Calendar
+	}
+	return iso8601CalendarBasic
+}
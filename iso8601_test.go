@@ -0,0 +1,75 @@
+package flextime_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ngicks/flextime"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseISO8601(t *testing.T) {
+	date := time.Date(2023, time.May, 17, 0, 0, 0, 0, time.UTC)
+	dateTime := time.Date(2023, time.May, 17, 14, 30, 15, 0, time.UTC)
+	jst := time.FixedZone("", 9*3600)
+
+	type testCase struct {
+		value    string
+		expected time.Time
+	}
+	for _, tc := range []testCase{
+		// calendar dates
+		{"2023-05-17", date},
+		{"2023-05", time.Date(2023, time.May, 1, 0, 0, 0, 0, time.UTC)},
+		{"20230517", date},
+		// ordinal dates
+		{"2023-137", date},
+		{"2023137", date},
+		// week dates
+		{"2023-W20-3", date},
+		{"2023-W20", time.Date(2023, time.May, 15, 0, 0, 0, 0, time.UTC)},
+		{"2023W203", date},
+		// the week-numbering year differs from the calendar year.
+		{"2020-W53-5", time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC)},
+		// with times
+		{"2023-05-17T14:30:15Z", dateTime},
+		{"2023-137T14:30:15Z", dateTime},
+		{"2023-W20-3T14:30:15Z", dateTime},
+		{"2023-W20-3T14:30", dateTime.Truncate(time.Minute)},
+		{"2023-05-17T23:30:15.5+09:00", time.Date(2023, time.May, 17, 23, 30, 15, 5e8, jst)},
+		{"20230517T143015Z", dateTime},
+		{"2023137T143015Z", dateTime},
+		{"2023W203T233015+0900", time.Date(2023, time.May, 17, 23, 30, 15, 0, jst)},
+		// a zone without a time.
+		{"2023-137Z", date},
+		{"2023137Z", date},
+		{"2023-05-17+09:00", time.Date(2023, time.May, 17, 0, 0, 0, 0, jst)},
+		{"20230517+0900", time.Date(2023, time.May, 17, 0, 0, 0, 0, jst)},
+		{"2023-W20-3Z", date},
+		// offsets of hours only.
+		{"2023-05-17T23:30:15+09", time.Date(2023, time.May, 17, 23, 30, 15, 0, jst)},
+		{"2023-137T23:30+09", time.Date(2023, time.May, 17, 23, 30, 0, 0, jst)},
+		{"20230517T233015+09", time.Date(2023, time.May, 17, 23, 30, 15, 0, jst)},
+		{"2023-05-17+09", time.Date(2023, time.May, 17, 0, 0, 0, 0, jst)},
+	} {
+		parsed, err := flextime.ParseISO8601(tc.value)
+		require.NoError(t, err, "value = %s", tc.value)
+		assert.True(t, tc.expected.Equal(parsed), "value = %s, parsed = %s", tc.value, parsed)
+	}
+
+	for _, value := range []string{
+		"",
+		"2023-13-01",
+		"2023-366",
+		"2023-W54-1",
+		"2023-W20-8",
+		// the extended and the basic format can not be mixed.
+		"2023-05-17T143015",
+		"20230517T14:30:15",
+		"2023-05-17 14:30:15",
+	} {
+		_, err := flextime.ParseISO8601(value)
+		assert.Error(t, err, "value = %s", value)
+	}
+}
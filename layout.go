@@ -0,0 +1,1022 @@
+package flextime
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	optionalstring "github.com/ngicks/flextime/optional_string"
+	"github.com/ngicks/type-param-common/set"
+)
+
+// Layout is a compiled flextime layout.
+// Compiling enumerates optional parts and converts tokens only once,
+// so it can be reused for repeated parsing.
+type Layout struct {
+	flexLayout string
+	// tables are tables tokens of flexLayout are looked up in.
+	tables     *tokenTables
+	candidates []candidate
+	parseOpts  parseOptions
+	// formatChunks are chunks of the first enumerated layout,
+	// where all optional parts are present and the first branch of each alternation is taken.
+	formatChunks []layoutChunk
+	// formatLoc is the location times are converted into before formatting, if non nil.
+	formatLoc *time.Location
+	// locale is names of months and weekdays used by Format, if non nil.
+	locale *LocaleNames
+	// relaxYear is set by RelaxYear.
+	relaxYear bool
+	// digits maps runes to digits they are read as, set by WithDigits.
+	digits map[rune]int
+	// trimZeroOptionals is set by TrimZeroOptionals.
+	trimZeroOptionals bool
+	// trimTree is the tree of the source layout Format uses instead of formatChunks if trimZeroOptionals is set.
+	trimTree *trimNode
+	// maxValueLen and maxLayoutLen are set by MaxValueLen and MaxLayoutLen.
+	maxValueLen  int
+	maxLayoutLen int
+}
+
+// Option configures a Layout. Pass it to Compile.
+type Option func(l *Layout)
+
+// WithLocation makes Format convert times into loc before formatting.
+// It only affects Format and AppendFormat, not Parse.
+func WithLocation(loc *time.Location) Option {
+	return func(l *Layout) {
+		l.formatLoc = loc
+	}
+}
+
+// CaseInsensitiveMeridiem makes A and a tokens accept AM / PM in any case, e.g. pm, PM or Pm.
+func CaseInsensitiveMeridiem() Option {
+	return func(l *Layout) {
+		l.parseOpts.caseInsensitiveMeridiem = true
+	}
+}
+
+// AllowOffsetPrefix makes numeric offset tokens, e.g. Z or -0700, accept a leading UTC or GMT,
+// e.g. UTC+09:00 or GMT-0500. The prefix is stripped and the zone is set from the numeric part.
+// Plain offsets are accepted as well. It only affects Parse, not Format.
+func AllowOffsetPrefix() Option {
+	return func(l *Layout) {
+		l.parseOpts.offsetPrefix = true
+	}
+}
+
+// WithRFC2822Zones makes the MST token look up zone names in RFC2822Zones first,
+// e.g. EDT is parsed as -04:00 rather than a zone with unknown offset.
+// Names not in RFC2822Zones are parsed as usual. It only affects Parse, not Format.
+func WithRFC2822Zones() Option {
+	return func(l *Layout) {
+		l.parseOpts.rfc2822Zones = true
+	}
+}
+
+// IgnoreWeekday makes day of week tokens, ww, w, WEEKDAY, E and c, skip the value at their position
+// instead of reading it, so that a weekday wrong for the date does not fail Parse.
+// Names are skipped as a run of letters, e.g. Thurs is also accepted, and numbers as a digit.
+// The week-numbering weekday, e, is not affected since it decides the date. It only affects Parse, not Format.
+func IgnoreWeekday() Option {
+	return func(l *Layout) {
+		l.parseOpts.ignoreWeekday = true
+	}
+}
+
+// LiteralUnknownTokens makes characters which start no token, e.g. a single Y which is not YY or YYYY,
+// literals instead of making Compile fail, so that tokens can be embedded in prose.
+// Be careful that tokens are still found in words, e.g. D and a of "Date" are day of month and am/pm,
+// a misspelled token silently becomes a literal, and a part of it may still be a token, e.g. YYY is YY followed by Y.
+// Quote literals if possible.
+func LiteralUnknownTokens() Option {
+	return func(l *Layout) {
+		l.tables = l.tables.withLiteralUnknown()
+	}
+}
+
+// RelaxYear makes Parse retry with 4 digit year tokens, YYYY and yyyy, replaced by YY and yy,
+// after all the layouts enumerated from the source layout failed.
+// Thus 2 digit years are accepted where 4 digit years are expected, e.g. 22-10-20 for YYYY-MM-DD.
+// 2 digit years are read as time.Parse does: 69 to 99 are 1969 to 1999, and 00 to 68 are 2000 to 2068.
+// It only affects Parse, not Format.
+func RelaxYear() Option {
+	return func(l *Layout) {
+		l.relaxYear = true
+	}
+}
+
+// AcceptLeapSecond makes second tokens, ss and s, accept 60, which some sources record at leap seconds,
+// e.g. 2016-12-31T23:59:60Z, instead of failing with second out of range.
+// Since time.Time can not represent leap seconds, the second is clamped to the last instant of the 59th second,
+// e.g. 23:59:59.999999999, rather than rolled over to the next minute,
+// so that the date and the order of times are kept. A fractional second read along with it is discarded.
+// Neither the time of day nor the date is checked to be a real leap second. It only affects Parse, not Format.
+func AcceptLeapSecond() Option {
+	return func(l *Layout) {
+		l.parseOpts.leapSecond = true
+	}
+}
+
+// WithDigits makes Parse read runes in digits as the digits they are mapped to, 0 to 9,
+// e.g. ArabicIndicDigits reads ٢٠٢٣ as 2023.
+// Values are converted into ASCII digits before parsing, and so are literals of the layout,
+// thus a literal containing the runes still matches itself, as well as ASCII digits.
+// Month and weekday names are not affected unless they contain the runes.
+// Errors may report the converted value. It only affects Parse, not Format.
+func WithDigits(digits map[rune]int) Option {
+	return func(l *Layout) {
+		l.digits = digits
+	}
+}
+
+// asciiDigits converts runes of value in l.digits into ASCII digits.
+func (l *Layout) asciiDigits(value string) string {
+	if l.digits == nil {
+		return value
+	}
+	return convertDigits(l.digits, value)
+}
+
+func convertDigits(digits map[rune]int, value string) string {
+	return strings.Map(func(r rune) rune {
+		if d, ok := digits[r]; ok {
+			return rune('0' + d)
+		}
+		return r
+	}, value)
+}
+
+// originalSuffix returns the suffix of value corresponding to suffix of value converted by convertDigits.
+// Conversion maps a rune to a rune, so they have the same number of runes.
+func originalSuffix(value, suffix string) string {
+	n := utf8.RuneCountInString(suffix)
+	i := len(value)
+	for ; n > 0 && i > 0; n-- {
+		_, size := utf8.DecodeLastRuneInString(value[:i])
+		i -= size
+	}
+	return value[i:]
+}
+
+// withASCIIDigits returns c with runes of literals in digits converted into ASCII digits.
+func (c candidate) withASCIIDigits(digits map[rune]int) (candidate, error) {
+	chunks := make([]layoutChunk, len(c.chunks))
+	for i, chunk := range c.chunks {
+		if !chunk.isToken() {
+			chunk.literal = convertDigits(digits, chunk.literal)
+		}
+		chunks[i] = chunk
+	}
+	return candidateOf(c.flexLayout, chunks)
+}
+
+// candidate is one of layouts enumerated from optional parts of a flextime layout.
+type candidate struct {
+	// flexLayout is the enumerated flextime layout.
+	flexLayout string
+	chunks     []layoutChunk
+	// goLayout is Go reference layout converted from chunks.
+	// It is empty if computed is true.
+	goLayout string
+	// computed is true if chunks contain a computed token.
+	computed bool
+	// crossChecked is true if chunks contain fields checked against each other, e.g. DDD and MM.
+	// Such candidates are parsed by flextime's own parser
+	// so that a mismatch is reported by flextime tokens.
+	crossChecked bool
+	// misread is true if time.Parse would read goLayout differently from chunks,
+	// e.g. a quoted -07 which it takes for an offset.
+	// Such candidates are parsed by flextime's own parser.
+	misread bool
+	// longYear is true if chunks contain YYYY or yyyy.
+	// time.Parse fails on negative years, so such candidates fall back to flextime's own parser.
+	longYear bool
+	// key is goLayout if it is not computed.
+	// Otherwise computed tokens are left as flextime tokens.
+	// It is used to sort and dedupe candidates.
+	key string
+}
+
+func newCandidate(tables *tokenTables, raw optionalstring.RawString) (candidate, error) {
+	chunks, err := tables.splitChunksRaw(raw)
+	if err != nil {
+		return candidate{}, err
+	}
+	return candidateOf(raw.String(), chunks)
+}
+
+// candidateOf returns the candidate of chunks split from flexLayout.
+func candidateOf(flexLayout string, chunks []layoutChunk) (candidate, error) {
+	c := candidate{
+		flexLayout:   flexLayout,
+		chunks:       chunks,
+		computed:     hasComputed(chunks),
+		crossChecked: hasCrossCheckedFields(chunks),
+		longYear:     hasLongYear(chunks),
+	}
+	if !c.computed {
+		var err error
+		c.goLayout, err = chunksToGoLayout(c.flexLayout, chunks)
+		if err != nil {
+			return candidate{}, err
+		}
+		c.misread = !goLayoutMatches(c.goLayout, chunks)
+		c.key = c.goLayout
+		return c, nil
+	}
+	for _, chunk := range chunks {
+		switch {
+		case !chunk.isToken():
+			c.key += chunk.literal
+		case chunk.isComputed():
+			c.key += string(chunk.token)
+		default:
+			c.key += chunk.goFmt
+		}
+	}
+	return c, nil
+}
+
+// relaxYear returns c with 4 digit year tokens, YYYY and yyyy, replaced by YY and yy.
+// ok is false if c has no such token.
+func (c candidate) relaxYear() (relaxed candidate, ok bool, err error) {
+	if !c.longYear {
+		return candidate{}, false, nil
+	}
+	var flexLayout strings.Builder
+	chunks := make([]layoutChunk, len(c.chunks))
+	last := 0
+	for i, chunk := range c.chunks {
+		if chunk.isToken() && !chunk.isComputed() && chunk.goFmt == "2006" {
+			flexLayout.WriteString(c.flexLayout[last:chunk.offset])
+			chunk.token = "YY"
+			if strings.HasPrefix(string(c.chunks[i].token), "y") {
+				chunk.token = "yy"
+			}
+			chunk.goFmt = "06"
+			chunk.offset = flexLayout.Len()
+			flexLayout.WriteString(string(chunk.token))
+			last = c.chunks[i].offset + len(c.chunks[i].token)
+		} else {
+			chunk.offset += flexLayout.Len() - last
+		}
+		chunks[i] = chunk
+	}
+	flexLayout.WriteString(c.flexLayout[last:])
+	relaxed, err = candidateOf(flexLayout.String(), chunks)
+	return relaxed, true, err
+}
+
+// parseOptions changes how values are matched against layouts.
+// Go reference layouts are parsed by flextime's own parser
+// if any of options is set, since time.Parse does not support them.
+type parseOptions struct {
+	// flexibleSpace makes any run of whitespace in unquoted literals of a layout
+	// match any run of whitespace in a value.
+	flexibleSpace bool
+	// caseInsensitiveMeridiem makes A and a tokens match AM / PM in any case.
+	caseInsensitiveMeridiem bool
+	// offsetPrefix makes numeric offset tokens skip a leading UTC or GMT.
+	offsetPrefix bool
+	// rfc2822Zones makes the MST token look up RFC2822Zones.
+	rfc2822Zones bool
+	// ignoreWeekday makes day of week tokens skip the value instead of reading it.
+	ignoreWeekday bool
+	// locale makes month and weekday name tokens match its names instead of English ones, if non nil.
+	locale *LocaleNames
+	// leapSecond makes second tokens accept 60.
+	leapSecond bool
+}
+
+func (c candidate) parse(value string, defaultLoc, local *time.Location, opts parseOptions) (time.Time, error) {
+	if c.computed || c.crossChecked || c.misread || opts != (parseOptions{}) {
+		return parseChunks(c.flexLayout, c.chunks, value, defaultLoc, local, opts)
+	}
+	var t time.Time
+	var err error
+	if defaultLoc == local {
+		t, err = time.ParseInLocation(c.goLayout, value, local)
+	} else {
+		t, err = time.Parse(c.goLayout, value)
+	}
+	if err == nil {
+		return t, nil
+	}
+	// Parse again by flextime's own parser so that the error is reported by flextime tokens,
+	// e.g. DD rather than 02.
+	ownT, ownErr := parseChunks(c.flexLayout, c.chunks, value, defaultLoc, local, opts)
+	if ownErr != nil {
+		return time.Time{}, ownErr
+	}
+	if c.longYear && strings.Contains(value, "-") {
+		// time.Parse does not accept negative years, e.g. -0044, which time.Time.Format produces.
+		return ownT, nil
+	}
+	return t, err
+}
+
+// Compile parses flexLayout and returns a compiled *Layout configured by opts.
+// It returns *optionalstring.SyntaxError if flexLayout has unbalanced optional parts,
+// or *FormatError if it contains an invalid token.
+func Compile(flexLayout string, opts ...Option) (*Layout, error) {
+	return compile(context.Background(), defaultTables(), flexLayout, opts...)
+}
+
+// MustCompile is like Compile but panics if flexLayout can not be compiled.
+// It simplifies safe initialization of global variables holding compiled layouts.
+func MustCompile(flexLayout string, opts ...Option) *Layout {
+	l, err := Compile(flexLayout, opts...)
+	if err != nil {
+		panic(`flextime: Compile(` + strconv.Quote(flexLayout) + `): ` + err.Error())
+	}
+	return l
+}
+
+// compile is like Compile but looks up tokens in tables
+// and returns ctx.Err() if ctx is done while compiling.
+func compile(ctx context.Context, tables *tokenTables, flexLayout string, opts ...Option) (*Layout, error) {
+	l := &Layout{
+		flexLayout: flexLayout,
+		tables:     tables,
+	}
+	for _, opt := range opts {
+		opt(l)
+	}
+	if err := l.checkLayoutLen(); err != nil {
+		return nil, err
+	}
+
+	rawFormats, err := optionalstring.EnumerateOptionalStringRawContext(ctx, flexLayout)
+	if err != nil {
+		return nil, err
+	}
+	if l.trimZeroOptionals {
+		if l.trimTree, err = newTrimTree(l.tables, flexLayout); err != nil {
+			return nil, err
+		}
+	}
+
+	seen := set.New[string]()
+	candidates := make([]candidate, 0, len(rawFormats))
+	for i, raw := range rawFormats {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		c, err := newCandidate(l.tables, raw)
+		if err != nil {
+			return nil, err
+		}
+		if i == 0 {
+			l.formatChunks = c.chunks
+		}
+		if l.digits != nil {
+			// literals are matched against values after conversion, see WithDigits.
+			if c, err = c.withASCIIDigits(l.digits); err != nil {
+				return nil, err
+			}
+		}
+		if seen.Has(c.key) {
+			continue
+		}
+		seen.Add(c.key)
+		candidates = append(candidates, c)
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		return moreSpecific(candidates[i], candidates[j])
+	})
+	if l.relaxYear {
+		// relaxed candidates are tried only after all the others failed.
+		for _, c := range candidates {
+			relaxed, ok, err := c.relaxYear()
+			if err != nil {
+				return nil, err
+			}
+			if !ok || seen.Has(relaxed.key) {
+				continue
+			}
+			seen.Add(relaxed.key)
+			candidates = append(candidates, relaxed)
+		}
+	}
+	l.candidates = candidates
+	return l, nil
+}
+
+// moreSpecific reports whether candidate i should be tried before j.
+// Candidates with more tokens come first, then ones with longer keys, see longerFirst.
+// Thus the richest interpretation of a value wins
+// rather than a shorter candidate which happens to match a prefix of it and drops the rest.
+func moreSpecific(i, j candidate) bool {
+	if ti, tj := i.tokenCount(), j.tokenCount(); ti != tj {
+		return ti > tj
+	}
+	return longerFirst(i.key, j.key)
+}
+
+func (c candidate) tokenCount() int {
+	var count int
+	for _, chunk := range c.chunks {
+		if chunk.isToken() {
+			count++
+		}
+	}
+	return count
+}
+
+// String returns the source flextime layout.
+func (l *Layout) String() string {
+	return l.flexLayout
+}
+
+// GoLayouts returns Go reference layouts enumerated from the source layout,
+// in the order Parse tries them.
+// Enumerated layouts containing computed tokens are omitted
+// since they have no Go reference layout equivalent.
+func (l *Layout) GoLayouts() []string {
+	layouts := make([]string, 0, len(l.candidates))
+	for _, c := range l.candidates {
+		if !c.computed {
+			layouts = append(layouts, c.goLayout)
+		}
+	}
+	return layouts
+}
+
+// PreviewExpansion returns flextime layouts enumerated from flexLayout, in the order Parse tries them.
+// Unlike GoLayouts, they are kept in flextime syntax, e.g. YYYY-MM-DD'T'HH for YYYY-MM-DD['T'HH],
+// and enumerated layouts containing computed tokens are also included.
+// Enumerated layouts which are converted into the same Go reference layout are reported only once.
+// Chunks which would be read differently when adjacent are separated by an empty quote as Builder does,
+// so that each of them is read as Parse reads it, e.g. M[-]MMM is previewed as M-MMM and as
+//
+//	M''MMM
+//
+// rather than MMMM.
+func PreviewExpansion(flexLayout string) ([]string, error) {
+	l, err := Compile(flexLayout)
+	if err != nil {
+		return nil, err
+	}
+	layouts := make([]string, len(l.candidates))
+	for i, c := range l.candidates {
+		layouts[i] = c.preview()
+	}
+	return layouts, nil
+}
+
+// preview returns c.flexLayout with an empty quote inserted between chunks
+// which would be read differently when adjacent.
+func (c candidate) preview() string {
+	var b strings.Builder
+	for i, chunk := range c.chunks {
+		end := len(c.flexLayout)
+		if i+1 < len(c.chunks) {
+			end = c.chunks[i+1].offset
+		}
+		piece := c.flexLayout[chunk.offset:end]
+		if b.Len() > 0 && !readsAs(b.String()+piece, c.chunks[:i+1]) {
+			b.WriteString("''")
+		}
+		b.WriteString(piece)
+	}
+	return b.String()
+}
+
+// readsAs reports whether layout is split into the same tokens and literals as chunks.
+func readsAs(layout string, chunks []layoutChunk) bool {
+	split, err := splitChunks(layout)
+	return err == nil && chunkSequence(split) == chunkSequence(chunks)
+}
+
+// chunkSequence returns a string which is same for chunks of the same tokens and literals,
+// adjacent literals joined.
+func chunkSequence(chunks []layoutChunk) string {
+	var b strings.Builder
+	literal := false
+	for _, c := range chunks {
+		if c.isToken() {
+			b.WriteString("\x00t")
+			b.WriteString(string(c.token))
+			literal = false
+			continue
+		}
+		if !literal {
+			b.WriteString("\x00l")
+		}
+		b.WriteString(c.literal)
+		literal = true
+	}
+	return b.String()
+}
+
+// Format returns a textual representation of t.
+// If the source layout has optional parts, all of them are present,
+// and the first branch is taken for each alternation, unless TrimZeroOptionals is given.
+// t is converted into the location set by WithLocation if any.
+func (l *Layout) Format(t time.Time) string {
+	return string(l.AppendFormat(make([]byte, 0, len(l.flexLayout)+10), t))
+}
+
+// AppendFormat is like Format but appends the textual representation to b
+// and returns the extended buffer.
+func (l *Layout) AppendFormat(b []byte, t time.Time) []byte {
+	if l.formatLoc != nil {
+		t = t.In(l.formatLoc)
+	}
+	if l.trimTree != nil {
+		return appendChunks(b, t, l.trimTree.appendChunks(nil, t), l.locale)
+	}
+	return appendChunks(b, t, l.formatChunks, l.locale)
+}
+
+// layout returns the layout passed to the underlying parser:
+// goLayout for time.Parse, or the enumerated flextime layout for computed candidates.
+func (c candidate) layout() string {
+	if c.computed {
+		return c.flexLayout
+	}
+	return c.goLayout
+}
+
+// parse tries candidates in order and returns the first success
+// along with the layout of the candidate.
+// It stops at a candidate which matches value but has contradicting fields, see isMismatch,
+// rather than trying other candidates.
+// The meaning of defaultLoc and local is described in parseChunks.
+func (l *Layout) parse(
+	ctx context.Context,
+	value string,
+	defaultLoc, local *time.Location,
+	opts parseOptions,
+) (time.Time, string, error) {
+	t, c, err := l.parseCandidate(ctx, value, defaultLoc, local, opts)
+	if c == nil {
+		return t, "", err
+	}
+	return t, c.layout(), nil
+}
+
+// parseCandidate is like parse but returns the candidate which parsed value.
+func (l *Layout) parseCandidate(
+	ctx context.Context,
+	value string,
+	defaultLoc, local *time.Location,
+	opts parseOptions,
+) (time.Time, *candidate, error) {
+	if err := l.checkValueLen(value); err != nil {
+		return time.Time{}, nil, err
+	}
+	var bestErr error
+	attempted := make([]string, 0, len(l.candidates))
+	converted := l.asciiDigits(value)
+	for i := range l.candidates {
+		if err := ctx.Err(); err != nil {
+			return time.Time{}, nil, err
+		}
+		c := &l.candidates[i]
+		attempted = append(attempted, c.layout())
+		t, err := c.parse(converted, defaultLoc, local, opts)
+		if isMismatch(err) {
+			return time.Time{}, nil, l.newParseError(value, attempted, err)
+		}
+		if err != nil {
+			bestErr = moreInformative(bestErr, err)
+		} else {
+			return t, c, nil
+		}
+	}
+	return time.Time{}, nil, l.newParseError(value, attempted, bestErr)
+}
+
+func (l *Layout) newParseError(value string, attempted []string, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &ParseError{
+		Layout:    l.flexLayout,
+		Value:     value,
+		Attempted: attempted,
+		Err:       err,
+	}
+}
+
+// parseAll tries all candidates and returns every distinct result
+// along with the layout of the first candidate that produced it.
+// Results are distinct in terms of time.Time.Equal.
+// err is the most informative error of failed candidates, see moreInformative.
+func (l *Layout) parseAll(
+	value string,
+	defaultLoc, local *time.Location,
+) (times []time.Time, layouts []string, err error) {
+	if err := l.checkValueLen(value); err != nil {
+		return nil, nil, err
+	}
+	converted := l.asciiDigits(value)
+CANDIDATES:
+	for _, c := range l.candidates {
+		t, parseErr := c.parse(converted, defaultLoc, local, l.parseOpts)
+		if parseErr != nil {
+			err = moreInformative(err, parseErr)
+			continue
+		}
+		for _, seen := range times {
+			if seen.Equal(t) {
+				continue CANDIDATES
+			}
+		}
+		times = append(times, t)
+		layouts = append(layouts, c.layout())
+	}
+	if len(times) == 0 {
+		attempted := make([]string, len(l.candidates))
+		for i, c := range l.candidates {
+			attempted[i] = c.layout()
+		}
+		err = l.newParseError(value, attempted, err)
+	}
+	return times, layouts, err
+}
+
+// moreInformative returns whichever of current and candidate
+// failed at the later position of the value.
+// It is the one with shorter ValueElem if both are *time.ParseError.
+// current is preferred on a tie.
+func moreInformative(current, candidate error) error {
+	if current == nil {
+		return candidate
+	}
+	var currentErr, candidateErr *time.ParseError
+	if !errors.As(current, &currentErr) {
+		return candidate
+	}
+	if !errors.As(candidate, &candidateErr) {
+		return current
+	}
+	if len(candidateErr.ValueElem) < len(currentErr.ValueElem) {
+		return candidate
+	}
+	return current
+}
+
+// Parse parses value by the layouts enumerated from the source layout and returns the first success.
+// Layouts with more tokens are tried first, then longer ones,
+// so that the most specific interpretation wins, e.g. YYYY-MM-DD[THH:mm:ss]
+// tries YYYY-MM-DDTHH:mm:ss before YYYY-MM-DD.
+// Layouts with a same number of tokens and length are tried in lexical order of their Go reference layouts.
+// The order of enumeration, see optionalstring.EnumerateOptionalStringRaw, only decides
+// which of enumerated layouts converted into a same Go reference layout is kept, the first one.
+// If none of them parses value, it returns *ParseError.
+func (l *Layout) Parse(value string) (time.Time, error) {
+	t, _, err := l.parse(context.Background(), value, time.UTC, time.Local, l.parseOpts)
+	return t, err
+}
+
+// ParseWithLayout is like Parse but also returns the layout which successfully parsed value.
+// It is the Go reference layout passed to time.Parse,
+// or the enumerated flextime layout if it contains computed tokens.
+func (l *Layout) ParseWithLayout(value string) (time.Time, string, error) {
+	return l.parse(context.Background(), value, time.UTC, time.Local, l.parseOpts)
+}
+
+// ParseFlexibleSpace is like Parse but leniently matches whitespace.
+// A run of whitespace, e.g. spaces and tabs, in unquoted literals of the layout
+// matches any run of whitespace in value.
+// Whitespace in quoted or backslash escaped literals must match exactly.
+// Leading and trailing whitespace of value is ignored.
+func (l *Layout) ParseFlexibleSpace(value string) (time.Time, error) {
+	opts := l.parseOpts
+	opts.flexibleSpace = true
+	t, _, err := l.parse(context.Background(), strings.TrimSpace(value), time.UTC, time.Local, opts)
+	return t, err
+}
+
+// ParseAll is like Parse but tries all enumerated layouts
+// and returns every distinct result along with the layout which produced it.
+// Results are distinct in terms of time.Time.Equal.
+// Layouts are same as ones returned from ParseWithLayout.
+// If none of layouts matches,
+// it returns the error of the layout which failed at the latest position of value.
+func (l *Layout) ParseAll(value string) ([]time.Time, []string, error) {
+	times, layouts, err := l.parseAll(value, time.UTC, time.Local)
+	if len(times) == 0 {
+		return nil, nil, err
+	}
+	return times, layouts, nil
+}
+
+// ParseStrict is like Parse but tries all enumerated layouts.
+// It returns *AmbiguousError if two or more of them parse value into different instants.
+func (l *Layout) ParseStrict(value string) (time.Time, error) {
+	times, layouts, err := l.parseAll(value, time.UTC, time.Local)
+	switch len(times) {
+	case 0:
+		return time.Time{}, err
+	case 1:
+		return times[0], nil
+	}
+	return time.Time{}, &AmbiguousError{
+		Layout:  l.flexLayout,
+		Value:   value,
+		Times:   times,
+		Layouts: layouts,
+	}
+}
+
+// ParseExact is like Parse but reports trailing text explicitly.
+// Each enumerated layout is accepted only when it consumes value entirely.
+// If none of them matches and some of them match a prefix of value,
+// it returns *ExtraTextError naming the shortest unconsumed suffix.
+// Otherwise it returns *ParseError as Parse does.
+func (l *Layout) ParseExact(value string) (time.Time, error) {
+	if err := l.checkValueLen(value); err != nil {
+		return time.Time{}, err
+	}
+	var extraErr *ExtraTextError
+	var lastErr error
+	attempted := make([]string, 0, len(l.candidates))
+	converted := l.asciiDigits(value)
+	for _, c := range l.candidates {
+		attempted = append(attempted, c.layout())
+		t, err := c.parse(converted, time.UTC, time.Local, l.parseOpts)
+		if err == nil {
+			return t, nil
+		}
+		if isMismatch(err) {
+			return time.Time{}, l.newParseError(value, attempted, err)
+		}
+		if suffix, ok := extraText(err); ok {
+			suffix = originalSuffix(value, suffix)
+			if extraErr == nil || len(suffix) < len(extraErr.Suffix) {
+				extraErr = &ExtraTextError{Layout: l.flexLayout, Value: value, Suffix: suffix}
+			}
+			continue
+		}
+		lastErr = err
+	}
+	if extraErr != nil {
+		return time.Time{}, extraErr
+	}
+	return time.Time{}, l.newParseError(value, attempted, lastErr)
+}
+
+// parsePrefix is like Parse but also accepts value which has trailing text after a time.
+// Candidates are tried in order and the first one which parses value or a prefix of it wins.
+// rest is the trailing text, empty if value is entirely parsed.
+func (l *Layout) parsePrefix(value string) (t time.Time, rest string, err error) {
+	var bestErr error
+	attempted := make([]string, 0, len(l.candidates))
+	converted := l.asciiDigits(value)
+	for _, c := range l.candidates {
+		attempted = append(attempted, c.layout())
+		t, err := c.parse(converted, time.UTC, time.Local, l.parseOpts)
+		if err == nil {
+			return t, "", nil
+		}
+		if suffix, ok := extraText(err); ok {
+			t, err = c.parse(converted[:len(converted)-len(suffix)], time.UTC, time.Local, l.parseOpts)
+			if err == nil {
+				return t, originalSuffix(value, suffix), nil
+			}
+		}
+		if isMismatch(err) {
+			return time.Time{}, value, l.newParseError(value, attempted, err)
+		}
+		bestErr = moreInformative(bestErr, err)
+	}
+	return time.Time{}, value, l.newParseError(value, attempted, bestErr)
+}
+
+// extraText returns the unconsumed suffix if err is caused by trailing text of value.
+func extraText(err error) (suffix string, ok bool) {
+	var parseErr *time.ParseError
+	if errors.As(err, &parseErr) && strings.HasPrefix(parseErr.Message, ": extra text") {
+		return parseErr.ValueElem, true
+	}
+	return "", false
+}
+
+// Valid reports whether value is parsed by l, with the same matching as Parse.
+// It is cheaper than Parse when only validity matters, since no error is built.
+func (l *Layout) Valid(value string) bool {
+	if l.checkValueLen(value) != nil {
+		return false
+	}
+	value = l.asciiDigits(value)
+	for i := range l.candidates {
+		_, err := l.candidates[i].parse(value, time.UTC, time.Local, l.parseOpts)
+		if err == nil {
+			return true
+		}
+		if isMismatch(err) {
+			return false
+		}
+	}
+	return false
+}
+
+// ParseContext is like Parse but returns ctx.Err() if ctx is done before value is parsed.
+// ctx is checked between attempts of enumerated layouts.
+func (l *Layout) ParseContext(ctx context.Context, value string) (time.Time, error) {
+	t, _, err := l.parse(ctx, value, time.UTC, time.Local, l.parseOpts)
+	return t, err
+}
+
+func (l *Layout) ParseInLocation(value string, loc *time.Location) (time.Time, error) {
+	t, _, err := l.parse(context.Background(), value, loc, loc, l.parseOpts)
+	return t, err
+}
+
+// Parse parses value by flexLayout.
+// Compiled layouts are cached for recently used flexLayout,
+// so repeated calls with the same flexLayout skip compiling.
+func Parse(flexLayout, value string) (time.Time, error) {
+	l, err := compileCached(flexLayout)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return l.Parse(value)
+}
+
+// ParseContext is like Parse but returns ctx.Err() if ctx is done before value is parsed.
+// ctx is checked while enumerating optional parts of flexLayout and between attempts of enumerated layouts,
+// so it bounds the time spent on a layout which expands to an enormous number of layouts.
+func ParseContext(ctx context.Context, flexLayout, value string) (time.Time, error) {
+	l, err := compileCachedContext(ctx, flexLayout)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return l.ParseContext(ctx, value)
+}
+
+// ParseInLocation is like Parse but interprets value in loc
+// if value does not contain time zone information.
+func ParseInLocation(flexLayout, value string, loc *time.Location) (time.Time, error) {
+	l, err := compileCached(flexLayout)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return l.ParseInLocation(value, loc)
+}
+
+// ParseWithLayout is like Parse but also returns the layout which successfully parsed value.
+// See (*Layout).ParseWithLayout.
+func ParseWithLayout(flexLayout, value string) (time.Time, string, error) {
+	l, err := compileCached(flexLayout)
+	if err != nil {
+		return time.Time{}, "", err
+	}
+	return l.ParseWithLayout(value)
+}
+
+// ParseFlexibleSpace is like Parse but leniently matches whitespace.
+// See (*Layout).ParseFlexibleSpace.
+func ParseFlexibleSpace(flexLayout, value string) (time.Time, error) {
+	l, err := compileCached(flexLayout)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return l.ParseFlexibleSpace(value)
+}
+
+// ParseAll returns all interpretations of value.
+// See (*Layout).ParseAll.
+func ParseAll(flexLayout, value string) ([]time.Time, []string, error) {
+	l, err := compileCached(flexLayout)
+	if err != nil {
+		return nil, nil, err
+	}
+	return l.ParseAll(value)
+}
+
+// ParseStrict is like Parse but fails if value is ambiguous.
+// See (*Layout).ParseStrict.
+func ParseStrict(flexLayout, value string) (time.Time, error) {
+	l, err := compileCached(flexLayout)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return l.ParseStrict(value)
+}
+
+// ParseExact is like Parse but reports trailing text explicitly.
+// See (*Layout).ParseExact.
+func ParseExact(flexLayout, value string) (time.Time, error) {
+	l, err := compileCached(flexLayout)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return l.ParseExact(value)
+}
+
+// ExtraTextError is returned from ParseExact
+// when a prefix of value matches the layout but the rest is left unconsumed.
+type ExtraTextError struct {
+	Layout string
+	Value  string
+	// Suffix is the shortest unconsumed suffix of Value among enumerated layouts.
+	Suffix string
+}
+
+func (e *ExtraTextError) Error() string {
+	return fmt.Sprintf(
+		"extra text: layout %q does not consume %q at the end of value %q",
+		e.Layout, e.Suffix, e.Value,
+	)
+}
+
+// ParseAny tries flexLayouts in order and returns the first successful result
+// along with the flexLayout which parsed value.
+// Each of flexLayouts is tried with all its enumerated layouts as Parse does.
+// If none of them parses value, it returns *ParseAnyError holding errors of each flexLayout,
+// including errors of compiling invalid ones.
+func ParseAny(flexLayouts []string, value string) (time.Time, string, error) {
+	errs := make([]error, len(flexLayouts))
+	for i, flexLayout := range flexLayouts {
+		t, err := Parse(flexLayout, value)
+		if err == nil {
+			return t, flexLayout, nil
+		}
+		errs[i] = err
+	}
+	return time.Time{}, "", &ParseAnyError{
+		Value:   value,
+		Layouts: flexLayouts,
+		Errs:    errs,
+	}
+}
+
+// ParseAnyError is returned from ParseAny when none of layouts parses a value.
+type ParseAnyError struct {
+	Value string
+	// Layouts are tried layouts.
+	Layouts []string
+	// Errs are errors of each of Layouts.
+	Errs []error
+}
+
+func (e *ParseAnyError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "none of %d layouts parses %q:", len(e.Layouts), e.Value)
+	for i, err := range e.Errs {
+		fmt.Fprintf(&b, "\n\t%q: %s", e.Layouts[i], strings.ReplaceAll(err.Error(), "\n", "\n\t\t"))
+	}
+	return b.String()
+}
+
+// Unwrap returns Errs so that errors.Is and errors.As see errors of each layout.
+func (e *ParseAnyError) Unwrap() []error {
+	return e.Errs
+}
+
+// ParseError is returned from Parse and its variants when none of enumerated layouts parses a value.
+// Err is usually *time.ParseError of the layout which failed at the latest position of the value.
+// Its Layout is the enumerated flextime layout and LayoutElem is the flextime token, e.g. DD rather than 02.
+type ParseError struct {
+	// Layout is the source flextime layout.
+	Layout string
+	Value  string
+	// Attempted are layouts tried in order, as returned from ParseWithLayout.
+	Attempted []string
+	// Err is the most informative error of Attempted.
+	Err error
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("parsing time %q as %q: tried %q: %s", e.Value, e.Layout, e.Attempted, e.Err)
+}
+
+// Unwrap returns Err so that errors.As sees the underlying *time.ParseError.
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// AmbiguousError is returned from ParseStrict
+// when enumerated layouts parse a value into different instants.
+type AmbiguousError struct {
+	Layout string
+	Value  string
+	// Times are distinct results.
+	Times []time.Time
+	// Layouts are layouts which produced each of Times.
+	Layouts []string
+}
+
+func (e *AmbiguousError) Error() string {
+	interpretations := make([]string, len(e.Times))
+	for i := range e.Times {
+		interpretations[i] = fmt.Sprintf("%s by %q", e.Times[i].Format(time.RFC3339Nano), e.Layouts[i])
+	}
+	return fmt.Sprintf(
+		"ambiguous value: %q is parsed by layout %q in %d different ways: %s",
+		e.Value,
+		e.Layout,
+		len(e.Times),
+		strings.Join(interpretations, ", "),
+	)
+}
@@ -0,0 +1,85 @@
+package flextime
+
+import (
+	"container/list"
+	"context"
+	"sync"
+)
+
+// layoutCacheSize is the max number of compiled layouts kept by package level parse functions.
+const layoutCacheSize = 128
+
+// layoutCache is a concurrency-safe LRU cache of compiled layouts keyed by flextime layouts.
+type layoutCache struct {
+	mu    sync.Mutex
+	size  int
+	order *list.List // of *layoutCacheEntry. front is the most recently used.
+	items map[string]*list.Element
+}
+
+type layoutCacheEntry struct {
+	flexLayout string
+	layout     *Layout
+}
+
+func newLayoutCache(size int) *layoutCache {
+	return &layoutCache{
+		size:  size,
+		order: list.New(),
+		items: make(map[string]*list.Element, size),
+	}
+}
+
+func (c *layoutCache) get(flexLayout string) (*Layout, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	elem, ok := c.items[flexLayout]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(elem)
+	return elem.Value.(*layoutCacheEntry).layout, true
+}
+
+func (c *layoutCache) add(flexLayout string, l *Layout) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if elem, ok := c.items[flexLayout]; ok {
+		c.order.MoveToFront(elem)
+		return
+	}
+	c.items[flexLayout] = c.order.PushFront(&layoutCacheEntry{flexLayout: flexLayout, layout: l})
+	if c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.items, oldest.Value.(*layoutCacheEntry).flexLayout)
+	}
+}
+
+func (c *layoutCache) len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+var defaultLayoutCache = newLayoutCache(layoutCacheSize)
+
+// compileCached is like Compile without options but reuses recently compiled layouts.
+// Layout is never mutated after Compile, so it is safe to share.
+// Errors are not cached.
+func compileCached(flexLayout string) (*Layout, error) {
+	return compileCachedContext(context.Background(), flexLayout)
+}
+
+// compileCachedContext is like compileCached but returns ctx.Err() if ctx is done while compiling.
+func compileCachedContext(ctx context.Context, flexLayout string) (*Layout, error) {
+	if l, ok := defaultLayoutCache.get(flexLayout); ok {
+		return l, nil
+	}
+	l, err := compile(ctx, defaultTables(), flexLayout)
+	if err != nil {
+		return nil, err
+	}
+	defaultLayoutCache.add(flexLayout, l)
+	return l, nil
+}
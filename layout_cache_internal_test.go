@@ -0,0 +1,58 @@
+package flextime
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestLayoutCacheEviction(t *testing.T) {
+	cache := newLayoutCache(2)
+	a, b, c := &Layout{flexLayout: "a"}, &Layout{flexLayout: "b"}, &Layout{flexLayout: "c"}
+
+	cache.add("a", a)
+	cache.add("b", b)
+	if l, ok := cache.get("a"); !ok || l != a {
+		t.Fatalf("a must be cached")
+	}
+	// b is the least recently used.
+	cache.add("c", c)
+	if _, ok := cache.get("b"); ok {
+		t.Errorf("b must be evicted")
+	}
+	for _, key := range []string{"a", "c"} {
+		if _, ok := cache.get(key); !ok {
+			t.Errorf("%s must be cached", key)
+		}
+	}
+	if cache.len() != 2 {
+		t.Errorf("len must be 2 but %d", cache.len())
+	}
+}
+
+func TestCompileCachedConcurrent(t *testing.T) {
+	var wg sync.WaitGroup
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 200; j++ {
+				suffix := strings.Repeat("#", (i+j)%(layoutCacheSize*2))
+				layout, value := "YYYY-MM-DD"+suffix, "2022-10-20"+suffix
+				parsed, err := Parse(layout, value)
+				if err != nil {
+					t.Errorf("%+v", err)
+					return
+				}
+				if !parsed.Equal(time.Date(2022, time.October, 20, 0, 0, 0, 0, time.UTC)) {
+					t.Errorf("wrong result: %s", parsed)
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+	if n := defaultLayoutCache.len(); n > layoutCacheSize {
+		t.Errorf("cache must be bounded but has %d", n)
+	}
+}
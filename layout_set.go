@@ -14,13 +14,7 @@ type LayoutSet struct {
 
 func newLayoutSet(layouts []string) *LayoutSet {
 	sort.Slice(layouts, func(i, j int) bool {
-		iLen := len(layouts[i])
-		jLen := len(layouts[j])
-		if iLen != jLen {
-			return iLen > jLen
-		} else {
-			return strings.Compare(layouts[i], layouts[j]) == -1
-		}
+		return longerFirst(layouts[i], layouts[j])
 	})
 
 	return &LayoutSet{
@@ -28,19 +22,34 @@ func newLayoutSet(layouts []string) *LayoutSet {
 	}
 }
 
+// longerFirst reports whether layout i should be tried before j.
+// Longer layouts come first and layouts with a same length are sorted lexically.
+func longerFirst(i, j string) bool {
+	if len(i) != len(j) {
+		return len(i) > len(j)
+	}
+	return strings.Compare(i, j) == -1
+}
+
 func NewLayoutSet(optionalStr string) (*LayoutSet, error) {
 	rawFormats, err := optionalstring.EnumerateOptionalStringRaw(optionalStr)
 	if err != nil {
 		return nil, err
 	}
 
-	layouts := make([]string, len(rawFormats))
+	// Empty optional parts, e.g. `[]`, may yield identical layouts.
+	seen := set.New[string]()
+	layouts := make([]string, 0, len(rawFormats))
 	for i := 0; i < len(rawFormats); i++ {
 		replaced, err := ReplaceTimeTokenRaw(rawFormats[i])
 		if err != nil {
 			return nil, err
 		}
-		layouts[i] = replaced
+		if seen.Has(replaced) {
+			continue
+		}
+		seen.Add(replaced)
+		layouts = append(layouts, replaced)
 	}
 
 	return newLayoutSet(layouts), nil
@@ -77,3 +86,20 @@ func (l *LayoutSet) AddLayout(other *LayoutSet) *LayoutSet {
 
 	return newLayoutSet(setLayout.Values().Collect())
 }
+
+// ToGoLayout converts flexLayout into Go reference layouts.
+// Optional parts of flexLayout are enumerated and each variant is converted,
+// so the returned slice has every Go layout flexLayout may represent.
+// Duplicates are removed.
+//
+// The order is deterministic: longer layouts come first and layouts with a same length are sorted lexically.
+// It is not always the order Parse tries layouts, which puts layouts with more tokens first,
+// e.g. for YYYY[MMDD][' year'], 2006 year comes before 20060102 here, but Parse tries 20060102 first.
+// Use (*Layout).GoLayouts for the order Parse tries.
+func ToGoLayout(flexLayout string) ([]string, error) {
+	layouts, err := NewLayoutSet(flexLayout)
+	if err != nil {
+		return nil, err
+	}
+	return layouts.CloneLayout(), nil
+}
@@ -0,0 +1,128 @@
+package flextime_test
+
+import (
+	"testing"
+
+	"github.com/ngicks/flextime"
+	optionalstring "github.com/ngicks/flextime/optional_string"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type toGoLayoutTestCase struct {
+	input    string
+	expected []string
+}
+
+func TestToGoLayout(t *testing.T) {
+	cases := []toGoLayoutTestCase{
+		{
+			input: `YYYY-MM-DD[THH[:mm]][Z]`,
+			expected: []string{
+				`2006-01-02T15:04Z07:00`,
+				`2006-01-02T15Z07:00`,
+				`2006-01-02T15:04`,
+				`2006-01-02Z07:00`,
+				`2006-01-02T15`,
+				`2006-01-02`,
+			},
+		},
+		{
+			// nested
+			input: `HH[:mm[:ss]]`,
+			expected: []string{
+				`15:04:05`,
+				`15:04`,
+				`15`,
+			},
+		},
+		{
+			// empty optional is no-op.
+			input: `YYYY[]-MM`,
+			expected: []string{
+				`2006-01`,
+			},
+		},
+		{
+			input: `YYYY-[[]MM]`,
+			expected: []string{
+				`2006-01`,
+				`2006-`,
+			},
+		},
+		{
+			input: `'YYYY'-MM`,
+			expected: []string{
+				`YYYY-01`,
+			},
+		},
+		{
+			// backslash escaped brackets are literal.
+			input: `YYYY\[MM\][-DD]`,
+			expected: []string{
+				`2006[01]-02`,
+				`2006[01]`,
+			},
+		},
+		{
+			// longer first, unlike the order Parse tries, see TestToGoLayoutOrderDiffersFromParse.
+			input:    `YYYY[MMDD][' year']`,
+			expected: []string{`20060102 year`, `2006 year`, `20060102`, `2006`},
+		},
+	}
+
+	for _, testCase := range cases {
+		layouts, err := flextime.ToGoLayout(testCase.input)
+		require.NoError(t, err)
+		assert.Equal(t, testCase.expected, layouts, "input = %s", testCase.input)
+
+		// deterministic
+		again, err := flextime.ToGoLayout(testCase.input)
+		require.NoError(t, err)
+		assert.Equal(t, layouts, again)
+	}
+}
+
+func TestToGoLayoutOrderDiffersFromParse(t *testing.T) {
+	// Parse tries layouts with more tokens first, as GoLayouts reports.
+	assert.Equal(
+		t,
+		[]string{`20060102 year`, `20060102`, `2006 year`, `2006`},
+		flextime.MustCompile(`YYYY[MMDD][' year']`).GoLayouts(),
+	)
+}
+
+func TestToGoLayoutError(t *testing.T) {
+	for _, invalid := range []string{`YYYY-MM[`, `YYY-MM`} {
+		_, err := flextime.ToGoLayout(invalid)
+		assert.Error(t, err)
+	}
+}
+
+func TestLayoutSetAgreesWithOptionalString(t *testing.T) {
+	for _, input := range []string{
+		"YYYY-MM-DD",
+		"YYYY-MM-DD[THH[:mm[:ss.SSS]]][Z]",
+		"YYYY[-MM][-DD]",
+		"(MM/DD|DD/MM)/YYYY[ HH:mm]",
+		"YYYY-MM-DD[]",
+		`'[T]'HH[\[mm\]]`,
+	} {
+		enumerated, err := optionalstring.EnumerateOptionalStringRaw(input)
+		require.NoError(t, err, "input = %s", input)
+		expected := make([]string, 0, len(enumerated))
+		for _, raw := range enumerated {
+			goLayout, err := flextime.ReplaceTimeTokenRaw(raw)
+			require.NoError(t, err)
+			expected = append(expected, goLayout)
+		}
+
+		layoutSet, err := flextime.NewLayoutSet(input)
+		require.NoError(t, err)
+		assert.ElementsMatch(t, expected, layoutSet.Layout(), "input = %s", input)
+
+		goLayouts, err := flextime.ToGoLayout(input)
+		require.NoError(t, err)
+		assert.ElementsMatch(t, expected, goLayouts, "input = %s", input)
+	}
+}
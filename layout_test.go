@@ -0,0 +1,854 @@
+package flextime_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ngicks/flextime"
+	optionalstring "github.com/ngicks/flextime/optional_string"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompile(t *testing.T) {
+	l, err := flextime.Compile(`YYYY-MM-DD[THH[:mm[:ss.SSS]]][Z]`)
+	require.NoError(t, err)
+
+	parsed, err := l.Parse("2022-10-20T23:16:22.168+09:00")
+	require.NoError(t, err)
+	assert.True(t, time.Date(2022, time.October, 20, 23, 16, 22, 168000000, jst).Equal(parsed))
+
+	parsed, err = l.ParseInLocation("2022-10-20T23:16", jst)
+	require.NoError(t, err)
+	assert.True(t, time.Date(2022, time.October, 20, 23, 16, 0, 0, jst).Equal(parsed))
+
+	_, err = l.Parse("2022/10/20")
+	var parseErr *time.ParseError
+	assert.ErrorAs(t, err, &parseErr)
+}
+
+func TestParseError(t *testing.T) {
+	_, err := flextime.Parse(`YYYY-MM-DD[THH:mm]`, "2022-10-20T23-16")
+	var flexErr *flextime.ParseError
+	require.ErrorAs(t, err, &flexErr)
+	assert.Equal(t, `YYYY-MM-DD[THH:mm]`, flexErr.Layout)
+	assert.Equal(t, "2022-10-20T23-16", flexErr.Value)
+	assert.Equal(t, []string{"2006-01-02T15:04", "2006-01-02"}, flexErr.Attempted)
+	assert.Contains(t, err.Error(), `"YYYY-MM-DD[THH:mm]"`)
+
+	// the underlying error is the one failed at the latest position of value.
+	var parseErr *time.ParseError
+	require.ErrorAs(t, err, &parseErr)
+	assert.Equal(t, "YYYY-MM-DDTHH:mm", parseErr.Layout)
+	assert.Equal(t, "-16", parseErr.ValueElem)
+
+	// computed candidates are attempted by flextime layouts.
+	_, err = flextime.Parse(`YYYY-Q[-DD]`, "2022-5")
+	require.ErrorAs(t, err, &flexErr)
+	assert.Equal(t, []string{"YYYY-Q-DD", "YYYY-Q"}, flexErr.Attempted)
+
+	l, err := flextime.Compile(`YYYY-MM-DD`)
+	require.NoError(t, err)
+	_, _, err = l.ParseAll("2022/10/20")
+	assert.ErrorAs(t, err, &flexErr)
+	_, err = l.ParseStrict("2022/10/20")
+	assert.ErrorAs(t, err, &flexErr)
+}
+
+func TestParseErrorByFlexTokens(t *testing.T) {
+	_, err := flextime.Parse(`YYYY-MM-DD`, "2022-10-xx")
+	var parseErr *time.ParseError
+	require.ErrorAs(t, err, &parseErr)
+	assert.Equal(t, "YYYY-MM-DD", parseErr.Layout)
+	assert.Equal(t, "DD", parseErr.LayoutElem)
+	assert.Equal(t, "xx", parseErr.ValueElem)
+	assert.Contains(t, err.Error(), `as "DD"`)
+	assert.NotContains(t, err.Error(), `as "02"`)
+
+	_, err = flextime.Parse(`YYYY-MM-DD`, "2022-10-32")
+	require.ErrorAs(t, err, &parseErr)
+	assert.Contains(t, err.Error(), "day out of range")
+
+	_, err = flextime.Parse(`YYYY-MM-DD[ HH:mm]`, "2022-10-20 xx:16")
+	require.ErrorAs(t, err, &parseErr)
+	assert.Equal(t, "HH", parseErr.LayoutElem)
+}
+
+func TestCompileError(t *testing.T) {
+	var syntaxErr *optionalstring.SyntaxError
+	_, err := flextime.Compile(`YYYY-MM-DD[THH`)
+	assert.ErrorAs(t, err, &syntaxErr)
+
+	var formatErr *flextime.FormatError
+	_, err = flextime.Compile(`YYY-MM-DD[THH]`)
+	assert.ErrorAs(t, err, &formatErr)
+}
+
+var mustCompiled = flextime.MustCompile("YYYY-MM-DDTHH:mm:ssZ")
+
+func TestMustCompile(t *testing.T) {
+	parsed, err := mustCompiled.Parse("2022-10-20T23:16:22+09:00")
+	require.NoError(t, err)
+	assert.True(t, time.Date(2022, time.October, 20, 23, 16, 22, 0, jst).Equal(parsed))
+
+	l := flextime.MustCompile(`YYYY-MM-DD[THH]`, flextime.WithLocation(jst))
+	assert.Equal(t, "2022-10-20T23", l.Format(time.Date(2022, time.October, 20, 14, 0, 0, 0, time.UTC)))
+
+	for _, invalid := range []string{`YYYY-MM-DD[THH`, `YYY-MM-DD`} {
+		assert.PanicsWithValue(
+			t,
+			func() string {
+				_, err := flextime.Compile(invalid)
+				return "flextime: Compile(\"" + invalid + "\"): " + err.Error()
+			}(),
+			func() { flextime.MustCompile(invalid) },
+			"layout = %s", invalid,
+		)
+	}
+}
+
+func TestParse(t *testing.T) {
+	parsed, err := flextime.Parse(`YYYY-MM-DD[THH[:mm]]`, "2022-10-20T23:16")
+	require.NoError(t, err)
+	assert.True(t, time.Date(2022, time.October, 20, 23, 16, 0, 0, time.UTC).Equal(parsed))
+
+	parsed, err = flextime.ParseInLocation(`YYYY-MM-DD[THH[:mm]]`, "2022-10-20", jst)
+	require.NoError(t, err)
+	assert.True(t, time.Date(2022, time.October, 20, 0, 0, 0, 0, jst).Equal(parsed))
+}
+
+const benchLayout = `YYYY-MM-DD[THH[:mm[:ss.SSS]]][Z]`
+
+var benchValues = []string{
+	"2022-10-20T23:16:22.168+09:00",
+	"2022-10-20T23:16:22.168",
+	"2022-10-20T23:16",
+	"2022-10-20",
+}
+
+func BenchmarkParse(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_, _ = flextime.Parse(benchLayout, benchValues[i%len(benchValues)])
+	}
+}
+
+// BenchmarkCompileAndParse compiles the layout on every iteration,
+// which is what Parse would cost without its cache.
+func BenchmarkCompileAndParse(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		l, err := flextime.Compile(benchLayout)
+		if err != nil {
+			b.Fatal(err)
+		}
+		_, _ = l.Parse(benchValues[i%len(benchValues)])
+	}
+}
+
+func BenchmarkCompiledParse(b *testing.B) {
+	l, err := flextime.Compile(benchLayout)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = l.Parse(benchValues[i%len(benchValues)])
+	}
+}
+
+func TestParseAlternation(t *testing.T) {
+	l, err := flextime.Compile(`YYYY(-|/)MM(-|/)DD`)
+	require.NoError(t, err)
+	for _, value := range []string{"2022-10-20", "2022/10/20", "2022-10/20"} {
+		parsed, err := l.Parse(value)
+		require.NoError(t, err)
+		assert.True(t, time.Date(2022, time.October, 20, 0, 0, 0, 0, time.UTC).Equal(parsed))
+	}
+}
+
+func TestParseWithLayout(t *testing.T) {
+	cases := []struct {
+		flexLayout string
+		value      string
+		layout     string
+	}{
+		{`YYYY-MM-DD[THH[:mm]]`, "2022-10-20T23:16", "2006-01-02T15:04"},
+		{`YYYY-MM-DD[THH[:mm]]`, "2022-10-20T23", "2006-01-02T15"},
+		{`YYYY-MM-DD[THH[:mm]]`, "2022-10-20", "2006-01-02"},
+		{`YYYY(-|/)MM`, "2022/10", "2006/01"},
+		// computed tokens have no Go reference layout.
+		{`YYYY-Q[-DD]`, "2022-4", "YYYY-Q"},
+	}
+
+	for _, testCase := range cases {
+		parsed, layout, err := flextime.ParseWithLayout(testCase.flexLayout, testCase.value)
+		require.NoError(t, err)
+		assert.Equal(t, testCase.layout, layout, "value = %s", testCase.value)
+		if !strings.ContainsRune(layout, 'Q') {
+			reproduced, err := time.Parse(layout, testCase.value)
+			require.NoError(t, err)
+			assert.True(t, reproduced.Equal(parsed))
+		}
+	}
+
+	_, layout, err := flextime.ParseWithLayout(`YYYY-MM-DD`, "2022/10/20")
+	var parseErr *time.ParseError
+	assert.ErrorAs(t, err, &parseErr)
+	assert.Equal(t, "", layout)
+}
+
+func TestParseMostSpecificFirst(t *testing.T) {
+	l, err := flextime.Compile(`YYYY-MM-DD[THH:mm:ss]`)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"2006-01-02T15:04:05", "2006-01-02"}, l.GoLayouts())
+
+	parsed, layout, err := l.ParseWithLayout("2022-10-20T23:16:22")
+	require.NoError(t, err)
+	assert.Equal(t, "2006-01-02T15:04:05", layout)
+	assert.True(t, time.Date(2022, time.October, 20, 23, 16, 22, 0, time.UTC).Equal(parsed))
+
+	// tokens are counted before lengths: a long literal does not outrank tokens.
+	l, err = flextime.Compile(`[HH:mm]['(start of the day)']`)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"15:04(start of the day)", "15:04", "(start of the day)", ""}, l.GoLayouts())
+}
+
+func TestPreviewExpansion(t *testing.T) {
+	for _, flexLayout := range []string{
+		`YYYY-MM-DD['T'HH[:mm]][Z]`,
+		`[HH:mm]['(start of the day)']`,
+		`(YYYY-MM|MM/YYYY)[-DD]`,
+	} {
+		previewed, err := flextime.PreviewExpansion(flexLayout)
+		require.NoError(t, err)
+		l, err := flextime.Compile(flexLayout)
+		require.NoError(t, err)
+
+		// same order as Parse tries.
+		goLayouts := make([]string, len(previewed))
+		for i, p := range previewed {
+			goLayouts[i], err = flextime.ReplaceTimeToken(p)
+			require.NoError(t, err)
+		}
+		assert.Equal(t, l.GoLayouts(), goLayouts, "layout = %s", flexLayout)
+	}
+
+	previewed, err := flextime.PreviewExpansion(`YYYY-MM[-DD]['T'HH]`)
+	require.NoError(t, err)
+	assert.Equal(
+		t,
+		[]string{`YYYY-MM-DD'T'HH`, `YYYY-MM-DD`, `YYYY-MM'T'HH`, `YYYY-MM`},
+		previewed,
+	)
+
+	// computed tokens are kept.
+	previewed, err = flextime.PreviewExpansion(`Do MMM[ YYYY]`)
+	require.NoError(t, err)
+	assert.Equal(t, []string{`Do MMM YYYY`, `Do MMM`}, previewed)
+
+	// adjacent tokens are separated so that each preview is read as its candidate.
+	previewed, err = flextime.PreviewExpansion(`M[-]MMM`)
+	require.NoError(t, err)
+	assert.Equal(t, []string{`M-MMM`, `M''MMM`}, previewed)
+	expected, err := flextime.Parse(`M[-]MMM`, "10Oct")
+	require.NoError(t, err)
+	parsed, err := flextime.Parse(previewed[1], "10Oct")
+	require.NoError(t, err)
+	assert.True(t, expected.Equal(parsed))
+
+	_, err = flextime.PreviewExpansion(`YYYY-MM[-DD`)
+	assert.Error(t, err)
+}
+
+func TestParseStrict(t *testing.T) {
+	// optional parts disambiguate by length.
+	for _, value := range []string{"2022-10-20", "2022-10-20T23"} {
+		parsed, err := flextime.ParseStrict(`YYYY-MM-DD[THH]`, value)
+		require.NoError(t, err)
+		expected, _ := flextime.Parse(`YYYY-MM-DD[THH]`, value)
+		assert.True(t, expected.Equal(parsed))
+	}
+
+	// candidates agreeing on the instant are not ambiguous.
+	parsed, err := flextime.ParseStrict(`(YYYY-MM-DD|YYYY-MM-dd)`, "2022-10-20")
+	require.NoError(t, err)
+	assert.True(t, time.Date(2022, time.October, 20, 0, 0, 0, 0, time.UTC).Equal(parsed))
+
+	_, err = flextime.ParseStrict(`(MM/DD|DD/MM)/YYYY`, "10/11/2022")
+	var ambiguousErr *flextime.AmbiguousError
+	require.ErrorAs(t, err, &ambiguousErr)
+	assert.Equal(t, []string{"01/02/2006", "02/01/2006"}, ambiguousErr.Layouts)
+	assert.True(t, time.Date(2022, time.October, 11, 0, 0, 0, 0, time.UTC).Equal(ambiguousErr.Times[0]))
+	assert.True(t, time.Date(2022, time.November, 10, 0, 0, 0, 0, time.UTC).Equal(ambiguousErr.Times[1]))
+	assert.Contains(t, err.Error(), "10/11/2022")
+
+	// not ambiguous if only one of them matches.
+	parsed, err = flextime.ParseStrict(`(MM/DD|DD/MM)/YYYY`, "10/13/2022")
+	require.NoError(t, err)
+	assert.True(t, time.Date(2022, time.October, 13, 0, 0, 0, 0, time.UTC).Equal(parsed))
+
+	_, err = flextime.ParseStrict(`YYYY-MM-DD[THH]`, "2022/10/20")
+	var parseErr *time.ParseError
+	assert.ErrorAs(t, err, &parseErr)
+}
+
+func TestParseExact(t *testing.T) {
+	layout := `YYYY-MM-DD[THH[:mm]]`
+
+	// the greedier candidate consuming the entire value wins.
+	parsed, err := flextime.ParseExact(layout, "2022-10-20T23:16")
+	require.NoError(t, err)
+	assert.True(t, time.Date(2022, time.October, 20, 23, 16, 0, 0, time.UTC).Equal(parsed))
+
+	parsed, err = flextime.ParseExact(layout, "2022-10-20")
+	require.NoError(t, err)
+	assert.True(t, time.Date(2022, time.October, 20, 0, 0, 0, 0, time.UTC).Equal(parsed))
+
+	for _, testCase := range []struct {
+		value  string
+		suffix string
+	}{
+		{"2022-10-20T23:16:22", ":22"},
+		{"2022-10-20T23:16Z", "Z"},
+		{"2022-10-20 foo", " foo"},
+	} {
+		_, err := flextime.ParseExact(layout, testCase.value)
+		var extraErr *flextime.ExtraTextError
+		require.ErrorAs(t, err, &extraErr, "value = %s", testCase.value)
+		assert.Equal(t, testCase.suffix, extraErr.Suffix)
+		assert.Equal(t, layout, extraErr.Layout)
+		assert.Equal(t, testCase.value, extraErr.Value)
+		assert.Contains(t, err.Error(), fmt.Sprintf("%q", testCase.suffix))
+	}
+
+	_, err = flextime.ParseExact(layout, "2022/10/20")
+	var parseErr *time.ParseError
+	assert.ErrorAs(t, err, &parseErr)
+	var flexErr *flextime.ParseError
+	require.ErrorAs(t, err, &flexErr)
+	assert.Equal(t, layout, flexErr.Layout)
+	assert.Equal(t, "2022/10/20", flexErr.Value)
+	assert.NotEmpty(t, flexErr.Attempted)
+}
+
+func TestParseAll(t *testing.T) {
+	times, layouts, err := flextime.ParseAll(`(MM/DD|DD/MM|MM/dd)/YYYY`, "10/11/2022")
+	require.NoError(t, err)
+	// MM/dd is collapsed into MM/DD.
+	assert.Equal(t, []string{"01/02/2006", "02/01/2006"}, layouts)
+	require.Len(t, times, 2)
+	assert.True(t, time.Date(2022, time.October, 11, 0, 0, 0, 0, time.UTC).Equal(times[0]))
+	assert.True(t, time.Date(2022, time.November, 10, 0, 0, 0, 0, time.UTC).Equal(times[1]))
+
+	times, layouts, err = flextime.ParseAll(`YYYY-MM-DD[THH[:mm]]`, "2022-10-20T23")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"2006-01-02T15"}, layouts)
+	assert.Len(t, times, 1)
+
+	// the error of the layout which went furthest is returned.
+	_, _, err = flextime.ParseAll(`YYYY-MM-DD[THH[:mm]]`, "2022-10-20T23:xx")
+	var parseErr *time.ParseError
+	require.ErrorAs(t, err, &parseErr)
+	assert.Equal(t, "YYYY-MM-DDTHH:mm", parseErr.Layout)
+	assert.Equal(t, "xx", parseErr.ValueElem)
+}
+
+func TestParseFlexibleSpace(t *testing.T) {
+	expected := time.Date(2022, time.October, 20, 23, 16, 22, 0, time.UTC)
+	layout := `MMM D HH:mm:ss YYYY`
+
+	for _, value := range []string{
+		"Oct 20 23:16:22 2022",
+		"Oct  20 23:16:22   2022",
+		"Oct\t20\t23:16:22 \t 2022",
+		"  Oct 20 23:16:22 2022\t",
+		"\tOct 20\t\t23:16:22 2022\n",
+	} {
+		parsed, err := flextime.ParseFlexibleSpace(layout, value)
+		require.NoError(t, err, "value = %q", value)
+		assert.True(t, expected.Equal(parsed), "value = %q", value)
+
+		_, err = flextime.Parse(layout, value)
+		if strings.ContainsAny(value, "\t\n") {
+			assert.Error(t, err, "value = %q", value)
+		}
+	}
+
+	// whitespace in quoted literals is not collapsed.
+	quoted := `YYYY-MM-DD 'at  'HH`
+	parsed, err := flextime.ParseFlexibleSpace(quoted, "2022-10-20\t at  23")
+	require.NoError(t, err)
+	assert.True(t, time.Date(2022, time.October, 20, 23, 0, 0, 0, time.UTC).Equal(parsed))
+	for _, value := range []string{"2022-10-20 at 23", "2022-10-20 at\t\t23"} {
+		_, err = flextime.ParseFlexibleSpace(quoted, value)
+		assert.Error(t, err, "value = %q", value)
+	}
+
+	_, err = flextime.ParseFlexibleSpace(layout, "Oct20 23:16:22 2022")
+	assert.Error(t, err)
+}
+
+func TestCaseInsensitiveMeridiem(t *testing.T) {
+	pm := time.Date(0, time.January, 1, 15, 4, 0, 0, time.UTC)
+	am := time.Date(0, time.January, 1, 3, 4, 0, 0, time.UTC)
+
+	// a matches lowercase only by default, as Go's pm does.
+	parsed, err := flextime.Parse("h:mm a", "3:04 pm")
+	require.NoError(t, err)
+	assert.True(t, pm.Equal(parsed))
+	_, err = flextime.Parse("h:mm a", "3:04 PM")
+	assert.Error(t, err)
+	_, err = flextime.Parse("h:mm A", "3:04 pm")
+	assert.Error(t, err)
+
+	for _, layout := range []string{"h:mm a", "h:mm A"} {
+		l, err := flextime.Compile(layout, flextime.CaseInsensitiveMeridiem())
+		require.NoError(t, err)
+		for value, expected := range map[string]time.Time{
+			"3:04 pm": pm,
+			"3:04 PM": pm,
+			"3:04 Pm": pm,
+			"3:04 am": am,
+			"3:04 AM": am,
+		} {
+			parsed, err := l.Parse(value)
+			require.NoError(t, err, "layout = %s, value = %s", layout, value)
+			assert.True(t, expected.Equal(parsed), "layout = %s, value = %s", layout, value)
+		}
+		_, err = l.Parse("3:04 xm")
+		assert.Error(t, err)
+	}
+}
+
+func TestAllowOffsetPrefix(t *testing.T) {
+	_, err := flextime.Parse("YYYY-MM-DDTHH:mmZ", "2022-10-20T23:16UTC+09:00")
+	assert.Error(t, err)
+
+	cases := []struct {
+		flexLayout string
+		value      string
+		offset     int
+	}{
+		{"YYYY-MM-DDTHH:mmZ", "2022-10-20T23:16UTC+09:00", 9 * 60 * 60},
+		{"YYYY-MM-DDTHH:mm -0700", "2022-10-20T23:16 GMT-0500", -5 * 60 * 60},
+		{"YYYY-MM-DDTHH:mmZ", "2022-10-20T23:16+09:00", 9 * 60 * 60},
+		{"YYYY-MM-DDTHH:mmZ", "2022-10-20T23:16Z", 0},
+		// prefix is stripped also for computed layouts.
+		{"YYYY-MM-DD Do HH:mm[ ZZ]", "2022-10-20 20th 23:16 GMT+0900", 9 * 60 * 60},
+	}
+
+	for _, testCase := range cases {
+		l, err := flextime.Compile(testCase.flexLayout, flextime.AllowOffsetPrefix())
+		require.NoError(t, err)
+		parsed, err := l.Parse(testCase.value)
+		require.NoError(t, err, "value = %s", testCase.value)
+		_, offset := parsed.Zone()
+		assert.Equal(t, testCase.offset, offset, "value = %s", testCase.value)
+		assert.True(t, time.Date(2022, time.October, 20, 23, 16, 0, 0, time.FixedZone("", testCase.offset)).Equal(parsed))
+	}
+
+	l, err := flextime.Compile("HH:mmZ", flextime.AllowOffsetPrefix())
+	require.NoError(t, err)
+	for _, invalid := range []string{"23:16UTC", "23:16UTC09:00", "23:16JST+09:00"} {
+		_, err = l.Parse(invalid)
+		assert.Error(t, err, "value = %s", invalid)
+	}
+}
+
+func TestIgnoreWeekday(t *testing.T) {
+	// 2022-10-20 is Thursday.
+	expected := time.Date(2022, time.October, 20, 23, 16, 0, 0, time.UTC)
+
+	_, err := flextime.Parse("YYYY-MM-DD HH:mm c", "2022-10-20 23:16 1")
+	assert.Error(t, err)
+	_, err = flextime.Parse("w, DD MMM YYYY HH:mm", "Thurs, 20 Oct 2022 23:16")
+	assert.Error(t, err)
+
+	cases := []struct {
+		flexLayout string
+		value      string
+	}{
+		{"YYYY-MM-DD HH:mm c", "2022-10-20 23:16 1"},
+		{"YYYY-MM-DD HH:mm E", "2022-10-20 23:16 7"},
+		{"w, DD MMM YYYY HH:mm", "Mon, 20 Oct 2022 23:16"},
+		{"w, DD MMM YYYY HH:mm", "Thurs, 20 Oct 2022 23:16"},
+		{"ww, DD MMM YYYY HH:mm", "Funday, 20 Oct 2022 23:16"},
+		{"WEEKDAY DD MMM YYYY HH:mm", "MONDAY 20 Oct 2022 23:16"},
+		{"[w, ]DD MMM YYYY HH:mm", "Sat, 20 Oct 2022 23:16"},
+		{"[w, ]DD MMM YYYY HH:mm", "20 Oct 2022 23:16"},
+	}
+	for _, testCase := range cases {
+		l, err := flextime.Compile(testCase.flexLayout, flextime.IgnoreWeekday())
+		require.NoError(t, err)
+		parsed, err := l.Parse(testCase.value)
+		require.NoError(t, err, "layout = %s, value = %s", testCase.flexLayout, testCase.value)
+		assert.True(t, expected.Equal(parsed), "layout = %s, value = %s, parsed = %s", testCase.flexLayout, testCase.value, parsed)
+	}
+
+	l, err := flextime.Compile("w, DD MMM YYYY HH:mm", flextime.IgnoreWeekday())
+	require.NoError(t, err)
+	for _, invalid := range []string{", 20 Oct 2022 23:16", "4, 20 Oct 2022 23:16"} {
+		_, err = l.Parse(invalid)
+		assert.Error(t, err, "value = %s", invalid)
+	}
+	assert.Equal(t, "Thu, 20 Oct 2022 23:16", l.Format(expected))
+}
+
+func TestLiteralUnknownTokens(t *testing.T) {
+	target := time.Date(2022, time.October, 20, 23, 16, 0, 0, time.UTC)
+	flexLayout := "Y YYYY, 'at' HH:mm[ Y]"
+
+	// strict by default.
+	_, err := flextime.Compile(flexLayout)
+	var formatErr *flextime.FormatError
+	assert.ErrorAs(t, err, &formatErr)
+
+	l, err := flextime.Compile(flexLayout, flextime.LiteralUnknownTokens())
+	require.NoError(t, err)
+	assert.Equal(t, "Y 2022, at 23:16 Y", l.Format(target))
+	for _, value := range []string{"Y 2022, at 23:16 Y", "Y 2022, at 23:16"} {
+		parsed, err := l.Parse(value)
+		require.NoError(t, err, value)
+		assert.True(t, time.Date(2022, time.January, 1, 23, 16, 0, 0, time.UTC).Equal(parsed), parsed)
+	}
+
+	// known tokens are still tokens, even a part of an unknown one.
+	l, err = flextime.Compile("YYY", flextime.LiteralUnknownTokens())
+	require.NoError(t, err)
+	assert.Equal(t, "22Y", l.Format(target))
+
+	// other errors are not affected.
+	_, err = flextime.Compile("YYYY'", flextime.LiteralUnknownTokens())
+	assert.Error(t, err)
+}
+
+func TestRelaxYear(t *testing.T) {
+	expected := time.Date(2022, time.October, 20, 23, 16, 0, 0, time.UTC)
+
+	l, err := flextime.Compile("YYYY-MM-DD[ HH:mm]", flextime.RelaxYear())
+	require.NoError(t, err)
+	for _, value := range []string{"2022-10-20 23:16", "22-10-20 23:16"} {
+		parsed, layout, err := l.ParseWithLayout(value)
+		require.NoError(t, err, value)
+		assert.True(t, expected.Equal(parsed), "value = %s, parsed = %s", value, parsed)
+		if strings.HasPrefix(value, "2022") {
+			assert.Equal(t, "2006-01-02 15:04", layout)
+		} else {
+			assert.Equal(t, "06-01-02 15:04", layout)
+		}
+	}
+	parsed, err := l.Parse("99-10-20")
+	require.NoError(t, err)
+	assert.Equal(t, 1999, parsed.Year())
+
+	// 4 digit years are preferred, and Format is not affected.
+	assert.Equal(t, "2022-10-20 23:16", l.Format(expected))
+	parsed, err = l.Parse("1922-10-20")
+	require.NoError(t, err)
+	assert.Equal(t, 1922, parsed.Year())
+
+	// computed tokens are kept.
+	l, err = flextime.Compile("Do MMM yyyy", flextime.RelaxYear())
+	require.NoError(t, err)
+	parsed, err = l.Parse("20th Oct 22")
+	require.NoError(t, err)
+	assert.True(t, time.Date(2022, time.October, 20, 0, 0, 0, 0, time.UTC).Equal(parsed), parsed)
+
+	_, err = flextime.Parse("YYYY-MM-DD", "22-10-20")
+	assert.Error(t, err)
+	_, err = l.Parse("2 Oct 22")
+	assert.Error(t, err)
+}
+
+func TestValid(t *testing.T) {
+	for _, testCase := range []struct {
+		flexLayout string
+		opts       []flextime.Option
+		values     []string
+	}{
+		{
+			flexLayout: "YYYY-MM-DD[THH:mm[:ss]][Z]",
+			values: []string{
+				"2022-10-20", "2022-10-20T23:16", "2022-10-20T23:16:22+09:00",
+				"2022-10-20T", "2022-13-20", "20221020", "",
+			},
+		},
+		{
+			// DDD is checked against MM-DD.
+			flexLayout: "YYYY-DDD[ MM-DD]",
+			values:     []string{"2022-293", "2022-293 10-20", "2022-293 10-21"},
+		},
+		{
+			flexLayout: "ww, DD MMM YYYY",
+			opts:       []flextime.Option{flextime.IgnoreWeekday()},
+			values:     []string{"Fri, 20 Oct 2022", "Thursday, 20 Oct 2022", "20 Oct 2022"},
+		},
+	} {
+		l, err := flextime.Compile(testCase.flexLayout, testCase.opts...)
+		require.NoError(t, err)
+		for _, value := range testCase.values {
+			_, parseErr := l.Parse(value)
+			assert.Equal(t, parseErr == nil, l.Valid(value), "layout = %s, value = %s", testCase.flexLayout, value)
+		}
+	}
+
+	l := flextime.MustCompile("YYYY-MM-DD")
+	assert.True(t, l.Valid("2022-10-20"))
+	assert.False(t, l.Valid("2022-10-20 "))
+}
+
+func TestParseLeadingFraction(t *testing.T) {
+	for _, layout := range []string{".000", ".SSS", "[.SSS]", ".999"} {
+		parsed, err := flextime.Parse(layout, ".012")
+		require.NoError(t, err, "layout = %s", layout)
+		assert.Equal(t, 12000000, parsed.Nanosecond(), "layout = %s", layout)
+	}
+
+	for _, layout := range []string{".000", ".SSS", ".999"} {
+		formatted, err := flextime.Format(time.Date(2022, 1, 1, 0, 0, 0, 12000000, time.UTC), layout)
+		require.NoError(t, err)
+		assert.Equal(t, ".012", formatted, "layout = %s", layout)
+	}
+}
+
+func TestLayoutFormat(t *testing.T) {
+	target := time.Date(2022, time.October, 20, 14, 16, 22, 0, time.UTC)
+
+	l, err := flextime.Compile(`YYYY-MM-DD[THH[:mm]](Z|MST)`)
+	require.NoError(t, err)
+	assert.Equal(t, "2022-10-20T14:16Z", l.Format(target))
+	assert.Equal(t, "prefix:2022-10-20T14:16Z", string(l.AppendFormat([]byte("prefix:"), target)))
+
+	l, err = flextime.Compile(`YYYY-MM-DD HH:mm Z`, flextime.WithLocation(jst))
+	require.NoError(t, err)
+	formatted := l.Format(target)
+	assert.Equal(t, "2022-10-20 23:16 +09:00", formatted)
+
+	// WithLocation does not affect Parse.
+	parsed, err := l.Parse("2022-10-20 23:16 +09:00")
+	require.NoError(t, err)
+	assert.True(t, target.Truncate(time.Minute).Equal(parsed))
+	_, offset := parsed.Zone()
+	assert.Equal(t, 9*3600, offset)
+	parsed, err = l.ParseInLocation("2022-10-20 23:16 Z", time.UTC)
+	require.NoError(t, err)
+	assert.Equal(t, time.UTC, parsed.Location())
+
+	formatted, err = flextime.FormatIn(target, `YYYY-MM-DD HH:mm MST`, jst)
+	require.NoError(t, err)
+	assert.Equal(t, "2022-10-20 23:16 JST", formatted)
+}
+
+func TestAcceptLeapSecond(t *testing.T) {
+	lastInstant := time.Date(2016, time.December, 31, 23, 59, 59, 999999999, time.UTC)
+
+	_, err := flextime.Parse(`YYYY-MM-DDTHH:mm:ss[.SSS]Z`, "2016-12-31T23:59:60Z")
+	assert.Error(t, err)
+
+	l, err := flextime.Compile(`YYYY-MM-DDTHH:mm:ss[.SSS]Z`, flextime.AcceptLeapSecond())
+	require.NoError(t, err)
+	for _, value := range []string{"2016-12-31T23:59:60Z", "2016-12-31T23:59:60.500Z"} {
+		parsed, err := l.Parse(value)
+		require.NoError(t, err, "value = %s", value)
+		assert.True(t, lastInstant.Equal(parsed), "value = %s, parsed = %s", value, parsed)
+	}
+
+	parsed, err := l.Parse("2017-01-01T08:59:60+09:00")
+	require.NoError(t, err)
+	assert.True(t, lastInstant.Equal(parsed), "parsed = %s", parsed)
+
+	// other seconds are parsed as usual.
+	parsed, err = l.Parse("2016-12-31T23:59:59Z")
+	require.NoError(t, err)
+	assert.True(t, lastInstant.Truncate(time.Second).Equal(parsed))
+	_, err = l.Parse("2016-12-31T23:59:61Z")
+	assert.Error(t, err)
+}
+
+func TestTrimZeroOptionals(t *testing.T) {
+	date := func(hour, min, sec, nsec int) time.Time {
+		return time.Date(2022, time.October, 20, hour, min, sec, nsec, time.UTC)
+	}
+	type testCase struct {
+		layout   string
+		input    time.Time
+		expected string
+	}
+	for _, tc := range []testCase{
+		{`HH:mm[:ss]`, date(10, 30, 0, 0), "10:30"},
+		{`HH:mm[:ss]`, date(10, 30, 15, 0), "10:30:15"},
+		{`HH:mm[:ss]`, date(0, 0, 0, 0), "00:00"},
+		{`YYYY-MM-DD[THH:mm[:ss[.SSS]]]`, date(0, 0, 0, 0), "2022-10-20"},
+		{`YYYY-MM-DD[THH:mm[:ss[.SSS]]]`, date(12, 0, 0, 0), "2022-10-20T12:00"},
+		{`YYYY-MM-DD[THH:mm[:ss[.SSS]]]`, date(0, 0, 5, 0), "2022-10-20T00:00:05"},
+		{`YYYY-MM-DD[THH:mm[:ss[.SSS]]]`, date(0, 0, 0, 1e6), "2022-10-20T00:00:00.001"},
+		// a zone is never zero.
+		{`YYYY-MM-DD[THH:mm Z]`, date(0, 0, 0, 0), "2022-10-20T00:00 Z"},
+		// an optional part without tokens is always present.
+		{`HH:mm['Z']`, date(0, 0, 0, 0), "00:00Z"},
+		{`YYYY-MM-DD[ sod]`, date(0, 0, 0, 0), "2022-10-20"},
+		{`YYYY-MM-DD[ sod]`, date(0, 0, 1, 0), "2022-10-20 1"},
+		// the first branch of an alternation is taken.
+		{`HH:mm[(:|.)ss]`, date(10, 30, 15, 0), "10:30:15"},
+		{`HH:mm[(:|.)ss]`, date(10, 30, 0, 0), "10:30"},
+		// nested optional parts are kept if any of them is not zero.
+		{`YYYY-MM-DD[ HH[:mm]]`, date(0, 30, 0, 0), "2022-10-20 00:30"},
+		// only trailing optional parts are omitted.
+		{`HH:mm[:ss][.SSS]`, date(10, 0, 0, 5e6), "10:00:00.005"},
+		{`HH:mm[:ss][.SSS]`, date(10, 0, 5, 0), "10:00:05"},
+		{`HH:mm[:ss][.SSS]`, date(10, 0, 0, 0), "10:00"},
+		// fractional seconds are zero in the digits of the token.
+		{`HH:mm[:ss[.SSS]]`, date(10, 0, 5, 100), "10:00:05"},
+		{`HH:mm[:ss[.SSSSSSSSS]]`, date(10, 0, 5, 100), "10:00:05.000000100"},
+	} {
+		l, err := flextime.Compile(tc.layout, flextime.TrimZeroOptionals())
+		require.NoError(t, err)
+		assert.Equal(t, tc.expected, l.Format(tc.input), "layout = %s, input = %s", tc.layout, tc.input)
+	}
+
+	// all optional parts are present without the option.
+	l, err := flextime.Compile(`HH:mm[:ss]`)
+	require.NoError(t, err)
+	assert.Equal(t, "10:30:00", l.Format(date(10, 30, 0, 0)))
+
+	// Parse is not affected.
+	l, err = flextime.Compile(`HH:mm[:ss]`, flextime.TrimZeroOptionals())
+	require.NoError(t, err)
+	parsed, err := l.Parse("10:30:15")
+	require.NoError(t, err)
+	assert.Equal(t, 15, parsed.Second())
+}
+
+func TestParseDayOfYearMismatch(t *testing.T) {
+	// 2022-293 is 2022-10-20.
+	expected := time.Date(2022, time.October, 20, 0, 0, 0, 0, time.UTC)
+
+	for _, layout := range []string{`YYYY-DDD MM-dd`, `YYYY-ddd MM-DD`, `YYYY-DDD[/MM-dd]`} {
+		value := "2022-293 10-20"
+		if strings.Contains(layout, "/") {
+			value = "2022-293/10-20"
+		}
+		parsed, err := flextime.Parse(layout, value)
+		require.NoError(t, err)
+		assert.True(t, expected.Equal(parsed))
+	}
+
+	var parseErr *time.ParseError
+	_, err := flextime.Parse(`YYYY-DDD MM-dd`, "2022-293 10-21")
+	require.ErrorAs(t, err, &parseErr)
+	assert.Equal(t, `YYYY-DDD MM-dd`, parseErr.Layout)
+	assert.Contains(t, err.Error(), "day-of-year DDD does not match day")
+
+	_, err = flextime.Parse(`YYYY-ddd MM-DD`, "2022-293 11-20")
+	require.ErrorAs(t, err, &parseErr)
+	assert.Contains(t, err.Error(), "day-of-year ddd does not match month")
+
+	// space padded day of year is named as well.
+	_, err = flextime.Parse(`YYYY-MM-DD __D`, "2023-01-02 100")
+	require.ErrorAs(t, err, &parseErr)
+	assert.Contains(t, err.Error(), "day-of-year __D does not match month")
+
+	// the mismatch is reported instead of the error of other enumerated layouts.
+	_, err = flextime.Parse(`YYYY-DDD[/MM-dd]`, "2022-293/10-21")
+	require.ErrorAs(t, err, &parseErr)
+	assert.Contains(t, err.Error(), "day-of-year DDD does not match day")
+}
+
+func TestParseOptionalWithLeadingSpace(t *testing.T) {
+	layout, err := flextime.Compile(`YYYY-MM-DD[ HH:mm]' at 'ss`)
+	require.NoError(t, err)
+
+	parsed, err := layout.Parse("2022-10-20 23:16 at 22")
+	require.NoError(t, err)
+	assert.True(t, time.Date(2022, time.October, 20, 23, 16, 22, 0, time.UTC).Equal(parsed))
+
+	parsed, err = layout.Parse("2022-10-20 at 22")
+	require.NoError(t, err)
+	assert.True(t, time.Date(2022, time.October, 20, 0, 0, 22, 0, time.UTC).Equal(parsed))
+}
+
+func TestParseHyphenSeparator(t *testing.T) {
+	parsed, err := flextime.Parse(`YYYY-MM-DD-HH`, "2022-10-20-23")
+	require.NoError(t, err)
+	assert.True(t, time.Date(2022, time.October, 20, 23, 0, 0, 0, time.UTC).Equal(parsed))
+	formatted, err := flextime.Format(parsed, `YYYY-MM-DD-HH`)
+	require.NoError(t, err)
+	assert.Equal(t, "2022-10-20-23", formatted)
+
+	// escaped literals are never read as an offset, although their Go reference layout is 2006-07.
+	for _, layout := range []string{`YYYY'-07'`, `YYYY\-07`} {
+		parsed, err = flextime.Parse(layout, "2022-07")
+		require.NoError(t, err, layout)
+		assert.Equal(t, time.Date(2022, time.January, 1, 0, 0, 0, 0, time.UTC), parsed, layout)
+	}
+	// nor a quoted 0 followed by D as 02.
+	parsed, err = flextime.Parse(`MM-'0'D`, "10-020")
+	require.NoError(t, err)
+	assert.True(t, time.Date(0, time.October, 20, 0, 0, 0, 0, time.UTC).Equal(parsed))
+}
+
+func TestParseAny(t *testing.T) {
+	layouts := []string{
+		`YYYY-MM-DD[THH:mm[:ss]]`,
+		`MM/DD/YYYY`,
+		`DD/MM/YYYY`,
+		`X`,
+	}
+
+	for _, testCase := range []struct {
+		value    string
+		expected time.Time
+		layout   string
+	}{
+		{"2022-10-20T23:16", time.Date(2022, time.October, 20, 23, 16, 0, 0, time.UTC), layouts[0]},
+		// order is honored: MM/DD/YYYY wins over DD/MM/YYYY.
+		{"10/11/2022", time.Date(2022, time.October, 11, 0, 0, 0, 0, time.UTC), layouts[1]},
+		{"20/10/2022", time.Date(2022, time.October, 20, 0, 0, 0, 0, time.UTC), layouts[2]},
+		{"1666275382", time.Unix(1666275382, 0), layouts[3]},
+	} {
+		parsed, layout, err := flextime.ParseAny(layouts, testCase.value)
+		require.NoError(t, err, "value = %s", testCase.value)
+		assert.True(t, testCase.expected.Equal(parsed), "value = %s, parsed = %s", testCase.value, parsed)
+		assert.Equal(t, testCase.layout, layout)
+	}
+
+	_, _, err := flextime.ParseAny(append([]string{"YYY"}, layouts...), "20.10.2022")
+	var parseAnyErr *flextime.ParseAnyError
+	require.ErrorAs(t, err, &parseAnyErr)
+	assert.Len(t, parseAnyErr.Errs, 5)
+	assert.Equal(t, "20.10.2022", parseAnyErr.Value)
+	var formatErr *flextime.FormatError
+	assert.ErrorAs(t, parseAnyErr.Errs[0], &formatErr)
+	var parseErr *time.ParseError
+	assert.ErrorAs(t, parseAnyErr.Errs[1], &parseErr)
+	for _, layout := range layouts {
+		assert.Contains(t, err.Error(), layout)
+	}
+}
+
+func TestParseContext(t *testing.T) {
+	parsed, err := flextime.ParseContext(context.Background(), `YYYY-MM-DD[THH:mm]`, "2022-10-20T23:16")
+	require.NoError(t, err)
+	assert.True(t, time.Date(2022, time.October, 20, 23, 16, 0, 0, time.UTC).Equal(parsed))
+
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	layout, err := flextime.Compile(`YYYY-MM-DD[THH:mm]`)
+	require.NoError(t, err)
+	_, err = layout.ParseContext(canceled, "2022-10-20T23:16")
+	assert.ErrorIs(t, err, context.Canceled)
+
+	// 2^40 combinations can not be enumerated in time.
+	pathological := strings.Repeat("[a]", 40)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err = flextime.ParseContext(ctx, pathological, "aaa")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), 5*time.Second)
+}
@@ -0,0 +1,52 @@
+package flextime
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrValueTooLong is returned from Parse and its variants when the value is longer than the limit set by MaxValueLen.
+	ErrValueTooLong = errors.New("flextime: value too long")
+	// ErrLayoutTooLong is returned from Compile when the layout is longer than the limit set by MaxLayoutLen.
+	ErrLayoutTooLong = errors.New("flextime: layout too long")
+)
+
+// MaxValueLen makes Parse and its variants reject values longer than n bytes
+// with an error wrapping ErrValueTooLong before trying any layout,
+// so that parsing untrusted values costs a bounded amount of work.
+// The error does not contain the value. Valid reports false for such values.
+// FindTime and Scanner are not affected since they parse a prefix of the text.
+// n <= 0 means no limit, the default.
+func MaxValueLen(n int) Option {
+	return func(l *Layout) {
+		l.maxValueLen = n
+	}
+}
+
+// MaxLayoutLen makes Compile reject source layouts longer than n bytes
+// with an error wrapping ErrLayoutTooLong before enumerating optional parts,
+// so that compiling untrusted layouts costs a bounded amount of work.
+// Note that a short layout may still expand to many layouts, e.g. [a][b][c]..., see ParseContext.
+// n <= 0 means no limit, the default.
+func MaxLayoutLen(n int) Option {
+	return func(l *Layout) {
+		l.maxLayoutLen = n
+	}
+}
+
+// checkValueLen returns an error wrapping ErrValueTooLong if value exceeds the limit set by MaxValueLen.
+func (l *Layout) checkValueLen(value string) error {
+	if l.maxValueLen > 0 && len(value) > l.maxValueLen {
+		return fmt.Errorf("%w: %d bytes, limit is %d", ErrValueTooLong, len(value), l.maxValueLen)
+	}
+	return nil
+}
+
+// checkLayoutLen returns an error wrapping ErrLayoutTooLong if the source layout exceeds the limit set by MaxLayoutLen.
+func (l *Layout) checkLayoutLen() error {
+	if l.maxLayoutLen > 0 && len(l.flexLayout) > l.maxLayoutLen {
+		return fmt.Errorf("%w: %d bytes, limit is %d", ErrLayoutTooLong, len(l.flexLayout), l.maxLayoutLen)
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+package flextime_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ngicks/flextime"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMaxValueLen(t *testing.T) {
+	l, err := flextime.Compile(`YYYY-MM-DD[THH:mm:ss]`, flextime.MaxValueLen(19))
+	require.NoError(t, err)
+
+	for _, value := range []string{"2022-10-20", "2022-10-20T23:16:22"} {
+		_, err := l.Parse(value)
+		assert.NoError(t, err, "value = %s", value)
+		assert.True(t, l.Valid(value), "value = %s", value)
+	}
+
+	long := "2022-10-20T23:16:22" + strings.Repeat("0", 1<<20)
+	_, err = l.Parse(long)
+	assert.ErrorIs(t, err, flextime.ErrValueTooLong)
+	assert.Less(t, len(err.Error()), 100)
+	_, _, err = l.ParseAll(long)
+	assert.ErrorIs(t, err, flextime.ErrValueTooLong)
+	_, err = l.ParseExact(long)
+	assert.ErrorIs(t, err, flextime.ErrValueTooLong)
+	assert.False(t, l.Valid(long))
+
+	// a value under the limit proceeds to parsing and fails as usual.
+	_, err = l.Parse("2022-10-20T")
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, flextime.ErrValueTooLong)
+}
+
+func TestMaxLayoutLen(t *testing.T) {
+	_, err := flextime.Compile(`YYYY-MM-DD`, flextime.MaxLayoutLen(10))
+	assert.NoError(t, err)
+
+	_, err = flextime.Compile(strings.Repeat("[YYYY]", 1000), flextime.MaxLayoutLen(10))
+	assert.ErrorIs(t, err, flextime.ErrLayoutTooLong)
+}
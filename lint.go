@@ -0,0 +1,358 @@
+package flextime
+
+import (
+	"fmt"
+	"strings"
+
+	optionalstring "github.com/ngicks/flextime/optional_string"
+)
+
+// LintWarning is a possible mistake in a flextime layout reported by Lint.
+type LintWarning struct {
+	// Offset is the byte offset of the token the warning is about.
+	Offset  int
+	Message string
+}
+
+func (w LintWarning) String() string {
+	return fmt.Sprintf("offset [%d]: %s", w.Offset, w.Message)
+}
+
+// fieldKinds maps tokens to the fields of time they set.
+// Tokens which set the same field must not be used together.
+var fieldKinds = map[timeFormatToken]string{
+	"YYYYYY": "year", "YYYY": "year", "yyyy": "year", "YY": "year", "yy": "year",
+	"MMMM": "month", "MMM": "month", "MON": "month", "MONTH": "month", "MM": "month", "M": "month",
+	"DD": "day of month", "dd": "day of month", "D": "day of month", "d": "day of month", "Do": "day of month",
+	"_D": "day of month", "_d": "day of month",
+	"DDD": "day of year", "ddd": "day of year", "__D": "day of year", "__d": "day of year",
+	"ww": "day of week", "w": "day of week", "WEEKDAY": "day of week", "E": "day of week", "c": "day of week", "e": "day of week",
+	"HH": "hour", "H": "hour", "hh": "hour", "h": "hour",
+	"mm": "minute", "m": "minute",
+	"ss": "second", "s": "second",
+	"sod": "time of day", "msod": "time of day", "mod": "time of day",
+	"SSS": "fractional second", "SSSSSS": "fractional second", "SSSSSSSSS": "fractional second",
+	"A": "AM/PM", "a": "AM/PM", "aa": "AM/PM",
+	"Q": "quarter", "QQ": "quarter",
+	"W":  "week of month",
+	"WW": "ISO week", "GGGG": "ISO year",
+	"G": "era",
+	"X": "epoch", "x": "epoch",
+	"MST": "time zone", "zzzz": "time zone", "zz": "time zone", "zm": "time zone", "zs": "time zone",
+	"Z": "time zone", "ZZ": "time zone", "Z07": "time zone", "Z070000": "time zone", "Z07:00:00": "time zone",
+	"Z0700": "time zone", "Z07:00": "time zone", "ZZZ": "time zone",
+	"-07": "time zone", "-0700": "time zone", "-07:00": "time zone", "-070000": "time zone", "-07:00:00": "time zone",
+}
+
+func fieldKindOf(token timeFormatToken) string {
+	if isFracToken(token) {
+		return "fractional second"
+	}
+	return fieldKinds[token]
+}
+
+// lintFrame is an optional part or a branch of an alternation enclosing a token.
+type lintFrame struct {
+	id       int
+	optional bool
+	// branch is the index of the branch if the frame is an alternation.
+	branch int
+}
+
+type lintToken struct {
+	Token
+	frames []lintFrame
+}
+
+// coOccurs reports whether t and other are always present together in enumerated layouts:
+// they are enclosed by the same optional parts and not in different branches of an alternation.
+func (t lintToken) coOccurs(other lintToken) bool {
+	var optionals, otherOptionals []int
+	for _, f := range t.frames {
+		if f.optional {
+			optionals = append(optionals, f.id)
+			continue
+		}
+		for _, o := range other.frames {
+			if !o.optional && o.id == f.id && o.branch != f.branch {
+				return false
+			}
+		}
+	}
+	for _, f := range other.frames {
+		if f.optional {
+			otherOptionals = append(otherOptionals, f.id)
+		}
+	}
+	if len(optionals) != len(otherOptionals) {
+		return false
+	}
+	for i := range optionals {
+		if optionals[i] != otherOptionals[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Lint reports possible mistakes in flexLayout, based on Tokenize.
+//
+// It reports:
+//   - tokens setting the same field, e.g. YYYY and YY, which are always present together.
+//     Tokens in different optional parts or in different branches of an alternation are not reported,
+//     since `[YY]YY` or `(YYYY|YY)` is intended.
+//   - epoch tokens, X and x, used with other tokens.
+//   - 12-hour clock tokens, h and hh, without AM/PM token, and AM/PM token without 12-hour clock tokens.
+//   - digits which look like Go reference layout, see DetectGoLayoutConfusion.
+//
+// If flexLayout can not be tokenized, it returns a warning with the message of *FormatError.
+func Lint(flexLayout string) []LintWarning {
+	tokens, err := Tokenize(flexLayout)
+	if err != nil {
+		if formatErr, ok := err.(*FormatError); ok {
+			return []LintWarning{{Offset: formatErr.idx, Message: err.Error()}}
+		}
+		return []LintWarning{{Message: err.Error()}}
+	}
+
+	var warnings []LintWarning
+	var timeTokens []lintToken
+	var stack []lintFrame
+	var nextID int
+	for _, token := range tokens {
+		if !token.IsTimeToken {
+			if strings.HasPrefix(token.Raw, "'") || strings.HasPrefix(token.Raw, `\`) {
+				continue
+			}
+			for _, c := range token.Raw {
+				switch c {
+				case '[', '(':
+					stack = append(stack, lintFrame{id: nextID, optional: c == '['})
+					nextID++
+				case '|':
+					if len(stack) > 0 && !stack[len(stack)-1].optional {
+						stack[len(stack)-1].branch++
+					}
+				case ']', ')':
+					if len(stack) > 0 {
+						stack = stack[:len(stack)-1]
+					}
+				}
+			}
+			continue
+		}
+		timeTokens = append(timeTokens, lintToken{Token: token, frames: append([]lintFrame(nil), stack...)})
+	}
+
+	var hasHour12, hasMeridiem bool
+	for i, token := range timeTokens {
+		kind := fieldKindOf(timeFormatToken(token.Value))
+		switch token.Value {
+		case "h", "hh":
+			hasHour12 = true
+		case "A", "a", "aa":
+			hasMeridiem = true
+		}
+		for _, prev := range timeTokens[:i] {
+			if !token.coOccurs(prev) {
+				continue
+			}
+			prevKind := fieldKindOf(timeFormatToken(prev.Value))
+			if kind == "epoch" || prevKind == "epoch" {
+				warnings = append(warnings, LintWarning{
+					Offset: token.Offset,
+					Message: fmt.Sprintf(
+						"%s is used with %s at offset %d. epoch represents a whole instant by itself",
+						token.Value, prev.Value, prev.Offset,
+					),
+				})
+				break
+			}
+			if kind != "" && kind == prevKind {
+				warnings = append(warnings, LintWarning{
+					Offset: token.Offset,
+					Message: fmt.Sprintf(
+						"duplicate %s: %s and %s at offset %d set the same field",
+						kind, token.Value, prev.Value, prev.Offset,
+					),
+				})
+				break
+			}
+		}
+	}
+
+	for _, token := range timeTokens {
+		switch {
+		case (token.Value == "h" || token.Value == "hh") && !hasMeridiem:
+			warnings = append(warnings, LintWarning{
+				Offset:  token.Offset,
+				Message: fmt.Sprintf("12-hour clock %s is used without AM/PM token, A, a or aa", token.Value),
+			})
+		case (token.Value == "A" || token.Value == "a" || token.Value == "aa") && !hasHour12:
+			warnings = append(warnings, LintWarning{
+				Offset:  token.Offset,
+				Message: fmt.Sprintf("AM/PM token %s is used without 12-hour clock, h or hh", token.Value),
+			})
+		}
+	}
+	warnings = append(warnings, DetectGoLayoutConfusion(flexLayout)...)
+	return warnings
+}
+
+// confusableStd are Go reference layout chunks of 2 or more digits.
+// A run of digits entirely made of them in a flextime layout is likely a Go reference layout pasted by mistake.
+// Single digit chunks, e.g. 1 or 2, are excluded since they are common in literals.
+var confusableStd = map[int]bool{
+	StdLongYear: true, StdYear: true, StdZeroMonth: true, StdZeroDay: true, StdZeroYearDay: true,
+	StdHour: true, StdZeroHour12: true, StdZeroMinute: true, StdZeroSecond: true,
+}
+
+// DetectGoLayoutConfusion reports runs of digits in literals of flexLayout which look like Go reference layout,
+// e.g. 2006 or 15:04:05, along with their flextime equivalents.
+// Digits are not tokens of flextime layouts, thus they are silently literal text.
+// Quoted and backslash escaped literals are not reported.
+// Use ToFlexLayout to convert a whole Go reference layout.
+//
+// If flexLayout can not be tokenized, it returns nil. Lint reports the error.
+func DetectGoLayoutConfusion(flexLayout string) []LintWarning {
+	tokens, err := Tokenize(flexLayout)
+	if err != nil {
+		return nil
+	}
+	var warnings []LintWarning
+	for _, token := range tokens {
+		if token.IsTimeToken || strings.HasPrefix(token.Raw, "'") || strings.HasPrefix(token.Raw, `\`) {
+			continue
+		}
+		raw := token.Raw
+		for i := 0; i < len(raw); {
+			if !isDigit(raw, i) {
+				i++
+				continue
+			}
+			end := i
+			for end < len(raw) && isDigit(raw, end) {
+				end++
+			}
+			if suggestion, ok := flexEquivalentOfDigits(raw[i:end]); ok {
+				warnings = append(warnings, LintWarning{
+					Offset: token.Offset + i,
+					Message: fmt.Sprintf(
+						"%s looks like Go reference layout but is literal text. use %s",
+						raw[i:end], suggestion,
+					),
+				})
+			}
+			i = end
+		}
+	}
+	return warnings
+}
+
+// flexEquivalentOfDigits converts digits into flextime tokens
+// if it is entirely made of chunks in confusableStd.
+func flexEquivalentOfDigits(digits string) (string, bool) {
+	var flex strings.Builder
+	for rest := digits; rest != ""; {
+		prefix, std, suffix := NextStdChunk(rest)
+		if prefix != "" || !confusableStd[std] {
+			return "", false
+		}
+		flex.WriteString(string(stdToFlexToken[std]))
+		rest = suffix
+	}
+	return flex.String(), true
+}
+
+// IsRoundTrippable reports whether a time formatted by flexLayout can be parsed back into the same instant,
+// at the precision of the layout. If not, reason describes the first problem found.
+//
+// It statically checks tokens of the layout Format uses,
+// where all optional parts are present and the first branch of each alternation is taken:
+//   - epoch tokens, X and x, identify an instant by themselves.
+//   - the year must be present with all of its digits. YY and yy keep only 2 digits.
+//   - the date must be present: month and day, day of year, or ISO week and day of week.
+//   - time fields must not skip a unit, e.g. minute without hour or second without minute.
+//     12-hour clock tokens need an AM/PM token.
+//   - if the layout has time fields, a zone must be present:
+//     a numeric offset with minutes or an IANA time zone name, zzzz.
+//     Abbreviations, MST and zz, are ambiguous and Z07 or -07 drop offset minutes, e.g. +05:30.
+//
+// Sub-second precision is not required, e.g. YYYY-MM-DDTHH:mm:ssZ is round-trippable at second precision.
+// Without time fields, a date is a date rather than an instant and needs no zone.
+func IsRoundTrippable(flexLayout string) (ok bool, reason string) {
+	variants, err := optionalstring.EnumerateOptionalStringRaw(flexLayout)
+	if err != nil {
+		return false, err.Error()
+	}
+	if len(variants) == 0 {
+		return false, "no layout is enumerated"
+	}
+	chunks, err := splitChunksRaw(variants[0])
+	if err != nil {
+		return false, err.Error()
+	}
+
+	kinds := map[string]bool{}
+	var longYear, exactZone bool
+	var hour12, twoDigitYear, ambiguousZone timeFormatToken
+	for _, c := range chunks {
+		if !c.isToken() {
+			continue
+		}
+		kind := fieldKindOf(c.token)
+		kinds[kind] = true
+		switch {
+		case c.token == "YY" || c.token == "yy":
+			twoDigitYear = c.token
+		case kind == "year" || kind == "ISO year":
+			longYear = true
+		case c.token == "h" || c.token == "hh":
+			hour12 = c.token
+		case c.token == "MST" || c.token == "zz" || c.token == "Z07" || c.token == "-07":
+			ambiguousZone = c.token
+		case kind == "time zone":
+			exactZone = true
+		}
+	}
+
+	if kinds["epoch"] {
+		return true, ""
+	}
+	switch {
+	case !kinds["year"] && !kinds["ISO year"]:
+		return false, "no year: the year is lost"
+	case twoDigitYear != "" && !longYear:
+		return false, fmt.Sprintf("%s keeps only 2 digits of the year", twoDigitYear)
+	case !(kinds["month"] && kinds["day of month"]) && !kinds["day of year"] && !(kinds["ISO week"] && kinds["day of week"]):
+		return false, "no date: month and day, day of year, or ISO week and day of week are needed"
+	}
+
+	hasHour := kinds["hour"] || kinds["time of day"]
+	hasMinute := kinds["minute"] || kinds["time of day"]
+	hasSecond := kinds["second"] || kinds["time of day"]
+	switch {
+	case kinds["minute"] && !hasHour:
+		return false, "minute without hour: the hour is lost"
+	case kinds["second"] && !hasMinute:
+		return false, "second without minute: the minute is lost"
+	case kinds["fractional second"] && !hasSecond:
+		return false, "fractional second without second: the second is lost"
+	case hour12 != "" && !kinds["AM/PM"]:
+		return false, fmt.Sprintf("12-hour clock %s without AM/PM token: AM and PM are not distinguished", hour12)
+	}
+
+	if !hasHour && !kinds["fractional second"] {
+		return true, ""
+	}
+	switch {
+	case exactZone:
+		return true, ""
+	case ambiguousZone == "":
+		return false, "no zone: the instant depends on the location it is parsed in"
+	case ambiguousZone == "MST" || ambiguousZone == "zz":
+		return false, fmt.Sprintf("%s: time zone abbreviations are ambiguous", ambiguousZone)
+	default:
+		return false, fmt.Sprintf("%s drops offset minutes, e.g. +05:30", ambiguousZone)
+	}
+}
@@ -0,0 +1,133 @@
+package flextime_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ngicks/flextime"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLint(t *testing.T) {
+	for _, clean := range []string{
+		"YYYY-MM-DDTHH:mm:ss.SSSZ",
+		"YYYY-MM-DD[THH[:mm]][Z]",
+		"[YY]YY-MM-DD",
+		"(YYYY|YY)-MM-DD",
+		"YYYY-MM-DD (Z|MST)",
+		"h:mm A",
+		"YYYY-DDD",
+		"'YYYY' YYYY",
+		"X",
+	} {
+		assert.Empty(t, flextime.Lint(clean), "layout = %s", clean)
+	}
+
+	cases := []struct {
+		layout   string
+		offsets  []int
+		contains string
+	}{
+		{"YYYY YY-MM", []int{5}, "duplicate year"},
+		{"YYYY-MM-DD HH:mm HH", []int{17}, "duplicate hour"},
+		{"YYYY-MM-DD[ HH:mm:ss MST Z]", []int{25}, "duplicate time zone"},
+		{"HH:mm:ss.SSS.999", []int{12}, "duplicate fractional second"},
+		{"YYYY-MM-DD X", []int{11}, "epoch"},
+		{"hh:mm", []int{0}, "without AM/PM"},
+		{"HH:mm A", []int{6}, "without 12-hour clock"},
+	}
+	for _, testCase := range cases {
+		warnings := flextime.Lint(testCase.layout)
+		require.Len(t, warnings, len(testCase.offsets), "layout = %s", testCase.layout)
+		for i, w := range warnings {
+			assert.Equal(t, testCase.offsets[i], w.Offset, "layout = %s", testCase.layout)
+			assert.Contains(t, w.Message, testCase.contains)
+		}
+	}
+
+	warnings := flextime.Lint("2006-01-02")
+	require.Len(t, warnings, 3)
+	assert.Equal(t, 0, warnings[0].Offset)
+
+	warnings = flextime.Lint("YYY")
+	require.Len(t, warnings, 1)
+	assert.Equal(t, 2, warnings[0].Offset)
+}
+
+func TestIsRoundTrippable(t *testing.T) {
+	for _, ok := range []string{
+		"YYYY-MM-DDTHH:mm:ss.SSSZ",
+		"YYYY-MM-DDTHH:mm:ssZ",
+		"YYYY-MM-DD[THH:mm:ss]Z",
+		"YYYY-DDD HH:mm -0700",
+		"ww, DD MMM YYYY hh:mm:ss A zzzz",
+		"GGGG-'W'WW-E HH:mmZ",
+		"YYYY-MM-DD",
+		"X",
+		"x",
+		"YYYY-MM-DD sod Z",
+	} {
+		roundTrippable, reason := flextime.IsRoundTrippable(ok)
+		assert.True(t, roundTrippable, "layout = %s, reason = %s", ok, reason)
+		assert.Empty(t, reason)
+	}
+
+	for _, testCase := range []struct {
+		flexLayout string
+		reason     string
+	}{
+		{"HH:mm:ss", "no year"},
+		{"MM-DD HH:mmZ", "no year"},
+		{"YY-MM-DD HH:mmZ", "YY keeps only 2 digits"},
+		{"YYYY-MM HH:mmZ", "no date"},
+		{"YYYY-MM-DD mm:ssZ", "minute without hour"},
+		{"YYYY-MM-DD HH:ssZ", "second without minute"},
+		{"YYYY-MM-DD hh:mmZ", "without AM/PM"},
+		{"YYYY-MM-DDTHH:mm:ss", "no zone"},
+		{"YYYY-MM-DD HH:mm MST", "MST: time zone abbreviations are ambiguous"},
+		{"YYYY-MM-DD HH:mm:ss zz", "zz: time zone abbreviations are ambiguous"},
+		{"YYYY-MM-DD HH:mmZ07", "drops offset minutes"},
+		// Format takes the first branch.
+		{"YYYY-MM-DD(' 'HH:mm|)", "no zone"},
+		{"YYY", "index [2]"},
+		{"YYYY[", "syntax error"},
+	} {
+		roundTrippable, reason := flextime.IsRoundTrippable(testCase.flexLayout)
+		assert.False(t, roundTrippable, "layout = %s", testCase.flexLayout)
+		assert.Contains(t, reason, testCase.reason, "layout = %s", testCase.flexLayout)
+	}
+}
+
+func TestDetectGoLayoutConfusion(t *testing.T) {
+	cases := []struct {
+		layout      string
+		offsets     []int
+		suggestions []string
+	}{
+		{"2006-01-02", []int{0, 5, 8}, []string{"YYYY", "MM", "DD"}},
+		{"YYYY-MM-DD 15:04:05", []int{11, 14, 17}, []string{"HH", "mm", "ss"}},
+		{"20060102T150405", []int{0, 9}, []string{"YYYYMMDD", "HHmmss"}},
+		{"YYYY 002", []int{5}, []string{"DDD"}},
+		// not entirely made of Go reference layout chunks.
+		{"YYYY-MM-DD v1 2022 10", nil, nil},
+		// quoted and escaped literals are intended.
+		{`'2006' \2\0\0\6 YYYY`, nil, nil},
+		// invalid layouts are left to Lint.
+		{"2006-YYY", nil, nil},
+	}
+	for _, testCase := range cases {
+		warnings := flextime.DetectGoLayoutConfusion(testCase.layout)
+		require.Len(t, warnings, len(testCase.offsets), "layout = %s", testCase.layout)
+		for i, w := range warnings {
+			assert.Equal(t, testCase.offsets[i], w.Offset, "layout = %s", testCase.layout)
+			assert.Contains(t, w.Message, "use "+testCase.suggestions[i], "layout = %s", testCase.layout)
+		}
+	}
+
+	var suggested []string
+	for _, w := range flextime.DetectGoLayoutConfusion("2006-01-02") {
+		suggested = append(suggested, w.Message[strings.LastIndex(w.Message, " ")+1:])
+	}
+	assert.Equal(t, "YYYY-MM-DD", strings.Join(suggested, "-"))
+}
@@ -0,0 +1,108 @@
+package flextime
+
+import "time"
+
+// LocaleNames are localized names of months and weekdays.
+// Pass it to WithLocale.
+type LocaleNames struct {
+	// Months are names for MMMM, from January to December.
+	Months [12]string
+	// ShortMonths are names for MMM, from January to December.
+	ShortMonths [12]string
+	// Weekdays are names for ww, from Sunday to Saturday as time.Weekday.
+	Weekdays [7]string
+	// ShortWeekdays are names for w, from Sunday to Saturday as time.Weekday.
+	ShortWeekdays [7]string
+}
+
+// ArabicIndicDigits maps Arabic-Indic digits, U+0660 to U+0669, to digits. Pass it to WithDigits.
+var ArabicIndicDigits = map[rune]int{
+	'٠': 0, '١': 1, '٢': 2, '٣': 3, '٤': 4, '٥': 5, '٦': 6, '٧': 7, '٨': 8, '٩': 9,
+}
+
+// LocaleFrench is French names of months and weekdays.
+var LocaleFrench = LocaleNames{
+	Months: [12]string{
+		"janvier", "février", "mars", "avril", "mai", "juin",
+		"juillet", "août", "septembre", "octobre", "novembre", "décembre",
+	},
+	ShortMonths: [12]string{
+		"janv.", "févr.", "mars", "avr.", "mai", "juin",
+		"juil.", "août", "sept.", "oct.", "nov.", "déc.",
+	},
+	Weekdays:      [7]string{"dimanche", "lundi", "mardi", "mercredi", "jeudi", "vendredi", "samedi"},
+	ShortWeekdays: [7]string{"dim.", "lun.", "mar.", "mer.", "jeu.", "ven.", "sam."},
+}
+
+// LocaleGerman is German names of months and weekdays.
+var LocaleGerman = LocaleNames{
+	Months: [12]string{
+		"Januar", "Februar", "März", "April", "Mai", "Juni",
+		"Juli", "August", "September", "Oktober", "November", "Dezember",
+	},
+	ShortMonths: [12]string{
+		"Jan.", "Feb.", "März", "Apr.", "Mai", "Juni",
+		"Juli", "Aug.", "Sept.", "Okt.", "Nov.", "Dez.",
+	},
+	Weekdays:      [7]string{"Sonntag", "Montag", "Dienstag", "Mittwoch", "Donnerstag", "Freitag", "Samstag"},
+	ShortWeekdays: [7]string{"So.", "Mo.", "Di.", "Mi.", "Do.", "Fr.", "Sa."},
+}
+
+// WithLocale makes MMMM, MMM, ww and w tokens format and parse names of locale instead of English ones.
+// Other tokens, including upper case ones, e.g. MONTH, are not affected.
+func WithLocale(locale LocaleNames) Option {
+	return func(l *Layout) {
+		l.locale = &locale
+		l.parseOpts.locale = &locale
+	}
+}
+
+// names returns the names goFmt is formatted into, if goFmt is a month or weekday name.
+func (locale *LocaleNames) names(goFmt string) []string {
+	switch goFmt {
+	case "January":
+		return locale.Months[:]
+	case "Jan":
+		return locale.ShortMonths[:]
+	case "Monday":
+		return locale.Weekdays[:]
+	case "Mon":
+		return locale.ShortWeekdays[:]
+	}
+	return nil
+}
+
+// appendName appends the localized name of t for goFmt to b.
+// ok is false if goFmt is not a month or weekday name.
+func (locale *LocaleNames) appendName(b []byte, t time.Time, goFmt string) (_ []byte, ok bool) {
+	switch goFmt {
+	case "January", "Jan":
+		return append(b, locale.names(goFmt)[t.Month()-1]...), true
+	case "Monday", "Mon":
+		return append(b, locale.names(goFmt)[t.Weekday()]...), true
+	}
+	return b, false
+}
+
+// parseName reads a localized name for goFmt.
+// The longest matching name is taken since a name may be a prefix of another.
+// Weekdays are only checked, as the standard time package does.
+func (f *parsedFields) parseName(locale *LocaleNames, goFmt string, value string) (rest string, err error) {
+	names := locale.names(goFmt)
+	idx := -1
+	for i, name := range names {
+		if name == "" || len(value) < len(name) || !match(value[:len(name)], name) {
+			continue
+		}
+		if idx < 0 || len(name) > len(names[idx]) {
+			idx = i
+		}
+	}
+	if idx < 0 {
+		return value, errBad
+	}
+	if goFmt == "January" || goFmt == "Jan" {
+		f.month = idx + 1
+	}
+	return value[len(names[idx]):], nil
+}
@@ -0,0 +1,109 @@
+package flextime_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ngicks/flextime"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithLocale(t *testing.T) {
+	target := time.Date(2022, time.February, 20, 23, 16, 22, 0, time.UTC)
+
+	cases := []struct {
+		locale     flextime.LocaleNames
+		flexLayout string
+		formatted  string
+	}{
+		{flextime.LocaleFrench, "ww D MMMM YYYY[ HH:mm]", "dimanche 20 février 2022 23:16"},
+		{flextime.LocaleFrench, "w DD MMM YYYY", "dim. 20 févr. 2022"},
+		{flextime.LocaleGerman, "ww, D. MMMM YYYY", "Sonntag, 20. Februar 2022"},
+		{flextime.LocaleGerman, "w, DD. MMM YYYY", "So., 20. Feb. 2022"},
+	}
+
+	for _, testCase := range cases {
+		l, err := flextime.Compile(testCase.flexLayout, flextime.WithLocale(testCase.locale))
+		require.NoError(t, err)
+		assert.Equal(t, testCase.formatted, l.Format(target))
+
+		parsed, err := l.Parse(testCase.formatted)
+		require.NoError(t, err, "value = %s", testCase.formatted)
+		assert.Equal(t, time.February, parsed.Month())
+		assert.Equal(t, 20, parsed.Day())
+
+		// English names are not accepted.
+		_, err = l.Parse(target.Format("Monday 2 January 2006"))
+		assert.Error(t, err)
+	}
+
+	// every month and weekday round-trips.
+	for _, locale := range []flextime.LocaleNames{flextime.LocaleFrench, flextime.LocaleGerman} {
+		for _, flexLayout := range []string{"ww DD MMMM YYYY", "w DD MMM YYYY"} {
+			l, err := flextime.Compile(flexLayout, flextime.WithLocale(locale))
+			require.NoError(t, err)
+			for month := time.January; month <= time.December; month++ {
+				for day := 1; day <= 7; day++ {
+					target := time.Date(2022, month, day, 0, 0, 0, 0, time.UTC)
+					formatted := l.Format(target)
+					parsed, err := l.Parse(formatted)
+					require.NoError(t, err, "value = %s", formatted)
+					assert.True(t, target.Equal(parsed), "value = %s, parsed = %s", formatted, parsed)
+				}
+			}
+		}
+	}
+
+	// case is folded only for ASCII letters, as English names are.
+	l, err := flextime.Compile("DD MMMM", flextime.WithLocale(flextime.LocaleFrench))
+	require.NoError(t, err)
+	parsed, err := l.Parse("20 JUILLET")
+	require.NoError(t, err)
+	assert.Equal(t, time.July, parsed.Month())
+
+	// without the option, names are English.
+	formatted, err := flextime.Format(target, "ww D MMMM YYYY")
+	require.NoError(t, err)
+	assert.Equal(t, "Sunday 20 February 2022", formatted)
+}
+
+func TestWithDigits(t *testing.T) {
+	expected := time.Date(2023, time.March, 14, 15, 9, 26, 0, time.UTC)
+
+	l, err := flextime.Compile("YYYY-MM-DD[THH:mm:ss]", flextime.WithDigits(flextime.ArabicIndicDigits))
+	require.NoError(t, err)
+	for value, expected := range map[string]time.Time{
+		"٢٠٢٣-٠٣-١٤T١٥:٠٩:٢٦": expected,
+		"٢٠٢٣-٠٣-١٤":          expected.Truncate(24 * time.Hour),
+		// mixed with ASCII digits.
+		"2023-٠٣-14": expected.Truncate(24 * time.Hour),
+	} {
+		parsed, err := l.Parse(value)
+		require.NoError(t, err, value)
+		assert.True(t, expected.Equal(parsed), "value = %s, parsed = %s", value, parsed)
+		assert.True(t, l.Valid(value), value)
+	}
+	// Format is not affected.
+	assert.Equal(t, "2023-03-14T15:09:26", l.Format(expected))
+
+	// names and quoted literals are kept.
+	l, err = flextime.Compile("'يوم' D MMMM YYYY '٢'", flextime.WithDigits(flextime.ArabicIndicDigits))
+	require.NoError(t, err)
+	parsed, err := l.Parse("يوم ١٤ March ٢٠٢٣ ٢")
+	require.NoError(t, err)
+	assert.True(t, expected.Truncate(24*time.Hour).Equal(parsed), parsed)
+
+	// the rest of value is returned as is.
+	l, err = flextime.Compile("YYYY-MM-DD", flextime.WithDigits(flextime.ArabicIndicDigits))
+	require.NoError(t, err)
+	parsed, start, end, err := l.FindTime("log ٢٠٢٣-٠٣-١٤ ١٢")
+	require.NoError(t, err)
+	assert.True(t, expected.Truncate(24*time.Hour).Equal(parsed), parsed)
+	assert.Equal(t, "٢٠٢٣-٠٣-١٤", "log ٢٠٢٣-٠٣-١٤ ١٢"[start:end])
+
+	_, err = l.Parse("٢٠٢٣-١٣-١٤")
+	assert.Error(t, err)
+	_, err = flextime.Parse("YYYY-MM-DD", "٢٠٢٣-٠٣-١٤")
+	assert.Error(t, err)
+}
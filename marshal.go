@@ -0,0 +1,96 @@
+package flextime
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+var (
+	errNoLayout     = errors.New("flextime: no layout is set. use NewTime")
+	errNoTextLayout = errors.New("flextime: no layout is set to TextTime")
+)
+
+// Time wraps time.Time to marshal and unmarshal it as a JSON string formatted by a flextime layout.
+// Create it by NewTime so that the layout is set,
+// then unmarshal into it, e.g. as a field of a struct initialized beforehand.
+type Time struct {
+	Time   time.Time
+	layout *Layout
+}
+
+// NewTime returns a zero Time which marshals and unmarshals by flexLayout.
+// flexLayout is compiled here. It panics if flexLayout is invalid.
+func NewTime(flexLayout string) *Time {
+	l, err := Compile(flexLayout)
+	if err != nil {
+		panic(err)
+	}
+	return &Time{layout: l}
+}
+
+// Layout returns the compiled layout of t.
+func (t *Time) Layout() *Layout {
+	return t.layout
+}
+
+// MarshalJSON implements json.Marshaler.
+// The time is formatted by (*Layout).Format.
+func (t Time) MarshalJSON() ([]byte, error) {
+	if t.layout == nil {
+		return nil, errNoLayout
+	}
+	return json.Marshal(t.layout.Format(t.Time))
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+// The JSON value must be a string parsable by the layout, or null, which leaves t unchanged.
+func (t *Time) UnmarshalJSON(data []byte) error {
+	if t.layout == nil {
+		return errNoLayout
+	}
+	if string(data) == "null" {
+		return nil
+	}
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	parsed, err := t.layout.Parse(value)
+	if err != nil {
+		return err
+	}
+	t.Time = parsed
+	return nil
+}
+
+// TextTime implements encoding.TextMarshaler and encoding.TextUnmarshaler
+// by a compiled flextime layout,
+// so it works with encoders which honor those interfaces, e.g. YAML, TOML or URL query encoders.
+// Layout must be set before marshaling or unmarshaling.
+type TextTime struct {
+	Layout *Layout
+	Time   time.Time
+}
+
+// MarshalText implements encoding.TextMarshaler.
+// The time is formatted by (*Layout).Format.
+func (t TextTime) MarshalText() ([]byte, error) {
+	if t.Layout == nil {
+		return nil, errNoTextLayout
+	}
+	return t.Layout.AppendFormat(nil, t.Time), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (t *TextTime) UnmarshalText(text []byte) error {
+	if t.Layout == nil {
+		return errNoTextLayout
+	}
+	parsed, err := t.Layout.Parse(string(text))
+	if err != nil {
+		return err
+	}
+	t.Time = parsed
+	return nil
+}
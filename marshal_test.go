@@ -0,0 +1,83 @@
+package flextime_test
+
+import (
+	"encoding"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/ngicks/flextime"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestTimeJSON(t *testing.T) {
+	type config struct {
+		Start *flextime.Time `json:"start"`
+		End   *flextime.Time `json:"end"`
+	}
+
+	input := `{"start":"2022-10-20T23:16","end":"2022/10/21"}`
+	cfg := config{
+		Start: flextime.NewTime("YYYY-MM-DD[THH:mm]"),
+		End:   flextime.NewTime("YYYY(-|/)MM(-|/)DD"),
+	}
+	require.NoError(t, json.Unmarshal([]byte(input), &cfg))
+	assert.True(t, time.Date(2022, time.October, 20, 23, 16, 0, 0, time.UTC).Equal(cfg.Start.Time))
+	assert.True(t, time.Date(2022, time.October, 21, 0, 0, 0, 0, time.UTC).Equal(cfg.End.Time))
+
+	marshaled, err := json.Marshal(cfg)
+	require.NoError(t, err)
+	// all optional parts are present and the first branch is taken.
+	assert.Equal(t, `{"start":"2022-10-20T23:16","end":"2022-10-21"}`, string(marshaled))
+
+	// null leaves the value unchanged.
+	require.NoError(t, cfg.Start.UnmarshalJSON([]byte(`null`)))
+	assert.True(t, time.Date(2022, time.October, 20, 23, 16, 0, 0, time.UTC).Equal(cfg.Start.Time))
+
+	var parseErr *time.ParseError
+	err = json.Unmarshal([]byte(`{"start":"20221020"}`), &cfg)
+	assert.ErrorAs(t, err, &parseErr)
+	err = json.Unmarshal([]byte(`{"start":20221020}`), &cfg)
+	assert.Error(t, err)
+
+	// zero Time has no layout.
+	_, err = json.Marshal(flextime.Time{})
+	assert.Error(t, err)
+	assert.Error(t, json.Unmarshal([]byte(`"2022-10-20"`), &flextime.Time{}))
+
+	assert.Panics(t, func() { flextime.NewTime("YYY") })
+}
+
+func TestTextTime(t *testing.T) {
+	l, err := flextime.Compile("YYYY-MM-DD[THH:mm]")
+	require.NoError(t, err)
+
+	type config struct {
+		Start flextime.TextTime `yaml:"start"`
+	}
+	cfg := config{Start: flextime.TextTime{Layout: l}}
+	require.NoError(t, yaml.Unmarshal([]byte("start: 2022-10-20T23:16\n"), &cfg))
+	assert.True(t, time.Date(2022, time.October, 20, 23, 16, 0, 0, time.UTC).Equal(cfg.Start.Time))
+
+	cfg.Start.Time = time.Date(2022, time.October, 21, 1, 2, 0, 0, time.UTC)
+	marshaled, err := yaml.Marshal(cfg)
+	require.NoError(t, err)
+	assert.Equal(t, "start: 2022-10-21T01:02\n", string(marshaled))
+
+	// direct interface calls.
+	var unmarshaler encoding.TextUnmarshaler = &flextime.TextTime{Layout: l}
+	require.NoError(t, unmarshaler.UnmarshalText([]byte("2022-10-20")))
+	var marshaler encoding.TextMarshaler = *unmarshaler.(*flextime.TextTime)
+	text, err := marshaler.MarshalText()
+	require.NoError(t, err)
+	assert.Equal(t, "2022-10-20T00:00", string(text))
+
+	var parseErr *time.ParseError
+	assert.ErrorAs(t, unmarshaler.UnmarshalText([]byte("20221020")), &parseErr)
+
+	_, err = flextime.TextTime{}.MarshalText()
+	assert.Error(t, err)
+	assert.Error(t, (&flextime.TextTime{}).UnmarshalText([]byte("2022-10-20")))
+}
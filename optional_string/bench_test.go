@@ -0,0 +1,50 @@
+package optionalstring_test
+
+import (
+	"strings"
+	"testing"
+
+	optionalstring "github.com/ngicks/flextime/optional_string"
+)
+
+func BenchmarkEnumerateOptionalString(b *testing.B) {
+	cases := []struct {
+		name  string
+		input string
+	}{
+		{"plain", `YYYY-MM-DDTHH:mm:ss.SSSZ`},
+		{"nested", `YYYY-MM-DD[THH[:mm[:ss.SSS]]][Z]`},
+		{"alternation", `YYYY(-|/)MM(-|/)DD[ HH:mm]`},
+		{"quoted", `YYYY-MM-DD['T'HH:mm][' at 'Z]`},
+		// 2^12 combinations.
+		{"optional_heavy", strings.Repeat(`[HH:]`, 12)},
+		// 4^6 combinations of nested alternations.
+		{"alternation_heavy", strings.Repeat(`(a|b|c|[d])`, 6)},
+	}
+
+	for _, bc := range cases {
+		b.Run(bc.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := optionalstring.EnumerateOptionalString(bc.input); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkEnumerateOptionalStringSeq(b *testing.B) {
+	input := strings.Repeat(`[HH:]`, 12)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		seq, err := optionalstring.EnumerateOptionalStringSeq(input)
+		if err != nil {
+			b.Fatal(err)
+		}
+		// only the first variant, which the iterator yields without enumerating the rest.
+		for range seq {
+			break
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package optionalstring_test
+
+import (
+	"strings"
+	"testing"
+
+	optionalstring "github.com/ngicks/flextime/optional_string"
+)
+
+func FuzzEnumerateOptionalString(f *testing.F) {
+	for _, seed := range []string{
+		"",
+		"foo",
+		"a[b][c]",
+		"a[b[c]]",
+		"a(b|c)[d]",
+		`'[foo]'`,
+		`'it''s'`,
+		`foo\[bar\]`,
+		"[ HH]",
+		"' at '",
+		"[",
+		"]",
+		"'",
+		`\`,
+		"(a|",
+		"a[b(c|[d])]",
+	} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, input string) {
+		count, err := optionalstring.CountOptionalStringCombinations(input)
+		if err != nil {
+			if !strings.Contains(err.Error(), "syntax error") {
+				t.Fatalf("unexpected error: input = %q, err = %v", input, err)
+			}
+			return
+		}
+		if count > 1<<10 {
+			// enumerating is exponential, only counting is checked.
+			return
+		}
+
+		enumerated, err := optionalstring.EnumerateOptionalStringRaw(input)
+		if err != nil {
+			t.Fatalf("counted but not enumerated: input = %q, err = %v", input, err)
+		}
+		if len(enumerated) == 0 {
+			t.Fatalf("no variants: input = %q", input)
+		}
+		if len(enumerated) > count {
+			t.Fatalf("more variants than counted: input = %q, %d > %d", input, len(enumerated), count)
+		}
+
+		seq, err := optionalstring.EnumerateOptionalStringSeq(input)
+		if err != nil {
+			t.Fatalf("enumerated but no iterator: input = %q, err = %v", input, err)
+		}
+		var i int
+		for variant := range seq {
+			if i >= len(enumerated) || variant.String() != enumerated[i].String() {
+				t.Fatalf("iterator differs from slice: input = %q, index = %d", input, i)
+			}
+			i++
+		}
+		if i != len(enumerated) {
+			t.Fatalf("iterator yielded %d variants, slice has %d: input = %q", i, len(enumerated), input)
+		}
+
+		for _, variant := range enumerated {
+			var concatenated string
+			for _, node := range variant {
+				concatenated += node.Value()
+			}
+			if concatenated != variant.String() {
+				t.Fatalf("inconsistent concatenation: input = %q, %q != %q", input, concatenated, variant.String())
+			}
+			// every variant is made of characters of input.
+			if len(variant.String()) > len(input) {
+				t.Fatalf("variant longer than input: input = %q, variant = %q", input, variant.String())
+			}
+		}
+
+		// if input has no optional part and no alternation, the only variant is input itself.
+		if len(enumerated) == 1 && !strings.ContainsAny(input, "[]()|") {
+			if enumerated[0].String() != input {
+				t.Fatalf("not preserved: input = %q, variant = %q", input, enumerated[0].String())
+			}
+		}
+	})
+}
@@ -1,7 +1,9 @@
 package optionalstring
 
 import (
-	"github.com/ngicks/type-param-common/iterator"
+	"context"
+	"math"
+	"strings"
 )
 
 type treeNodeType int
@@ -9,16 +11,19 @@ type treeNodeType int
 const (
 	nonOptional treeNodeType = iota
 	optional
+	// alternation node has no value and children but branches.
+	alternation
 )
 
 // treeNode is node of optional string tree.
 // It is seperated by optional part. left node is always optional.
 // if lower parts have no optional part the node must not have child nodes.
 type treeNode struct {
-	left  *treeNode
-	right *treeNode
-	value []TextNode
-	typ   treeNodeType
+	left     *treeNode
+	right    *treeNode
+	branches []*treeNode
+	value    []TextNode
+	typ      treeNodeType
 }
 
 func (n *treeNode) Clone() []TextNode {
@@ -42,6 +47,21 @@ func (n *treeNode) IsOptional() bool {
 	return n.typ == optional
 }
 
+func (n *treeNode) SetAsAlternation() {
+	n.typ = alternation
+}
+
+func (n *treeNode) IsAlternation() bool {
+	return n.typ == alternation
+}
+
+// AddBranch adds a new branch to alternation node and returns it.
+func (n *treeNode) AddBranch() *treeNode {
+	branch := &treeNode{}
+	n.branches = append(n.branches, branch)
+	return branch
+}
+
 func (n *treeNode) Left() *treeNode {
 	if n.left == nil {
 		n.left = &treeNode{}
@@ -65,52 +85,198 @@ func (n *treeNode) HasRight() bool {
 }
 
 func (n *treeNode) Flatten() []RawString {
-	return n.flatten()
+	flattened, _ := n.flatten(context.Background())
+	return flattened
 }
 
-func (n *treeNode) flatten() []RawString {
-	// root node must not be optional
+// spine returns n and nodes reached from n through right, in order.
+// Each of them is its own value followed by left, if any, an optional part or an alternation.
+// Walking the spine visits every part of a non-alternation node.
+func (n *treeNode) spine() []*treeNode {
+	var nodes []*treeNode
+	for cur := n; cur != nil; cur = cur.right {
+		nodes = append(nodes, cur)
+	}
+	return nodes
+}
 
-	// treeNodes is value of self -> left -> right order.
-	var cur RawString
-	var total []RawString
-	if c := n.Clone(); len(c) > 0 {
-		cur = RawString(c).Clone()
-	} else {
-		cur = NewRawString()
-	}
-	total = []RawString{cur.Clone()}
-
-	if n.HasLeft() {
-		l := n.Left()
-		totalCloned := iterator.
-			FromSlice(total).
-			Collect()
-		total = total[:0]
-		for _, s := range l.flatten() {
-			for _, str := range totalCloned {
-				total = append(total, str.Append(s))
-			}
+// Count returns the number of variants Flatten would return.
+// It is saturated at the max value of int.
+func (n *treeNode) Count() int {
+	if n.IsAlternation() {
+		var total int
+		for _, b := range n.branches {
+			total = saturatedAdd(total, b.Count())
 		}
-		if l.IsOptional() {
-			total = append(total, cur)
+		return total
+	}
+
+	total := 1
+	for _, node := range n.spine() {
+		if !node.HasLeft() {
+			continue
 		}
+		count := node.left.Count()
+		if node.left.IsOptional() {
+			count = saturatedAdd(count, 1)
+		}
+		total = saturatedMul(total, count)
+	}
+	return total
+}
+
+func saturatedAdd(a, b int) int {
+	if a > math.MaxInt-b {
+		return math.MaxInt
 	}
+	return a + b
+}
 
-	if n.HasRight() {
-		// right cannot be optional.
+func saturatedMul(a, b int) int {
+	if a != 0 && b > math.MaxInt/a {
+		return math.MaxInt
+	}
+	return a * b
+}
 
-		totalCloned := iterator.
-			FromSlice(total).
-			Collect()
-		total = total[:0]
+// flatten enumerates all variants of the tree.
+// For each optional part, from left to right,
+// variants where it is present come before variants where it is absent.
+// Branches of alternation are enumerated in order.
+// It returns ctx.Err() if ctx is done while enumerating.
+func (n *treeNode) flatten(ctx context.Context) ([]RawString, error) {
+	if n.IsAlternation() {
+		var total []RawString
+		for _, b := range n.branches {
+			flattened, err := b.flatten(ctx)
+			if err != nil {
+				return nil, err
+			}
+			total = append(total, flattened...)
+		}
+		return total, nil
+	}
 
-		for _, s := range n.Right().flatten() {
-			for _, str := range totalCloned {
-				total = append(total, str.Append(s))
+	// root node must not be optional
+	total := []RawString{NewRawString()}
+	for _, node := range n.spine() {
+		var err error
+		if c := node.Clone(); len(c) > 0 {
+			total, err = product(ctx, total, []RawString{RawString(c)})
+			if err != nil {
+				return nil, err
+			}
+		}
+		if node.HasLeft() {
+			variants, err := node.left.flatten(ctx)
+			if err != nil {
+				return nil, err
+			}
+			if node.left.IsOptional() {
+				variants = append(variants, NewRawString())
+			}
+			total, err = product(ctx, total, variants)
+			if err != nil {
+				return nil, err
 			}
 		}
 	}
+	return total, nil
+}
 
-	return total
+// each calls yield with head followed by each variant of n, in the same order as flatten,
+// walking the tree on demand instead of holding all variants.
+// It stops and returns false once yield returns false.
+func (n *treeNode) each(head RawString, yield func(RawString) bool) bool {
+	if n.IsAlternation() {
+		for _, b := range n.branches {
+			if !b.each(head, yield) {
+				return false
+			}
+		}
+		return true
+	}
+	return eachSpine(n.spine(), head, yield)
+}
+
+// eachSpine is each for the rest of a spine.
+func eachSpine(nodes []*treeNode, head RawString, yield func(RawString) bool) bool {
+	if len(nodes) == 0 {
+		return yield(head)
+	}
+	node, rest := nodes[0], nodes[1:]
+	if len(node.value) > 0 {
+		head = head.Append(RawString(node.value))
+	}
+	if !node.HasLeft() {
+		return eachSpine(rest, head, yield)
+	}
+	if !node.left.each(head, func(v RawString) bool { return eachSpine(rest, v, yield) }) {
+		return false
+	}
+	if node.left.IsOptional() {
+		return eachSpine(rest, head, yield)
+	}
+	return true
+}
+
+// product appends each of tails to each of heads.
+func product(ctx context.Context, heads, tails []RawString) ([]RawString, error) {
+	out := make([]RawString, 0, len(heads)*len(tails))
+	for _, head := range heads {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		for _, tail := range tails {
+			out = append(out, head.Append(tail))
+		}
+	}
+	return out, nil
+}
+
+// String reconstructs the optional string of n.
+// Contents of an optional node are returned without enclosing brackets.
+func (n *treeNode) String() string {
+	if n.IsAlternation() {
+		branches := make([]string, len(n.branches))
+		for i, b := range n.branches {
+			branches[i] = b.String()
+		}
+		return "(" + strings.Join(branches, "|") + ")"
+	}
+
+	var b strings.Builder
+	for _, node := range n.spine() {
+		b.WriteString(RawString(node.value).String())
+		if node.HasLeft() {
+			b.WriteString(node.left.enclosed())
+		}
+	}
+	return b.String()
+}
+
+// enclosed is like String but encloses an optional node with brackets.
+func (n *treeNode) enclosed() string {
+	if n.IsOptional() {
+		return "[" + n.String() + "]"
+	}
+	return n.String()
+}
+
+// explain splits n into the always present part and top level optional parts.
+// Alternations are always present, thus they are left as parts of required.
+func (n *treeNode) explain() (required string, optionals []string) {
+	var b strings.Builder
+	for _, node := range n.spine() {
+		b.WriteString(RawString(node.value).String())
+		if !node.HasLeft() {
+			continue
+		}
+		if node.left.IsOptional() {
+			optionals = append(optionals, node.left.String())
+		} else {
+			b.WriteString(node.left.enclosed())
+		}
+	}
+	return b.String(), optionals
 }
@@ -1,6 +1,7 @@
 package optionalstring
 
 import (
+	"fmt"
 	"sort"
 	"testing"
 
@@ -99,3 +100,83 @@ func TestNode(t *testing.T) {
 		assert.Equal(t, tc.expected, flatten)
 	}
 }
+
+func TestNodeString(t *testing.T) {
+	for _, input := range []string{
+		"",
+		"abc",
+		"a[b][c]",
+		"a[b[c]d]e",
+		"a(b|[c]d|)e[f(g|h)]",
+		`'[x]'[\]y]`,
+	} {
+		root, err := parseTree(input)
+		if err != nil {
+			t.Fatalf("input = %s, err = %v", input, err)
+		}
+		assert.Equal(t, input, root.String())
+	}
+}
+
+func TestFlattenCartesianProduct(t *testing.T) {
+	cases := []struct {
+		input    string
+		expected []string
+	}{
+		{
+			input:    "a[b]c[d]",
+			expected: []string{"abcd", "abc", "acd", "ac"},
+		},
+		{
+			input: "[a]-[b]-[c]",
+			expected: []string{
+				"a-b-c", "a-b-", "a--c", "a--",
+				"-b-c", "-b-", "--c", "--",
+			},
+		},
+		{
+			input: "Y[M][D][h][m]",
+			expected: []string{
+				"YMDhm", "YMDh", "YMDm", "YMD",
+				"YMhm", "YMh", "YMm", "YM",
+				"YDhm", "YDh", "YDm", "YD",
+				"Yhm", "Yh", "Ym", "Y",
+			},
+		},
+		{
+			// nested optional parts are present only if the enclosing one is.
+			input:    "a[b[c]]d[e]",
+			expected: []string{"abcde", "abcd", "abde", "abd", "ade", "ad"},
+		},
+	}
+
+	for _, testCase := range cases {
+		root, err := parseTree(testCase.input)
+		if err != nil {
+			t.Fatalf("input = %s, err = %v", testCase.input, err)
+		}
+		flattened := root.Flatten()
+		actual := make([]string, len(flattened))
+		seen := make(map[string]bool)
+		for i, v := range flattened {
+			actual[i] = v.String()
+			assert.False(t, seen[actual[i]], "duplicate %s, input = %s", actual[i], testCase.input)
+			seen[actual[i]] = true
+		}
+		assert.Equal(t, testCase.expected, actual, "input = %s", testCase.input)
+		assert.Equal(t, len(testCase.expected), root.Count(), "input = %s", testCase.input)
+	}
+
+	// n independent optional parts make exactly 2^n combinations.
+	for n := 1; n <= 4; n++ {
+		var input string
+		for i := 0; i < n; i++ {
+			input += fmt.Sprintf("%d[%c]", i, 'a'+i)
+		}
+		root, err := parseTree(input)
+		if err != nil {
+			t.Fatalf("input = %s, err = %v", input, err)
+		}
+		assert.Len(t, root.Flatten(), 1<<n, "input = %s", input)
+	}
+}
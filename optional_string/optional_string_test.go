@@ -2,7 +2,9 @@ package optionalstring_test
 
 import (
 	"fmt"
+	"math"
 	"sort"
+	"strings"
 	"testing"
 
 	optionalstring "github.com/ngicks/flextime/optional_string"
@@ -113,3 +115,409 @@ func TestOptionalNonClosing(t *testing.T) {
 		require.Error(t, err)
 	}
 }
+
+func TestSyntaxErrorOffset(t *testing.T) {
+	type testCase struct {
+		input  string
+		offset int
+	}
+	for _, tc := range []testCase{
+		{`a[b`, 1},
+		{`YYYY-MM-DD]`, 10},
+		{`foobar[ba[zq]ux`, 6},
+		{`a(b|c`, 1},
+	} {
+		_, err := optionalstring.EnumerateOptionalString(tc.input)
+		var syntaxErr *optionalstring.SyntaxError
+		require.ErrorAs(t, err, &syntaxErr, "input = %s", tc.input)
+		assert.Equal(t, tc.offset, syntaxErr.Offset, "input = %s", tc.input)
+		assert.Equal(t, len(syntaxErr.ParsedAs), syntaxErr.Offset, "input = %s", tc.input)
+	}
+
+	_, err := optionalstring.EnumerateOptionalString(`YYYY-MM-DD]`)
+	require.Error(t, err)
+	assert.True(t, strings.HasSuffix(err.Error(), "\nYYYY-MM-DD]\n          ^"), "error = %s", err)
+}
+
+func TestEnumerateOptionalStringOrder(t *testing.T) {
+	cases := []variantsTestCases{
+		{
+			input:  `a[b][c]`,
+			output: []string{`abc`, `ab`, `ac`, `a`},
+		},
+		{
+			input:  `a[b]c[d]`,
+			output: []string{`abcd`, `abc`, `acd`, `ac`},
+		},
+		{
+			input:  `a[b[c]d]e`,
+			output: []string{`abcde`, `abde`, `ae`},
+		},
+		{
+			// duplicates are removed, keeping the first occurrence.
+			input:  `[a][a]`,
+			output: []string{`aa`, `a`, ``},
+		},
+		{
+			input:  `a[]b`,
+			output: []string{`ab`},
+		},
+	}
+
+	for _, testCase := range cases {
+		result, err := optionalstring.EnumerateOptionalString(testCase.input)
+		require.NoError(t, err)
+		assert.Equal(t, testCase.output, result, "input = %s", testCase.input)
+
+		// every way of enumerating agrees on the order.
+		raw, err := optionalstring.EnumerateOptionalStringRaw(testCase.input)
+		require.NoError(t, err)
+		seq, err := optionalstring.EnumerateOptionalStringSeq(testCase.input)
+		require.NoError(t, err)
+		var fromRaw, fromSeq []string
+		for _, v := range raw {
+			fromRaw = append(fromRaw, v.String())
+		}
+		for v := range seq {
+			fromSeq = append(fromSeq, v.String())
+		}
+		assert.Equal(t, testCase.output, fromRaw, "input = %s", testCase.input)
+		assert.Equal(t, testCase.output, fromSeq, "input = %s", testCase.input)
+	}
+}
+
+func TestEnumerateOptionalStringSeq(t *testing.T) {
+	for _, input := range []string{
+		``,
+		`foo`,
+		`a[b][c]`,
+		`a[b[c]d]e`,
+		`[a][a]`,
+		`a[]b`,
+		`a(b|c)[d]`,
+		`YYYY(-|/)MM[(-|/)DD]`,
+		`a(b|[c]|)d`,
+		`'[foo]'[bar\]]`,
+		`YYYY-MM-DD[THH[:mm[:ss[.SSS]]]][Z]`,
+	} {
+		expected, err := optionalstring.EnumerateOptionalStringRaw(input)
+		require.NoError(t, err)
+
+		seq, err := optionalstring.EnumerateOptionalStringSeq(input)
+		require.NoError(t, err)
+		var actual []optionalstring.RawString
+		for v := range seq {
+			actual = append(actual, v)
+		}
+		assert.Equal(t, expected, actual, "input = %s", input)
+	}
+
+	seq, err := optionalstring.EnumerateOptionalStringSeq(strings.Repeat("[a]", 64))
+	require.NoError(t, err)
+	var count int
+	for v := range seq {
+		count++
+		if count == 3 {
+			// stopping early does not enumerate the rest of 2^64 combinations.
+			assert.Equal(t, strings.Repeat("a", 62), v.String())
+			break
+		}
+	}
+	assert.Equal(t, 3, count)
+
+	_, err = optionalstring.EnumerateOptionalStringSeq(`a[b`)
+	var syntaxErr *optionalstring.SyntaxError
+	assert.ErrorAs(t, err, &syntaxErr)
+}
+
+func TestEnumerateAlternation(t *testing.T) {
+	cases := []variantsTestCases{
+		{
+			input:  `YYYY(-|/)MM`,
+			output: []string{`YYYY-MM`, `YYYY/MM`},
+		},
+		{
+			input:  `a(b|c)[d]`,
+			output: []string{`abd`, `ab`, `acd`, `ac`},
+		},
+		{
+			input:  `(a|b)(c|d)`,
+			output: []string{`ac`, `ad`, `bc`, `bd`},
+		},
+		{
+			// alternation nested in optional.
+			input:  `YYYY[T(HH|hh)]`,
+			output: []string{`YYYYTHH`, `YYYYThh`, `YYYY`},
+		},
+		{
+			// optional nested in alternation.
+			input:  `(a[b]|c)d`,
+			output: []string{`abd`, `ad`, `cd`},
+		},
+		{
+			// nested alternation and empty branch.
+			input:  `x((a|b)|c|)`,
+			output: []string{`xa`, `xb`, `xc`, `x`},
+		},
+		{
+			input:  `a'(b|c)'`,
+			output: []string{`a'(b|c)'`},
+		},
+	}
+
+	for _, testCase := range cases {
+		result, err := optionalstring.EnumerateOptionalString(testCase.input)
+		require.NoError(t, err)
+		assert.Equal(t, testCase.output, result, "input = %s", testCase.input)
+	}
+}
+
+func TestAlternationNonClosing(t *testing.T) {
+	cases := []string{
+		`(a|b`,
+		`a|b`,
+		`a)`,
+		`[(a|b]`,
+		`(a[|b])`,
+	}
+
+	for _, input := range cases {
+		_, err := optionalstring.EnumerateOptionalString(input)
+		require.Error(t, err, "input = %s", input)
+	}
+}
+
+func TestCountOptionalStringCombinations(t *testing.T) {
+	deeplyNested := strings.Repeat("[a", 200) + strings.Repeat("]", 200)
+	siblings := strings.Repeat("[a]", 70)
+
+	cases := []struct {
+		input string
+		count int
+	}{
+		{`ABC`, 1},
+		{`A[B]C`, 2},
+		{`YYYY-MM-DD[THH[:mm[:ss.SSS]]][Z]`, 8},
+		{`a(b|c)[d]`, 4},
+		{`x((a|b)|c|)`, 4},
+		// deeply nested brackets only grow linearly.
+		{deeplyNested, 201},
+		// 2^70 saturates.
+		{siblings, math.MaxInt},
+	}
+
+	for _, testCase := range cases {
+		count, err := optionalstring.CountOptionalStringCombinations(testCase.input)
+		require.NoError(t, err)
+		assert.Equal(t, testCase.count, count, "input = %s", testCase.input)
+	}
+
+	_, err := optionalstring.CountOptionalStringCombinations(`[a`)
+	require.Error(t, err)
+}
+
+func TestEnumerateOptionalStringRawLimit(t *testing.T) {
+	deeplyNested := strings.Repeat("[a", 200) + strings.Repeat("]", 200)
+	enumerated, err := optionalstring.EnumerateOptionalStringRawLimit(deeplyNested, 201)
+	require.NoError(t, err)
+	assert.Len(t, enumerated, 201)
+	assert.Equal(t, strings.Repeat("a", 200), enumerated[0].String())
+	assert.Equal(t, "", enumerated[200].String())
+
+	_, err = optionalstring.EnumerateOptionalStringRawLimit(deeplyNested, 200)
+	require.ErrorIs(t, err, optionalstring.ErrTooManyCombinations)
+
+	_, err = optionalstring.EnumerateOptionalStringRawLimit(strings.Repeat("[a]", 40), 1<<16)
+	require.ErrorIs(t, err, optionalstring.ErrTooManyCombinations)
+}
+
+func TestSlashEscapedBracket(t *testing.T) {
+	cases := []struct {
+		input     string
+		unescaped []string
+	}{
+		{`foo\[bar\]`, []string{`foo[bar]`}},
+		{`foo\[[bar]\]`, []string{`foo[bar]`, `foo[]`}},
+		{`\(a\|b\)`, []string{`(a|b)`}},
+		// an escaped backslash does not escape the following bracket.
+		{`a\\[b]\'c`, []string{`a\b'c`, `a\'c`}},
+		{`'a\'[b]'[c]`, []string{`a'[b]c`, `a'[b]`}},
+		{`'a\\'[b]`, []string{`a\b`, `a\`}},
+	}
+
+	for _, testCase := range cases {
+		enumerated, err := optionalstring.EnumerateOptionalStringRaw(testCase.input)
+		require.NoError(t, err)
+		unescaped := make([]string, len(enumerated))
+		for i, v := range enumerated {
+			unescaped[i] = v.Unescaped()
+		}
+		assert.Equal(t, testCase.unescaped, unescaped, "input = %s", testCase.input)
+	}
+
+	enumerated, err := optionalstring.EnumerateOptionalStringRaw(`foo\[bar\]`)
+	require.NoError(t, err)
+	assert.Equal(t, optionalstring.SlashEscaped, enumerated[0][1].Typ())
+}
+
+func TestDoubledSingleQuote(t *testing.T) {
+	cases := []struct {
+		input     string
+		unescaped []string
+	}{
+		{`'it''s'`, []string{`it's`}},
+		{`'o''clock'[-']''']`, []string{`o'clock-]'`, `o'clock`}},
+		// an empty quote is an empty literal, used to separate adjacent tokens.
+		{`M''MMM`, []string{`MMMM`}},
+		{`''`, []string{``}},
+	}
+
+	for _, testCase := range cases {
+		enumerated, err := optionalstring.EnumerateOptionalStringRaw(testCase.input)
+		require.NoError(t, err)
+		unescaped := make([]string, len(enumerated))
+		for i, v := range enumerated {
+			unescaped[i] = v.Unescaped()
+		}
+		assert.Equal(t, testCase.unescaped, unescaped, "input = %s", testCase.input)
+	}
+}
+
+func TestWhitespaceIsPreserved(t *testing.T) {
+	cases := []variantsTestCases{
+		{input: "YYYY[ HH]", output: []string{"YYYY HH", "YYYY"}},
+		{input: "[ a ] b ", output: []string{" a  b ", " b "}},
+		{input: "HH' at 'mm", output: []string{"HH' at 'mm"}},
+		{input: "( a| b )", output: []string{" a", " b "}},
+		{input: "\t[\n]", output: []string{"\t\n", "\t"}},
+	}
+
+	for _, testCase := range cases {
+		enumerated, err := optionalstring.EnumerateOptionalString(testCase.input)
+		require.NoError(t, err, "input = %q", testCase.input)
+		assert.Equal(t, testCase.output, enumerated, "input = %q", testCase.input)
+	}
+}
+
+func TestExplain(t *testing.T) {
+	cases := []struct {
+		input     string
+		required  string
+		optionals []string
+	}{
+		{"YYYY-MM-DD", "YYYY-MM-DD", nil},
+		{"YYYY-MM-DD[THH[:mm[:ss]]][Z]", "YYYY-MM-DD", []string{"THH[:mm[:ss]]", "Z"}},
+		{"[a]b[c]d", "bd", []string{"a", "c"}},
+		{"a(b|[c]d|)e[f]", "a(b|[c]d|)e", []string{"f"}},
+		{`'[x]'[\]y]`, `'[x]'`, []string{`\]y`}},
+		// empty optional parts are no-op.
+		{"a[]", "a", nil},
+	}
+
+	for _, testCase := range cases {
+		required, optionals, err := optionalstring.Explain(testCase.input)
+		require.NoError(t, err, "input = %s", testCase.input)
+		assert.Equal(t, testCase.required, required, "input = %s", testCase.input)
+		assert.Equal(t, testCase.optionals, optionals, "input = %s", testCase.input)
+	}
+
+	_, _, err := optionalstring.Explain("a[b")
+	var syntaxErr *optionalstring.SyntaxError
+	assert.ErrorAs(t, err, &syntaxErr)
+}
+
+func TestNestedOptional(t *testing.T) {
+	cases := []variantsTestCases{
+		{input: "a[b[c]d]e", output: []string{"abcde", "abde", "ae"}},
+		{input: "a[b[c][d]e]f", output: []string{"abcdef", "abcef", "abdef", "abef", "af"}},
+		{input: "[[[[a]]]]", output: []string{"a", ""}},
+		{input: "[a[b]][c[d]]", output: []string{"abcd", "abc", "ab", "acd", "ac", "a", "cd", "c", ""}},
+		{input: "a[b(c|[d]e)f]g", output: []string{"abcfg", "abdefg", "abefg", "ag"}},
+	}
+
+	for _, testCase := range cases {
+		enumerated, err := optionalstring.EnumerateOptionalString(testCase.input)
+		require.NoError(t, err, "input = %s", testCase.input)
+		assert.Equal(t, testCase.output, enumerated, "input = %s", testCase.input)
+	}
+}
+
+func TestEmptyOptional(t *testing.T) {
+	cases := []variantsTestCases{
+		{input: "a[]b", output: []string{"ab"}},
+		{input: "[]", output: []string{""}},
+		{input: "[[]]", output: []string{""}},
+		{input: "a[[][]]b[c]", output: []string{"abc", "ab"}},
+		{input: "a([]|b)", output: []string{"a", "ab"}},
+		// an empty quote is not an empty optional part.
+		{input: "a['']b", output: []string{"a''b", "ab"}},
+	}
+
+	for _, testCase := range cases {
+		enumerated, err := optionalstring.EnumerateOptionalString(testCase.input)
+		require.NoError(t, err, "input = %s", testCase.input)
+		assert.Equal(t, testCase.output, enumerated, "input = %s", testCase.input)
+	}
+
+	// exactly one expansion, even before duplicates are removed.
+	for _, input := range []string{"a[]b", "[]", "[[]]", "a[[][]]b"} {
+		count, err := optionalstring.CountOptionalStringCombinations(input)
+		require.NoError(t, err)
+		assert.Equal(t, 1, count, "input = %s", input)
+	}
+}
+
+func TestParseTree(t *testing.T) {
+	input := `YYYY[-MM[-DD]]'T'(Z|\[UTC\])`
+	root, err := optionalstring.ParseTree(input)
+	require.NoError(t, err)
+	assert.Equal(t, input, root.String())
+
+	// YYYY [-MM[-DD]] 'T' (Z|\[UTC\])
+	assert.Equal(t, optionalstring.NodeRequired, root.Kind)
+	assert.Equal(t, "YYYY", root.Value.String())
+
+	month := root.Left
+	require.NotNil(t, month)
+	assert.Equal(t, optionalstring.NodeOptional, month.Kind)
+	assert.Equal(t, "-MM", month.Value.String())
+	assert.Nil(t, month.Right)
+	day := month.Left
+	require.NotNil(t, day)
+	assert.Equal(t, optionalstring.NodeOptional, day.Kind)
+	assert.Equal(t, "-DD", day.Value.String())
+	assert.Nil(t, day.Left)
+
+	rest := root.Right
+	require.NotNil(t, rest)
+	assert.Equal(t, optionalstring.NodeRequired, rest.Kind)
+	assert.Equal(t, "'T'", rest.Value.String())
+	assert.Equal(t, "T", rest.Value.Unescaped())
+	assert.Nil(t, rest.Right)
+
+	alt := rest.Left
+	require.NotNil(t, alt)
+	assert.Equal(t, optionalstring.NodeAlternation, alt.Kind)
+	assert.Empty(t, alt.Value)
+	require.Len(t, alt.Branches, 2)
+	assert.Equal(t, "Z", alt.Branches[0].Value.String())
+	assert.Equal(t, `\[UTC\]`, alt.Branches[1].Value.String())
+	assert.Equal(t, "[UTC]", alt.Branches[1].Value.Unescaped())
+
+	// the tree is a copy.
+	month.Value = nil
+	again, err := optionalstring.ParseTree(input)
+	require.NoError(t, err)
+	assert.Equal(t, "-MM", again.Left.Value.String())
+
+	for _, input := range []string{`a[b]c[d]`, `[a][b]`, `a[]b`, `(a|[b]|)c`, ``} {
+		root, err := optionalstring.ParseTree(input)
+		require.NoError(t, err, input)
+		expected := strings.ReplaceAll(input, "[]", "")
+		assert.Equal(t, expected, root.String(), input)
+	}
+
+	_, err = optionalstring.ParseTree(`a[b`)
+	var syntaxErr *optionalstring.SyntaxError
+	assert.ErrorAs(t, err, &syntaxErr)
+}
@@ -1,7 +1,11 @@
 package optionalstring
 
 import (
+	"context"
 	"fmt"
+	"iter"
+	"strings"
+	"unicode/utf8"
 
 	"github.com/pkg/errors"
 	parsec "github.com/prataprc/goparsec"
@@ -11,6 +15,7 @@ const (
 	OPENSQR           = "OPENSQR"
 	CLOSESQR          = "CLOSESQR"
 	SQUOTE            = "SQUOTE"
+	DOUBLEDSQUOTE     = "DOUBLEDSQUOTE"
 	ESCAPEDCHAR       = "ESCAPEDCHAR"
 	NORMALCHARS       = "NORMALCHARS"
 	CHAR              = "CHAR"
@@ -21,45 +26,184 @@ const (
 	ITEM              = "ITEM"
 	ITEMS             = "ITEMS"
 	OPTIONAL          = "OPTIONAL"
+	OPENPAREN         = "OPENPAREN"
+	CLOSEPAREN        = "CLOSEPAREN"
+	PIPE              = "PIPE"
+	ALTBRANCH         = "ALTBRANCH"
+	ALTBRANCHES       = "ALTBRANCHES"
+	ALTERNATION       = "ALTERNATION"
 	OPTIONALSTRING    = "OPTIONALSTRING"
 )
 
 var (
-	opensqr     parsec.Parser = parsec.Atom(`[`, OPENSQR)
-	closesqr                  = parsec.Atom(`]`, CLOSESQR)
-	squote                    = parsec.Atom(`'`, SQUOTE)
-	escapedchar               = parsec.Token(`\\.`, ESCAPEDCHAR)
-	normalchars               = parsec.Token(`[^\[\]\\']+`, NORMALCHARS)
+	opensqr       parsec.Parser = parsec.Atom(`[`, OPENSQR)
+	closesqr                    = parsec.Atom(`]`, CLOSESQR)
+	squote                      = parsec.Atom(`'`, SQUOTE)
+	doubledsquote               = parsec.Atom(`''`, DOUBLEDSQUOTE)
+	openparen                   = parsec.Atom(`(`, OPENPAREN)
+	closeparen                  = parsec.Atom(`)`, CLOSEPAREN)
+	pipe                        = parsec.Atom(`|`, PIPE)
+	escapedchar                 = parsec.Token(`\\.`, ESCAPEDCHAR)
+	normalchars                 = parsec.Token(`[^\[\]()|\\']+`, NORMALCHARS)
 )
 
+// MakeOptionalStringParser makes the parser of optional string.
+//
+// `[...]` is an optional part, which may be present or absent.
+// `(a|b|c)` is an alternation, where exactly one of branches is present.
+// Branches may be empty, contain optional parts or nested alternations.
+// An empty optional part, `[]`, or one which only encloses empty optional parts, e.g. `[[]]`,
+// is a no-op: it is neither present nor absent and makes no variant, thus `a[]b` is just `ab`.
+// Enclose `[`, `]`, `(`, `)` and `|` with single quotes,
+// or prefix each of them with a backslash, e.g. `\[`, to use them literally.
+// Within single quotes, two consecutive single quotes are a literal quote, e.g. the following is it's:
+//
+//	'it''s'
 func MakeOptionalStringParser(ast *parsec.AST) parsec.Parser {
 	char := ast.OrdChoice(CHAR, nil, escapedchar, normalchars)
 	chars := ast.Many(CHARS, nil, char)
-	charWithinEscape := ast.OrdChoice(CHARWITHINESCAPE, nil, escapedchar, normalchars, opensqr, closesqr)
-	charsWithinEscape := ast.Many(CHARSWITHINESCAPE, nil, charWithinEscape)
+	charWithinEscape := ast.OrdChoice(
+		CHARWITHINESCAPE, nil,
+		doubledsquote, escapedchar, normalchars, opensqr, closesqr, openparen, closeparen, pipe,
+	)
+	// Kleene since an empty quote, `''`, is an empty literal.
+	charsWithinEscape := ast.Kleene(CHARSWITHINESCAPE, nil, charWithinEscape)
 
-	var optional parsec.Parser
+	var optional, alternation parsec.Parser
 	escaped := ast.And(ESCAPED, nil, squote, charsWithinEscape, squote)
-	item := ast.OrdChoice(ITEM, nil, chars, escaped, &optional)
+	item := ast.OrdChoice(ITEM, nil, chars, escaped, &optional, &alternation)
 	items := ast.Kleene(ITEMS, nil, item)
 	optional = ast.And(OPTIONAL, nil, opensqr, items, closesqr)
-	return ast.Kleene(OPTIONALSTRING, nil, ast.OrdChoice("items", nil, optional, item))
+	altBranches := ast.Kleene(ALTBRANCHES, nil, ast.And(ALTBRANCH, nil, pipe, items))
+	alternation = ast.And(ALTERNATION, nil, openparen, items, altBranches, closeparen)
+	return ast.Kleene(OPTIONALSTRING, nil, item)
 }
 
+// SyntaxError is returned when an optional string has unbalanced optional parts or alternations.
 type SyntaxError struct {
 	Input    string
 	ParsedAs string
+	// Offset is the byte offset of the first position of Input not parsed, len(ParsedAs).
+	Offset int
 }
 
+// Error reports Input with a caret under Offset.
 func (e SyntaxError) Error() string {
-	return fmt.Sprintf(
+	message := fmt.Sprintf(
 		"syntax error: maybe no opening/closing sqrt? parsed result = %s, input = %s",
 		e.ParsedAs,
 		e.Input,
 	)
+	if e.Offset < 0 || e.Offset > len(e.Input) {
+		return message
+	}
+	caretPos := utf8.RuneCountInString(e.Input[:e.Offset])
+	return message + "\n" + e.Input + "\n" + strings.Repeat(" ", caretPos) + "^"
 }
 
+// EnumerateOptionalStringRaw enumerates all variants of optionalString
+// where each optional part, enclosed by `[]`, is present or absent.
+// Duplicates are removed.
+//
+// The order is deterministic: for each optional part, from left to right,
+// variants where it is present come before variants where it is absent.
+// For example `a[b][c]` is enumerated as `abc`, `ab`, `ac`, `a`.
+// Branches of an alternation are enumerated in order,
+// e.g. `a(b|c)[d]` is enumerated as `abd`, `ab`, `acd`, `ac`.
+// Thus the first variant has all optional parts present and the first branch of each alternation taken.
+// The order is a part of the API: duplicates keep the first occurrence,
+// and callers, e.g. flextime Format, may depend on which variant comes first.
 func EnumerateOptionalStringRaw(optionalString string) (enumerated []RawString, err error) {
+	root, err := parseTree(optionalString)
+	if err != nil {
+		return []RawString{}, err
+	}
+	return dedupe(root.Flatten()), nil
+}
+
+// EnumerateOptionalStringRawContext is like EnumerateOptionalStringRaw
+// but returns ctx.Err() if ctx is done while enumerating.
+func EnumerateOptionalStringRawContext(ctx context.Context, optionalString string) ([]RawString, error) {
+	root, err := parseTree(optionalString)
+	if err != nil {
+		return []RawString{}, err
+	}
+	flattened, err := root.flatten(ctx)
+	if err != nil {
+		return []RawString{}, err
+	}
+	return dedupe(flattened), nil
+}
+
+// EnumerateOptionalStringSeq is like EnumerateOptionalStringRaw
+// but returns an iterator which yields variants one at a time, in the same order.
+// Variants are made on demand, so breaking out of the loop early skips the rest of combinations.
+// Only strings of yielded variants are kept to remove duplicates.
+func EnumerateOptionalStringSeq(optionalString string) (iter.Seq[RawString], error) {
+	root, err := parseTree(optionalString)
+	if err != nil {
+		return nil, err
+	}
+	return func(yield func(RawString) bool) {
+		seen := make(map[string]struct{})
+		root.each(NewRawString(), func(v RawString) bool {
+			s := v.String()
+			if _, ok := seen[s]; ok {
+				return true
+			}
+			seen[s] = struct{}{}
+			return yield(v)
+		})
+	}, nil
+}
+
+// ErrTooManyCombinations is returned from EnumerateOptionalStringRawLimit
+// when the number of combinations exceeds the limit.
+var ErrTooManyCombinations = errors.New("too many combinations")
+
+// EnumerateOptionalStringRawLimit is like EnumerateOptionalStringRaw
+// but returns ErrTooManyCombinations without enumerating
+// if the number of combinations, counted by CountOptionalStringCombinations, exceeds max.
+func EnumerateOptionalStringRawLimit(optionalString string, max int) ([]RawString, error) {
+	root, err := parseTree(optionalString)
+	if err != nil {
+		return []RawString{}, err
+	}
+	if count := root.Count(); count > max {
+		return []RawString{}, errors.Wrapf(ErrTooManyCombinations, "%d exceeds limit %d", count, max)
+	}
+	return dedupe(root.Flatten()), nil
+}
+
+// CountOptionalStringCombinations counts combinations of optionalString without enumerating them.
+// Duplicates are counted, so the result is an upper bound of the length of EnumerateOptionalStringRaw.
+// It is saturated at the max value of int.
+func CountOptionalStringCombinations(optionalString string) (int, error) {
+	root, err := parseTree(optionalString)
+	if err != nil {
+		return 0, err
+	}
+	return root.Count(), nil
+}
+
+// Explain splits optionalString into the always present part and top level optional parts.
+// required is optionalString with top level optional parts removed.
+// optionals are contents of them without enclosing brackets, in order,
+// where nested optional parts are left enclosed.
+// Alternations are always present, thus they are left as parts of required.
+//
+// For example `YYYY-MM-DD[THH[:mm]][Z]` is explained as `YYYY-MM-DD` and [`THH[:mm]`, `Z`].
+func Explain(optionalString string) (required string, optionals []string, err error) {
+	root, err := parseTree(optionalString)
+	if err != nil {
+		return "", nil, err
+	}
+	required, optionals = root.explain()
+	return required, optionals, nil
+}
+
+// parseTree parses optionalString and decodes it into the tree.
+func parseTree(optionalString string) (root *treeNode, err error) {
 	var node parsec.Queryable
 	func() {
 		defer func() {
@@ -70,26 +214,42 @@ func EnumerateOptionalStringRaw(optionalString string) (enumerated []RawString,
 
 		ast := parsec.NewAST("optionalString", 100)
 		p := MakeOptionalStringParser(ast)
-		s := parsec.NewScanner([]byte(optionalString))
+		// Whitespace is a part of the string, do not let terminals skip it.
+		s := parsec.NewScanner([]byte(optionalString)).SetWSPattern(`^$`)
 		node, _ = ast.Parsewith(p, s)
 	}()
 
 	if err != nil {
-		return
+		return nil, err
 	}
 
 	if parsedAs := node.GetValue(); len(parsedAs) != len(optionalString) {
-		return []RawString{}, &SyntaxError{
+		return nil, &SyntaxError{
 			Input:    optionalString,
 			ParsedAs: parsedAs,
+			Offset:   len(parsedAs),
 		}
 	}
 
-	root := decode(node)
+	return decode(node), nil
+}
 
-	return root.Flatten(), nil
+// dedupe removes duplicates from enumerated while preserving the order.
+// Duplicates could be made by, e.g., `[a][a]` or `(a|a)`.
+func dedupe(enumerated []RawString) []RawString {
+	seen := make(map[string]struct{}, len(enumerated))
+	deduped := enumerated[:0]
+	for _, v := range enumerated {
+		if _, ok := seen[v.String()]; ok {
+			continue
+		}
+		seen[v.String()] = struct{}{}
+		deduped = append(deduped, v)
+	}
+	return deduped
 }
 
+// EnumerateOptionalString is like EnumerateOptionalStringRaw but returns variants as strings.
 func EnumerateOptionalString(optionalString string) (enumerated []string, err error) {
 	raw, err := EnumerateOptionalStringRaw(optionalString)
 	if err != nil {
@@ -105,54 +265,82 @@ func EnumerateOptionalString(optionalString string) (enumerated []string, err er
 
 func decode(node parsec.Queryable) *treeNode {
 	root := &treeNode{}
-	recursiveDecode(node.GetChildren(), root)
+	decodeItems(flattenItems(node.GetChildren()), root)
 	return root
 }
 
-func recursiveDecode(nodes []parsec.Queryable, ctx *treeNode) {
-	var onceFound bool
+// flattenItems expands containers, OPTIONALSTRING and ITEMS, in nodes
+// so that it returns a flat list of CHARS, ESCAPED, OPTIONAL and ALTERNATION nodes.
+func flattenItems(nodes []parsec.Queryable) []parsec.Queryable {
+	var flattened []parsec.Queryable
+	for _, node := range nodes {
+		switch node.GetName() {
+		case OPTIONALSTRING, ITEMS:
+			flattened = append(flattened, flattenItems(node.GetChildren())...)
+		default:
+			flattened = append(flattened, node)
+		}
+	}
+	return flattened
+}
 
-	for i := 0; i < len(nodes); i++ {
-		if onceFound {
-			recursiveDecode(nodes[i:], ctx.Right())
-			return
+// decodeItems decodes a flat list of items into ctx.
+// Values before the first optional part or alternation are stored to ctx itself,
+// the optional part or the alternation to ctx.Left() and the rest to ctx.Right(), recursively.
+// Thus optional parts may be nested or follow one another at any depth.
+// isEmptyOptional reports whether OPTIONAL node encloses nothing but empty optional parts, e.g. `[]` or `[[]]`.
+func isEmptyOptional(node parsec.Queryable) bool {
+	for _, item := range flattenItems(node.GetChildren()[1:2]) {
+		if item.GetName() != OPTIONAL || !isEmptyOptional(item) {
+			return false
 		}
+	}
+	return true
+}
 
-		switch nodes[i].GetName() {
-		case OPTIONALSTRING:
-			// skipping first node.
-			recursiveDecode(nodes[i].GetChildren(), ctx)
+func decodeItems(nodes []parsec.Queryable, ctx *treeNode) {
+	for i, node := range nodes {
+		switch node.GetName() {
 		case OPTIONAL:
-			var optNext *treeNode
-			if !onceFound {
-				onceFound = true
-				optNext = ctx.Left()
-			} else {
-				panic(
-					fmt.Sprintf(
-						"incorrect implementation: %s, %s",
-						nodes[i].GetName(),
-						nodes[i].GetValue(),
-					),
-				)
+			if isEmptyOptional(node) {
+				continue
+			}
+			opt := ctx.Left()
+			opt.SetAsOptional()
+			// children are OPENSQR, ITEMS, CLOSESQR.
+			decodeItems(flattenItems(node.GetChildren()[1:2]), opt)
+		case ALTERNATION:
+			alt := ctx.Left()
+			alt.SetAsAlternation()
+			// children are OPENPAREN, ITEMS, ALTBRANCHES, CLOSEPAREN.
+			children := node.GetChildren()
+			decodeItems(flattenItems(children[1:2]), alt.AddBranch())
+			for _, branch := range children[2].GetChildren() {
+				// children of ALTBRANCH are PIPE, ITEMS.
+				decodeItems(flattenItems(branch.GetChildren()[1:2]), alt.AddBranch())
 			}
-			optNext.SetAsOptional()
-			recursiveDecode(nodes[i].GetChildren(), optNext)
 		case CHARS:
-			for _, v := range nodes[i].GetChildren() {
+			for _, v := range node.GetChildren() {
 				switch v.GetName() {
 				case NORMALCHARS:
 					ctx.AddValue(v.GetValue(), Normal)
 				case ESCAPEDCHAR:
-					ctx.AddValue(v.GetValue(), SingleQuoteEscaped)
+					ctx.AddValue(v.GetValue(), SlashEscaped)
 				default:
 					panic(fmt.Sprintf("incorrect implementation: %s, %s", v.GetName(), v.GetValue()))
 				}
 			}
+			continue
 		case ESCAPED:
-			ctx.AddValue(nodes[i].GetValue(), SingleQuoteEscaped)
-		case ITEMS:
-			recursiveDecode(nodes[i].GetChildren(), ctx)
+			ctx.AddValue(node.GetValue(), SingleQuoteEscaped)
+			continue
+		default:
+			panic(fmt.Sprintf("incorrect implementation: %s, %s", node.GetName(), node.GetValue()))
 		}
+		// an optional part or an alternation is decoded into left. the rest goes to right.
+		if rest := nodes[i+1:]; len(rest) > 0 {
+			decodeItems(rest, ctx.Right())
+		}
+		return
 	}
 }
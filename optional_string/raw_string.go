@@ -1,6 +1,10 @@
 package optionalstring
 
-import "github.com/ngicks/type-param-common/slice"
+import (
+	"strings"
+
+	"github.com/ngicks/type-param-common/slice"
+)
 
 type valueType int
 
@@ -32,13 +36,31 @@ func (v TextNode) Unescaped() string {
 	case Normal:
 		return v.value
 	case SingleQuoteEscaped:
-		return v.Value()[1 : v.Len()-1]
+		return unquote(v.Value()[1 : v.Len()-1])
 	case SlashEscaped:
 		return v.Value()[1:]
 	}
 	panic("unknown")
 }
 
+// unquote unescapes the content of a single quoted string.
+// Doubled quotes and an escaped quote, `\'`, are a quote, and an escaped backslash, `\\`, is a backslash.
+// Other backslashes are kept as they are.
+func unquote(quoted string) string {
+	if !strings.ContainsAny(quoted, `'\`) {
+		return quoted
+	}
+	var b strings.Builder
+	for i := 0; i < len(quoted); i++ {
+		if (quoted[i] == '\'' || quoted[i] == '\\') && i+1 < len(quoted) &&
+			(quoted[i+1] == '\'' || (quoted[i] == '\\' && quoted[i+1] == '\\')) {
+			i++
+		}
+		b.WriteByte(quoted[i])
+	}
+	return b.String()
+}
+
 type RawString slice.Deque[TextNode]
 
 func NewRawString() RawString {
@@ -21,6 +21,9 @@ func TestTextNode(t *testing.T) {
 	assert.Equal(t, tn.Len(), 6)
 	assert.Equal(t, tn.Typ(), SingleQuoteEscaped)
 
+	tn = TextNode{typ: SingleQuoteEscaped, value: `'it''s'`}
+	assert.Equal(t, tn.Unescaped(), `it's`)
+
 	tn = TextNode{typ: SlashEscaped, value: `\a`}
 	assert.Equal(t, tn.Value(), `\a`)
 	assert.Equal(t, tn.Unescaped(), `a`)
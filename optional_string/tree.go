@@ -0,0 +1,109 @@
+package optionalstring
+
+// NodeKind is the kind of TreeNode.
+type NodeKind int
+
+const (
+	// NodeRequired is the root, a branch or Right of a node.
+	// It is present whenever the node it belongs to is.
+	NodeRequired NodeKind = iota
+	// NodeOptional is an optional part, enclosed by `[]`.
+	NodeOptional
+	// NodeAlternation is an alternation, `(a|b)`. It has Branches but no Value, Left and Right.
+	NodeAlternation
+)
+
+func (k NodeKind) String() string {
+	switch k {
+	case NodeRequired:
+		return "Required"
+	case NodeOptional:
+		return "Optional"
+	case NodeAlternation:
+		return "Alternation"
+	}
+	return "Unknown"
+}
+
+// TreeNode is a node of the tree of an optional string, returned from ParseTree.
+//
+// A node is Value followed by Left, then by Right.
+// Left is the first optional part or alternation after Value, if any,
+// and Right is the rest of the node after Left, if any.
+// Thus nodes reached from a node through Right, and itself, make a sequence.
+// For example `a[b]c(d|e)` is the root of Value `a` with Left of the optional `b`
+// and Right of `c`, whose Left is the alternation of `d` and `e`.
+//
+// TreeNode is a copy of the parsed tree. Modifying it affects nothing else.
+type TreeNode struct {
+	Kind NodeKind
+	// Value is text of the node before Left.
+	Value RawString
+	// Left is an optional part or an alternation, or nil.
+	Left *TreeNode
+	// Right is the rest of the node after Left, or nil.
+	Right *TreeNode
+	// Branches are branches of an alternation, in order.
+	Branches []*TreeNode
+}
+
+// ParseTree parses optionalString into the tree.
+// It returns *SyntaxError if optionalString has unbalanced optional parts or alternations.
+// Empty optional parts, e.g. `[]`, make no node, see MakeOptionalStringParser.
+func ParseTree(optionalString string) (*TreeNode, error) {
+	root, err := parseTree(optionalString)
+	if err != nil {
+		return nil, err
+	}
+	return exportTree(root), nil
+}
+
+func exportTree(n *treeNode) *TreeNode {
+	if n == nil {
+		return nil
+	}
+	exported := &TreeNode{
+		Value: RawString(n.Clone()),
+		Left:  exportTree(n.left),
+		Right: exportTree(n.right),
+	}
+	switch n.typ {
+	case optional:
+		exported.Kind = NodeOptional
+	case alternation:
+		exported.Kind = NodeAlternation
+	}
+	for _, b := range n.branches {
+		exported.Branches = append(exported.Branches, exportTree(b))
+	}
+	return exported
+}
+
+// String reconstructs the optional string of n.
+// Optional nodes are enclosed by brackets.
+func (n *TreeNode) String() string {
+	var out string
+	switch n.Kind {
+	case NodeAlternation:
+		out = "("
+		for i, b := range n.Branches {
+			if i > 0 {
+				out += "|"
+			}
+			out += b.String()
+		}
+		return out + ")"
+	case NodeOptional:
+		out = "["
+	}
+	for cur := n; cur != nil; cur = cur.Right {
+		out += cur.Value.String()
+		if cur.Left != nil {
+			out += cur.Left.String()
+		}
+	}
+	if n.Kind == NodeOptional {
+		out += "]"
+	}
+	return out
+}
@@ -3,59 +3,53 @@ package flextime
 import (
 	"fmt"
 	"strings"
+	"unicode/utf8"
 
 	optionalstring "github.com/ngicks/flextime/optional_string"
 )
 
 type FormatError struct {
+	// layout is the whole layout idx points into.
+	layout   string
 	idx      int
 	expected string
 	actual   string
 	msg      string
 }
 
+// Error returns the message followed by the layout and a caret pointing to the failing index, e.g.
+//
+//	index [2]: must be prefixed with one of [YYYYYY YYYY YY] but Y-MM-DD. maybe wrong len, like Y or YYY.
+//	YYY-MM-DD
+//	  ^
 func (e *FormatError) Error() string {
-	return fmt.Sprintf("index [%d]: %s but %s. %s", e.idx, e.expected, e.actual, e.msg)
+	message := fmt.Sprintf("index [%d]: %s but %s. %s", e.idx, e.expected, e.actual, e.msg)
+	if e.layout == "" || e.idx > len(e.layout) {
+		return message
+	}
+	caretPos := utf8.RuneCountInString(e.layout[:e.idx])
+	return message + "\n" + e.layout + "\n" + strings.Repeat(" ", caretPos) + "^"
 }
 
 func ReplaceTimeTokenRaw(input optionalstring.RawString) (string, error) {
-	var output string
-	for _, vv := range input {
-		switch vv.Typ() {
-		case optionalstring.SingleQuoteEscaped, optionalstring.SlashEscaped:
-			output += vv.Unescaped()
-		case optionalstring.Normal:
-			replaced, err := ReplaceTimeToken(vv.Unescaped())
-			if err != nil {
-				return "", err
-			}
-			output += string(replaced)
-		}
+	chunks, err := splitChunksRaw(input)
+	if err != nil {
+		return "", err
 	}
-	return output, nil
+	return chunksToGoLayout(input.String(), chunks)
 }
 
 func ReplaceTimeToken(input string) (string, error) {
-	var prefix, token string
-	var isToken bool
-	var err error
-
-	var output string
-
-	for len(input) > 0 {
-		prefix, token, input, isToken, err = nextChunk(input)
-		if err != nil {
-			return "", err
-		}
-		output += prefix
-		if isToken {
-			output += timeFormatToken(token).toGoFmt()
-		} else {
-			output += token
-		}
+	chunks, err := splitChunks(input)
+	if err != nil {
+		return "", err
 	}
+	return chunksToGoLayout(input, chunks)
+}
 
-	return output, nil
+// nextChunk is like (*tokenTables).nextChunk with the default tables.
+func nextChunk(input string) (prefix string, found string, suffix string, isToken bool, err error) {
+	return defaultTables().nextChunk(input)
 }
 
 // nextChunk reads input string from its head, up to a first time token or espaced string.
@@ -63,32 +57,55 @@ func ReplaceTimeToken(input string) (string, error) {
 // prefix is non time token string which is read up before the first hit.
 // found is next chunk string. If isTokein is true, chunk is a time token, an unescaped string otherwise.
 // suffix is rest of input.
-// err would be non nil if token has wrong length.
-func nextChunk(input string) (prefix string, found string, suffix string, isToken bool, err error) {
+// err would be non nil if token has wrong length or a quoted literal is not closed.
+func (tables *tokenTables) nextChunk(input string) (prefix string, found string, suffix string, isToken bool, err error) {
 	for i := 0; i < len(input); i++ {
 		switch input[i] {
 		case '\\':
-			return input[:i], input[i+1 : i+2], input[i+2:], false, nil
-		case '.':
-			if strings.HasPrefix(input[i:], ".S") ||
-				strings.HasPrefix(input[i:], ".9") ||
-				strings.HasPrefix(input[i:], ".0") {
+			if i+1 == len(input) {
+				return "", "", "", false, &FormatError{
+					idx:      i,
+					expected: "must be followed by a character to escape",
+					actual:   input[i:],
+					msg:      "trailing backslash.",
+				}
+			}
+			_, size := utf8.DecodeRuneInString(input[i+1:])
+			return input[:i], input[i+1 : i+1+size], input[i+1+size:], false, nil
+		case '.', ',':
+			// This also applies at i == 0, where prefix is empty.
+			sep := input[i : i+1]
+			if strings.HasPrefix(input[i:], sep+"S") ||
+				strings.HasPrefix(input[i:], sep+"9") ||
+				strings.HasPrefix(input[i:], sep+"0") {
 				repeated := getRepeatOf(input[i+1:], input[i+1:i+2])
-				return input[:i], "." + repeated, input[i+len("."+repeated):], true, nil
+				return input[:i], sep + repeated, input[i+len(sep+repeated):], true, nil
 			}
 		case '\'':
-			unescaped := getUntilClosingSingleQuote(input[i+1:])
-			return input[:i], unescaped, input[i+len(`'`+unescaped+`'`):], false, nil
+			quoted := getUntilClosingSingleQuote(input[i+1:])
+			if i+len(`'`+quoted) >= len(input) {
+				return "", "", "", false, &FormatError{
+					idx:      i,
+					expected: "must be closed with '",
+					actual:   input[i:],
+					msg:      "unterminated quoted literal.",
+				}
+			}
+			return input[:i], unquote(quoted), input[i+len(`'`+quoted+`'`):], false, nil
 		}
 
-		possibleSequences, ok := tokenSerachTable[input[i]]
+		possibleSequences, ok := tables.search[input[i]]
 		if ok {
 			for _, possible := range possibleSequences {
 				if strings.HasPrefix(string(input[i:]), string(possible)) {
+					if input[i] == '_' && extendsDayToken(input[i+len(possible):], possible) {
+						break
+					}
 					return input[:i], string(possible), input[i+len(possible):], true, nil
 				}
 			}
-			if input[i] == '-' {
+			if input[i] == '-' || input[i] == '_' || tables.literalUnknown {
+				// a literal. Unlike other token characters, they are common separators.
 				continue
 			}
 			return "", "", "", false, &FormatError{
@@ -102,6 +119,12 @@ func nextChunk(input string) (prefix string, found string, suffix string, isToke
 	return input, "", "", false, nil
 }
 
+// extendsDayToken reports whether rest continues the last letter of token into a longer day token,
+// e.g. rest is "D" for _D, which is read as _DD, literal _ followed by DD.
+func extendsDayToken(rest string, token timeFormatToken) bool {
+	return len(rest) > 0 && (rest[0] == token[len(token)-1] || rest[0] == 'o')
+}
+
 func getRepeatOf(input string, target string) string {
 	for i := 0; i < len(input); i++ {
 		if input[i:i+len(target)] != target {
@@ -112,38 +135,80 @@ func getRepeatOf(input string, target string) string {
 }
 
 // getUntilClosingSingleQuote returns `aaaaa` if input is `aaaaa'`.
+// Two consecutive single quotes are an escaped quote and do not close the string,
+// e.g. for the input below, it returns the input without the last quote:
+//
+//	it''s'
+//
+// A backslash escapes the next character, thus `\\'` closes the string but `\'` does not.
 func getUntilClosingSingleQuote(input string) string {
 	for i := 0; i < len(input); i++ {
-		if input[i] == '\'' {
-			if i == 0 {
-				return ""
-			}
-			if input[i-1] != '\\' || strings.HasSuffix(input[:i+1], `\\'`) {
-				return input[:i]
+		switch input[i] {
+		case '\\':
+			i++
+		case '\'':
+			if i+1 < len(input) && input[i+1] == '\'' {
+				i++
+				continue
 			}
+			return input[:i]
 		}
 	}
 	return input
 }
 
+// unquote returns the literal quoted represents, where quoted is returned from getUntilClosingSingleQuote.
+// Doubled quotes and an escaped quote, `\'`, are a quote, and an escaped backslash, `\\`, is a backslash.
+// Other backslashes are kept as they are, e.g. `'C:\dir'` is C:\dir.
+func unquote(quoted string) string {
+	if !strings.ContainsAny(quoted, `'\`) {
+		return quoted
+	}
+	var b strings.Builder
+	for i := 0; i < len(quoted); i++ {
+		if (quoted[i] == '\'' || quoted[i] == '\\') && i+1 < len(quoted) &&
+			(quoted[i+1] == '\'' || (quoted[i] == '\\' && quoted[i+1] == '\\')) {
+			i++
+		}
+		b.WriteByte(quoted[i])
+	}
+	return b.String()
+}
+
 var tokenSerachTable = map[byte][]timeFormatToken{
-	'M': {"MMMM", "MMM", "MST", "MM", "M"},
+	'M': {"MMMM", "MMM", "MST", "MONTH", "MON", "MM", "M"},
 	'w': {"ww", "w"},
 	'd': {"ddd", "dd", "d"},
-	'D': {"DDD", "DD", "D"},
-	'H': {"HH"},
+	'D': {"DDD", "DD", "Do", "D"},
+	'H': {"HH", "H"},
 	'h': {"hh", "h"},
-	'm': {"mm", "m"},
-	's': {"ss", "s"},
-	'Y': {"YYYY", "YY"},
+	'm': {"msod", "mod", "mm", "m"},
+	's': {"sod", "ss", "s"},
+	'Y': {"YYYYYY", "YYYY", "YY"},
 	'y': {"yyyy", "yy"},
+	'Q': {"QQ", "Q"},
+	'W': {"WEEKDAY", "WW", "W"},
+	'G': {"GGGG", "G"},
+	'e': {"e"},
+	'E': {"E"},
+	'c': {"c"},
+	'X': {"X"},
+	'x': {"x"},
+	'z': {"zzzz", "zz", "zm", "zs"},
+	// 'S' is fractional second without a dot. '.S' is handled below.
+	'S': {"SSSSSSSSS", "SSSSSS", "SSS"},
 	'A': {"A"},
-	'a': {"a"},
-	'Z': {"Z07:00:00", "Z070000", "Z07", "ZZ", "Z"},
-	// '-' with no successding 0 is non-token.
+	'a': {"aa", "a"},
+	// Z0700 and Z07:00 are spelled out aliases of ZZ and Z.
+	// They must precede Z07, otherwise Z07:00 would be read as Z07 followed by literal :00.
+	'Z': {"Z07:00:00", "Z070000", "Z07:00", "Z0700", "Z07", "ZZZ", "ZZ", "Z"},
+	// '-' not followed by 07 is non-token, e.g. separators of YYYY-MM-DD-HH.
 	'-': {"-07:00:00", "-070000", "-07:00", "-0700", "-07"},
-	// '.' with suceeding 0,9,S needs special handling.
-	// single '.' is non-token.
+	// '_' with no succeeding D or d is non-token, as '-'.
+	// '_' followed by DD, DDD or Do is also non-token, so that YYYY_MM_DD keeps its meaning.
+	'_': {"__D", "__d", "_D", "_d"},
+	// '.' or ',' with suceeding 0,9,S needs special handling.
+	// single '.' or ',' is non-token.
 }
 
 var tokenTable = map[timeFormatToken]goTimeFmtToken{
@@ -159,6 +224,10 @@ var tokenTable = map[timeFormatToken]goTimeFmtToken{
 	"dd":        "02",
 	"DDD":       "002",
 	"ddd":       "002",
+	"_D":        "_2",
+	"_d":        "_2",
+	"__D":       "__2",
+	"__d":       "__2",
 	"HH":        "15",
 	"h":         "3",
 	"hh":        "03",
@@ -174,9 +243,11 @@ var tokenTable = map[timeFormatToken]goTimeFmtToken{
 	"a":         "pm",
 	"MST":       "MST",
 	"ZZ":        "Z0700",
+	"Z0700":     "Z0700",
 	"Z070000":   "Z070000",
 	"Z07":       "Z07",
 	"Z":         "Z07:00",
+	"Z07:00":    "Z07:00",
 	"Z07:00:00": "Z07:00:00",
 	"-0700":     "-0700",
 	"-070000":   "-070000",
@@ -197,21 +268,56 @@ var tokens = [...]timeFormatToken{
 	"ddd",
 	"dd",
 	"d",
+	"Do",
+	"__D",
+	"__d",
+	"_D",
+	"_d",
 	"HH",
+	"H",
 	"hh",
 	"h",
 	"mm",
 	"m",
 	"ss",
 	"s",
+	"sod",
+	"msod",
+	"mod",
+	"YYYYYY",
 	"YYYY",
 	"YY",
 	"A",
 	"a",
+	"aa",
+	"MON",
+	"MONTH",
+	"WEEKDAY",
+	"QQ",
+	"Q",
+	"WW",
+	"W",
+	"GGGG",
+	"G",
+	"e",
+	"E",
+	"c",
+	"X",
+	"x",
+	"zzzz",
+	"zz",
+	"zm",
+	"zs",
+	"SSSSSSSSS",
+	"SSSSSS",
+	"SSS",
 	"MST",
 	"Z07:00:00",
 	"Z070000",
+	"Z07:00",
+	"Z0700",
 	"Z07",
+	"ZZZ",
 	"ZZ",
 	"Z",
 	"-07:00:00",
@@ -222,6 +328,9 @@ var tokens = [...]timeFormatToken{
 	".S",
 	".0",
 	".9",
+	",S",
+	",0",
+	",9",
 }
 
 type goTimeFmtToken string
@@ -234,7 +343,9 @@ var goTimeFmtTokens = [...]goTimeFmtToken{
 	"Monday",
 	"Mon",
 	"2",
+	"_2",
 	"02",
+	"__2",
 	"002",
 	"15",
 	"3",
@@ -261,14 +372,19 @@ var goTimeFmtTokens = [...]goTimeFmtToken{
 }
 
 func (tt timeFormatToken) toGoFmt() string {
-	token, ok := tokenTable[tt]
+	return defaultTables().toGoFmt(tt)
+}
+
+func (tables *tokenTables) toGoFmt(tt timeFormatToken) string {
+	token, ok := tables.goFmt[tt]
 	if ok {
 		return string(token)
 	}
 
-	if strings.HasPrefix(string(tt), ".S") {
+	if strings.HasPrefix(string(tt), ".S") || strings.HasPrefix(string(tt), ",S") {
 		return strings.ReplaceAll(string(tt), "S", "0")
-	} else if strings.HasPrefix(string(tt), ".0") || strings.HasPrefix(string(tt), ".9") {
+	} else if strings.HasPrefix(string(tt), ".0") || strings.HasPrefix(string(tt), ".9") ||
+		strings.HasPrefix(string(tt), ",0") || strings.HasPrefix(string(tt), ",9") {
 		return string(tt)
 	}
 	panic(fmt.Sprintf("unknown: %s", tt))
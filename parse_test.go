@@ -1,6 +1,7 @@
 package flextime_test
 
 import (
+	"strings"
 	"testing"
 	_ "time/tzdata"
 
@@ -32,7 +33,42 @@ func TestReplaceTimeToken(t *testing.T) {
 			expected: `2006-01-02T15:04:05`,
 		},
 		{
-			input:    `xxxx-'Www'-e`,
+			// fractional second at index 0.
+			input:    ".SSS",
+			expected: ".000",
+		},
+		{
+			input:    ".999Z",
+			expected: ".999Z07:00",
+		},
+		{
+			input:    ".",
+			expected: ".",
+		},
+		{
+			input:    "HH:mm:ss,SSS",
+			expected: "15:04:05,000",
+		},
+		{
+			input:    "HH:mm:ss,999999",
+			expected: "15:04:05,999999",
+		},
+		{
+			input:    "ww, DD MMM",
+			expected: "Monday, 02 Jan",
+		},
+		{
+			// - not followed by 07 is a separator.
+			input:    "YYYY-MM-DD-HH",
+			expected: "2006-01-02-15",
+		},
+		{
+			input:    "YYYY-MM-DD-HH-07:00",
+			expected: "2006-01-02-15-07:00",
+		},
+		{
+			// x is Unix milli token and e is ISO weekday token.
+			input:    `'xxxx'-'Www'-'e'`,
 			expected: `xxxx-Www-e`,
 		},
 	}
@@ -43,3 +79,16 @@ func TestReplaceTimeToken(t *testing.T) {
 		assert.Equal(t, testCase.expected, out)
 	}
 }
+
+func BenchmarkReplaceTimeToken(b *testing.B) {
+	// about 10KB.
+	layout := strings.Repeat(`YYYY-MM-DD'T'HH:mm:ss.SSSZ ww, MMM `, 300)
+	b.SetBytes(int64(len(layout)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := flextime.ReplaceTimeToken(layout); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -1,6 +1,11 @@
 package flextime
 
-import "testing"
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
 
 type simpleCase[T any] struct {
 	input    T
@@ -25,6 +30,19 @@ func TestGetUntilClosingSingleQuote(t *testing.T) {
 			input:    `aa\\'`,
 			expected: `aa\\`,
 		},
+		{
+			// an escaped backslash followed by an escaped quote.
+			input:    `aa\\\'b'`,
+			expected: `aa\\\'b`,
+		},
+		{
+			input:    `it''s'`,
+			expected: `it''s`,
+		},
+		{
+			input:    `''s'`,
+			expected: `''s`,
+		},
 	}
 
 	for _, testCase := range cases {
@@ -35,3 +53,120 @@ func TestGetUntilClosingSingleQuote(t *testing.T) {
 		}
 	}
 }
+
+func TestParseChunksAgreesWithTimeParse(t *testing.T) {
+	type testCase struct {
+		flexLayout string
+		value      string
+	}
+	cases := []testCase{
+		{`YYYY-MM-DDTHH:mm:ss.SSSZ`, "2022-10-20T23:16:22.168+09:00"},
+		{`YYYY-MM-DDTHH:mm:ss.999999999Z`, "2022-10-20T23:16:22Z"},
+		{`YYYY-MM-DDTHH:mm:ssZ`, "2022-10-20T23:16:22.123456-07:00"},
+		{`w, DD MMM YYYY HH:mm:ss MST`, "Thu, 20 Oct 2022 23:16:22 JST"},
+		{`w, DD MMM YYYY HH:mm:ss MST`, "Thu, 20 Oct 2022 23:16:22 UTC"},
+		{`w, DD MMM YYYY HH:mm:ss MST`, "Thu, 20 Oct 2022 23:16:22 GMT+9"},
+		{`ww MMMM D YY h:m:s A`, "Thursday October 20 22 11:16:22 PM"},
+		{`ww MMMM D YY h:m:s a`, "Thursday October 20 22 12:16:22 am"},
+		{`YYYY DDD`, "2022 293"},
+		{`YYYY DDD MM-DD`, "2022 293 10-20"},
+		{`YYYY DDD MM-DD`, "2022 293 10-21"},
+		{`YYYY-MM-DD`, "2022-02-29"},
+		{`YYYY-MM-DD`, "2022-13-01"},
+		{`YYYY-MM-DD`, "2022-12-01 extra"},
+		{`YYYY-MM-DD  HH`, "2022-12-01 10"},
+		{`HH:mm ZZ`, "10:00 +0900"},
+		{`HH:mm Z07`, "10:00 -03"},
+		{`HH:mm -07:00:00`, "10:00 +09:00:00"},
+		{`HH:mm Z070000`, "10:00 Z"},
+	}
+
+	for _, tc := range cases {
+		goLayout, err := ReplaceTimeToken(tc.flexLayout)
+		if err != nil {
+			t.Fatalf("%+v", err)
+		}
+		chunks, err := splitChunks(tc.flexLayout)
+		if err != nil {
+			t.Fatalf("%+v", err)
+		}
+		for _, loc := range []*time.Location{time.UTC, time.Local} {
+			expected, expectedErr := time.ParseInLocation(goLayout, tc.value, loc)
+			actual, actualErr := parseChunks(tc.flexLayout, chunks, tc.value, loc, loc, parseOptions{})
+			if (expectedErr == nil) != (actualErr == nil) {
+				t.Errorf("error mismatch: case = %+v, expected = %v, actual = %v", tc, expectedErr, actualErr)
+				continue
+			}
+			if !expected.Equal(actual) || expected.Location().String() != actual.Location().String() {
+				t.Errorf("not equal: case = %+v, expected = %v, actual = %v", tc, expected, actual)
+			}
+		}
+	}
+}
+
+func FuzzNextChunk(f *testing.F) {
+	for _, seed := range []string{
+		"",
+		"YYYY-MM-DDTHH:mm:ss.SSSZ",
+		`'it''s' h 'o''clock'`,
+		`'unterminated`,
+		`\`,
+		`a\`,
+		".",
+		",SSS",
+		"-07:00",
+		"YYY",
+		"Do sod msod zzzz",
+	} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, input string) {
+		rest := input
+		for rest != "" {
+			prefix, found, suffix, isToken, err := nextChunk(rest)
+			if err != nil {
+				var formatErr *FormatError
+				if !errors.As(err, &formatErr) {
+					t.Fatalf("unexpected error: input = %q, err = %v", input, err)
+				}
+				return
+			}
+			if !strings.HasPrefix(rest, prefix) || !strings.HasSuffix(rest, suffix) {
+				t.Fatalf("prefix or suffix is not a part of input: input = %q, rest = %q", input, rest)
+			}
+			if len(prefix)+len(suffix) > len(rest) {
+				t.Fatalf("prefix and suffix overlap: input = %q, rest = %q", input, rest)
+			}
+			if len(suffix) >= len(rest) {
+				t.Fatalf("no progress: input = %q, rest = %q", input, rest)
+			}
+			if isToken && !strings.HasPrefix(rest[len(prefix):], found) {
+				t.Fatalf("token is not read from input: input = %q, found = %q", input, found)
+			}
+			rest = suffix
+		}
+
+		if _, err := splitChunks(input); err != nil {
+			var formatErr *FormatError
+			if !errors.As(err, &formatErr) {
+				t.Fatalf("unexpected error: input = %q, err = %v", input, err)
+			}
+		}
+	})
+}
+
+func TestLoadLocationCachesOnlySuccess(t *testing.T) {
+	if _, err := loadLocation("Bogus/Zone"); err == nil {
+		t.Fatal("Bogus/Zone must not be loaded")
+	}
+	if _, ok := locationCache.Load("Bogus/Zone"); ok {
+		t.Error("failed lookup must not be cached")
+	}
+	if _, err := loadLocation("Asia/Tokyo"); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := locationCache.Load("Asia/Tokyo"); !ok {
+		t.Error("loaded location must be cached")
+	}
+}
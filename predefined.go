@@ -11,3 +11,9 @@ import (
 var RFC3339Optinal *LayoutSet = typeparamcommon.Must(NewLayoutSet(`YYYY-MM-DD[THH[:mm[:ss.999999999]]][Z]`))
 
 var RFC3339orUnixMilli *CombinedFlextime = NewCombined([]*Flextime{NewFlextime(RFC3339Optinal)}, time.UnixMilli)
+
+// RFC3339FixedNano is RFC 3339 with fractional seconds always in 9 digits,
+// e.g. 2022-10-20T23:16:22.000000000Z. Unlike time.RFC3339Nano, Format does not trim trailing zeros,
+// so formatted times in a same offset, e.g. UTC, sort lexically in chronological order.
+// Parse accepts 0 to 9 fractional digits.
+var RFC3339FixedNano = MustCompile(`YYYY-MM-DDTHH:mm:ss(.SSSSSSSSS|.999999999)Z`)
@@ -0,0 +1,48 @@
+package flextime_test
+
+import (
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/ngicks/flextime"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRFC3339FixedNano(t *testing.T) {
+	for _, testCase := range []struct {
+		t        time.Time
+		expected string
+	}{
+		{time.Date(2022, time.October, 20, 23, 16, 22, 0, time.UTC), "2022-10-20T23:16:22.000000000Z"},
+		{time.Date(2022, time.October, 20, 23, 16, 22, 120000000, time.UTC), "2022-10-20T23:16:22.120000000Z"},
+		{time.Date(2022, time.October, 20, 23, 16, 22, 123456789, jst), "2022-10-20T23:16:22.123456789+09:00"},
+	} {
+		formatted := flextime.RFC3339FixedNano.Format(testCase.t)
+		assert.Equal(t, testCase.expected, formatted)
+		parsed, err := flextime.RFC3339FixedNano.Parse(formatted)
+		require.NoError(t, err)
+		assert.True(t, testCase.t.Equal(parsed), parsed)
+	}
+
+	for _, value := range []string{
+		"2022-10-20T23:16:22Z",
+		"2022-10-20T23:16:22.1Z",
+		"2022-10-20T23:16:22.12345Z",
+		"2022-10-20T23:16:22.123456789Z",
+	} {
+		_, err := flextime.RFC3339FixedNano.Parse(value)
+		assert.NoError(t, err, value)
+	}
+	parsed, err := flextime.RFC3339FixedNano.Parse("2022-10-20T23:16:22.12Z")
+	require.NoError(t, err)
+	assert.Equal(t, 120000000, parsed.Nanosecond())
+
+	keys := []string{
+		flextime.RFC3339FixedNano.Format(time.Date(2022, time.October, 20, 23, 16, 22, 100000000, time.UTC)),
+		flextime.RFC3339FixedNano.Format(time.Date(2022, time.October, 20, 23, 16, 22, 0, time.UTC)),
+		flextime.RFC3339FixedNano.Format(time.Date(2022, time.October, 20, 23, 16, 22, 20000000, time.UTC)),
+	}
+	assert.True(t, sort.StringsAreSorted([]string{keys[1], keys[2], keys[0]}))
+}
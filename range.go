@@ -0,0 +1,61 @@
+package flextime
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ErrReversedRange is returned from ParseRange when the start of the range is after the end.
+var ErrReversedRange = errors.New("flextime: start of range is after end")
+
+// ParseRange parses value made of two times separated by sep, e.g. 2023-01-01/2023-02-01,
+// where both of them are parsed by flexLayout.
+// See (*Layout).ParseRange.
+func ParseRange(flexLayout, value, sep string) (start, end time.Time, err error) {
+	l, err := compileCached(flexLayout)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	return l.ParseRange(value, sep)
+}
+
+// ParseRange parses value made of two times separated by sep, e.g. 2023-01-01/2023-02-01,
+// where both of them are parsed by l.
+// sep may also appear in the times, e.g. - of 2023-01-01-2023-02-01:
+// value is split at each occurrence of sep from left to right and the first split where both halves parse wins.
+// If none does, the error is of the split at the first occurrence.
+//
+// start equal to end is accepted. Whether the range includes end is up to the caller.
+// If start is after end, it returns an error wrapping ErrReversedRange.
+func (l *Layout) ParseRange(value, sep string) (start, end time.Time, err error) {
+	if sep == "" {
+		return time.Time{}, time.Time{}, errors.New("flextime: empty range separator")
+	}
+	var firstErr error
+	for i := strings.Index(value, sep); i >= 0; {
+		start, err = l.Parse(value[:i])
+		if err == nil {
+			end, err = l.Parse(value[i+len(sep):])
+		}
+		if err == nil {
+			if start.After(end) {
+				return time.Time{}, time.Time{}, fmt.Errorf("%w: %q", ErrReversedRange, value)
+			}
+			return start, end, nil
+		}
+		if firstErr == nil {
+			firstErr = err
+		}
+		next := strings.Index(value[i+1:], sep)
+		if next < 0 {
+			break
+		}
+		i += 1 + next
+	}
+	if firstErr == nil {
+		firstErr = fmt.Errorf("flextime: range separator %q not found in %q", sep, value)
+	}
+	return time.Time{}, time.Time{}, firstErr
+}
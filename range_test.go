@@ -0,0 +1,55 @@
+package flextime_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ngicks/flextime"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRange(t *testing.T) {
+	jan := time.Date(2023, time.January, 1, 0, 0, 0, 0, time.UTC)
+	feb := time.Date(2023, time.February, 1, 0, 0, 0, 0, time.UTC)
+
+	for _, testCase := range []struct {
+		flexLayout string
+		value      string
+		sep        string
+		start, end time.Time
+	}{
+		// ISO 8601 time interval.
+		{"YYYY-MM-DD[THH:mm[:ss]Z]", "2023-01-01/2023-02-01", "/", jan, feb},
+		{"YYYY-MM-DD[THH:mm[:ss]Z]", "2023-01-01T07:00Z/2023-01-01T17:30:15+09:00", "/",
+			jan.Add(7 * time.Hour), time.Date(2023, time.January, 1, 8, 30, 15, 0, time.UTC)},
+		// sep also appears in times.
+		{"YYYY-MM-DD", "2023-01-01-2023-02-01", "-", jan, feb},
+		{"YYYY-MM-DD", "2023-01-01 - 2023-02-01", " - ", jan, feb},
+		// an empty range.
+		{"YYYY-MM-DD", "2023-01-01/2023-01-01", "/", jan, jan},
+	} {
+		start, end, err := flextime.ParseRange(testCase.flexLayout, testCase.value, testCase.sep)
+		require.NoError(t, err, testCase.value)
+		assert.True(t, testCase.start.Equal(start), "value = %s, start = %s", testCase.value, start)
+		assert.True(t, testCase.end.Equal(end), "value = %s, end = %s", testCase.value, end)
+	}
+
+	_, _, err := flextime.ParseRange("YYYY-MM-DD", "2023-02-01/2023-01-01", "/")
+	assert.ErrorIs(t, err, flextime.ErrReversedRange)
+
+	var parseErr *flextime.ParseError
+	_, _, err = flextime.ParseRange("YYYY-MM-DD", "2023-01-01/2023-13-01", "/")
+	assert.ErrorAs(t, err, &parseErr)
+	assert.NotErrorIs(t, err, flextime.ErrReversedRange)
+
+	for _, invalid := range []struct{ value, sep string }{
+		{"2023-01-01", "/"},
+		{"2023-01-01/", "/"},
+		{"2023-01-01/2023-02-01", ""},
+		{"2023-01-01/2023-02-01", "--"},
+	} {
+		_, _, err := flextime.ParseRange("YYYY-MM-DD", invalid.value, invalid.sep)
+		assert.Error(t, err, "value = %s, sep = %s", invalid.value, invalid.sep)
+	}
+}
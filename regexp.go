@@ -0,0 +1,240 @@
+package flextime
+
+import (
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// fieldPattern is a regular expression pattern of a token and the name of its capture group.
+type fieldPattern struct {
+	name    string
+	pattern string
+}
+
+func namesPattern(names []string) string {
+	quoted := make([]string, len(names))
+	for i, name := range names {
+		quoted[i] = regexp.QuoteMeta(name)
+	}
+	return `(?i:` + strings.Join(quoted, "|") + `)`
+}
+
+// goFmtPatterns maps Go reference layout elements to patterns of values they accept.
+var goFmtPatterns = map[string]fieldPattern{
+	"January":   {"month", namesPattern(longMonthNames)},
+	"Jan":       {"month", namesPattern(shortMonthNames)},
+	"1":         {"month", `\d{1,2}`},
+	"01":        {"month", `\d{2}`},
+	"Monday":    {"weekday", namesPattern(longDayNames)},
+	"Mon":       {"weekday", namesPattern(shortDayNames)},
+	"2":         {"day", `\d{1,2}`},
+	"_2":        {"day", ` ?\d{1,2}`},
+	"02":        {"day", `\d{2}`},
+	"__2":       {"yearday", ` {0,2}\d{1,3}`},
+	"002":       {"yearday", `\d{3}`},
+	"15":        {"hour", `\d{1,2}`},
+	"3":         {"hour", `\d{1,2}`},
+	"03":        {"hour", `\d{2}`},
+	"4":         {"minute", `\d{1,2}`},
+	"04":        {"minute", `\d{2}`},
+	"5":         {"second", `\d{1,2}`},
+	"05":        {"second", `\d{2}`},
+	"2006":      {"year", `-?\d{4}`},
+	"06":        {"year", `\d{2}`},
+	"PM":        {"meridiem", `AM|PM`},
+	"pm":        {"meridiem", `am|pm`},
+	"MST":       {"zone", `[A-Z][A-Za-z]{2,4}(?:[+-]\d{1,2})?`},
+	"Z0700":     {"zone", `Z|[+-]\d{4}`},
+	"Z070000":   {"zone", `Z|[+-]\d{6}`},
+	"Z07":       {"zone", `Z|[+-]\d{2}`},
+	"Z07:00":    {"zone", `Z|[+-]\d{2}:\d{2}`},
+	"Z07:00:00": {"zone", `Z|[+-]\d{2}:\d{2}:\d{2}`},
+	"-0700":     {"zone", `[+-]\d{4}`},
+	"-070000":   {"zone", `[+-]\d{6}`},
+	"-07":       {"zone", `[+-]\d{2}`},
+	"-07:00":    {"zone", `[+-]\d{2}:\d{2}`},
+	"-07:00:00": {"zone", `[+-]\d{2}:\d{2}:\d{2}`},
+}
+
+// computedPatterns maps computed tokens to patterns of values they accept.
+var computedPatterns = map[timeFormatToken]fieldPattern{
+	"Do":        {"day", `\d{1,2}(?:st|nd|rd|th)`},
+	"H":         {"hour", `\d{1,2}`},
+	"aa":        {"meridiem", `(?i:a\.m\.|p\.m\.)`},
+	"MON":       {"month", namesPattern(shortMonthNames)},
+	"MONTH":     {"month", namesPattern(longMonthNames)},
+	"WEEKDAY":   {"weekday", namesPattern(longDayNames)},
+	"sod":       {"secondofday", `\d{1,5}`},
+	"msod":      {"millisecondofday", `\d{1,8}`},
+	"mod":       {"minuteofday", `\d{1,4}`},
+	"G":         {"era", `BCE|BC|CE|AD`},
+	"Q":         {"quarter", `[1-4]`},
+	"QQ":        {"quarter", `0[1-4]`},
+	"WW":        {"isoweek", `\d{2}`},
+	"GGGG":      {"isoyear", `-?\d{4}`},
+	"e":         {"isoweekday", `[1-7]`},
+	"YYYYYY":    {"year", `-?\d{6}`},
+	"ZZZ":       {"zone", `Z|[+-]\d{2}(?:\d{2})?`},
+	"W":         {"weekofmonth", `[1-5]`},
+	"E":         {"weekdaynumber", `[1-7]`},
+	"c":         {"weekdayindex", `[0-6]`},
+	"X":         {"unix", `-?\d+`},
+	"x":         {"unixmilli", `-?\d+`},
+	"SSS":       {"fraction", `\d{3}`},
+	"SSSSSS":    {"fraction", `\d{6}`},
+	"SSSSSSSSS": {"fraction", `\d{9}`},
+	"zzzz":      {"zone", `[+-]\d{2}:\d{2}|[A-Za-z0-9/_+-]+`},
+	"zz":        {"zone", `[+-]\d{2}(?::?\d{2})?|[A-Z][A-Za-z]{2,4}(?:[+-]\d{1,2})?`},
+	"zm":        {"zone", `[+-]?\d+`},
+	"zs":        {"zone", `[+-]?\d+`},
+}
+
+// Regexp returns a regular expression which matches values l accepts.
+// Optional parts become optional groups and alternations become alternations of the regular expression.
+// Each token becomes a named capture group of the field it reads, e.g. year for YYYY:
+// year, month, day, yearday, weekday, hour, minute, second, fraction, meridiem, zone, era, quarter,
+// isoyear, isoweek, isoweekday, weekofmonth, secondofday, millisecondofday, unix and unixmilli.
+// Numeric weekdays have their own names since they are numbered differently from each other:
+// weekdaynumber for E, 1 to 7 from Monday, and weekdayindex for c, 0 to 6 from Sunday.
+// weekday is the name of weekday names, e.g. Mon for w.
+// A name appears more than once if its field is read at several positions, e.g. in branches of an alternation;
+// only the matched one has a non-empty submatch.
+//
+// The regular expression only checks the shape of values, e.g. MM matches 13 and month names are matched
+// case insensitively, thus it may match values Parse rejects. Options of l which change accepted values,
+// e.g. WithLocale or CaseInsensitiveMeridiem, are taken into account.
+// It is not anchored, so that it finds values in a longer text;
+// enclose it as ^(?:...)$ to check a whole string.
+func (l *Layout) Regexp() (*regexp.Regexp, error) {
+	w := &patternWriter{tables: l.tables, opts: l.parseOpts}
+	pattern, rest, err := w.sequence(l.flexLayout)
+	if err != nil {
+		return nil, err
+	}
+	if rest != "" {
+		return nil, errors.New("flextime: unbalanced " + rest[:1] + " in " + l.flexLayout)
+	}
+	return regexp.Compile(pattern)
+}
+
+// patternWriter converts a flextime layout into a regular expression pattern.
+type patternWriter struct {
+	tables *tokenTables
+	opts   parseOptions
+}
+
+// sequence converts layout up to the first unbalanced ], | or ), which starts rest, or the end.
+func (w *patternWriter) sequence(layout string) (pattern string, rest string, err error) {
+	var out strings.Builder
+	flush := func(segment string) error {
+		if segment == "" {
+			return nil
+		}
+		chunks, err := w.tables.splitChunks(segment)
+		if err != nil {
+			return err
+		}
+		for _, c := range chunks {
+			if c.isToken() {
+				out.WriteString(w.token(c))
+			} else {
+				out.WriteString(regexp.QuoteMeta(c.literal))
+			}
+		}
+		return nil
+	}
+
+	i := 0
+	for i < len(layout) {
+		switch layout[i] {
+		case '\\':
+			_, size := utf8.DecodeRuneInString(layout[i+1:])
+			i += 1 + size
+			continue
+		case '\'':
+			i += len(`'`+getUntilClosingSingleQuote(layout[i+1:])) + 1
+			continue
+		case '[', '(':
+			if err := flush(layout[:i]); err != nil {
+				return "", "", err
+			}
+			open := layout[i]
+			rest = layout[i:]
+			var branches []string
+			for {
+				var branch string
+				branch, rest, err = w.sequence(rest[1:])
+				if err != nil {
+					return "", "", err
+				}
+				branches = append(branches, branch)
+				if open == '(' && strings.HasPrefix(rest, "|") {
+					continue
+				}
+				break
+			}
+			closing := "]"
+			if open == '(' {
+				closing = ")"
+			}
+			if !strings.HasPrefix(rest, closing) {
+				return "", "", errors.New("flextime: unclosed " + string(open))
+			}
+			out.WriteString("(?:" + strings.Join(branches, "|") + ")")
+			if open == '[' {
+				out.WriteString("?")
+			}
+			layout, i = rest[1:], 0
+			continue
+		case ']', '|', ')':
+			if err := flush(layout[:i]); err != nil {
+				return "", "", err
+			}
+			return out.String(), layout[i:], nil
+		}
+		i++
+	}
+	if err := flush(layout); err != nil {
+		return "", "", err
+	}
+	return out.String(), "", nil
+}
+
+// token returns the pattern of c, a token chunk, as a named capture group.
+func (w *patternWriter) token(c layoutChunk) string {
+	if isFracToken(c.token) {
+		// either separator is accepted, as time.Parse does.
+		digits := len(c.token) - 1
+		if c.token[1] == '9' {
+			return `(?:[.,](?P<fraction>\d+))?`
+		}
+		return `[.,](?P<fraction>\d{` + strconv.Itoa(digits) + `})`
+	}
+
+	p, ok := computedPatterns[c.token]
+	if !ok {
+		p, ok = goFmtPatterns[c.goFmt]
+	}
+	if !ok {
+		p = fieldPattern{name: "token", pattern: `.+?`}
+	}
+	switch {
+	case w.opts.ignoreWeekday && isWeekdayChunk(c):
+		p.pattern = `\pL+`
+		if c.token == "E" || c.token == "c" {
+			p.pattern = `\d`
+		}
+	case w.opts.locale != nil && w.opts.locale.names(c.goFmt) != nil:
+		p.pattern = namesPattern(w.opts.locale.names(c.goFmt))
+	case w.opts.caseInsensitiveMeridiem && (c.token == "A" || c.token == "a"):
+		p.pattern = `(?i:AM|PM)`
+	case w.opts.rfc2822Zones && c.goFmt == "MST":
+		p.pattern = `[A-Za-z]{1,5}|` + p.pattern
+	case w.opts.offsetPrefix && isNumericOffset(c.goFmt):
+		p.pattern = `(?:UTC|GMT)?(?:` + p.pattern + `)`
+	}
+	return `(?P<` + p.name + `>` + p.pattern + `)`
+}
@@ -0,0 +1,113 @@
+package flextime_test
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/ngicks/flextime"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func anchored(t *testing.T, l *flextime.Layout) *regexp.Regexp {
+	t.Helper()
+	re, err := l.Regexp()
+	require.NoError(t, err)
+	return regexp.MustCompile(`^(?:` + re.String() + `)$`)
+}
+
+func TestLayoutRegexp(t *testing.T) {
+	cases := []struct {
+		flexLayout string
+		matches    []string
+		rejects    []string
+	}{
+		{
+			`YYYY-MM-DD['T'HH:mm[:ss[.SSS]]][Z]`,
+			[]string{"2022-10-20", "2022-10-20T23:16", "2022-10-20T23:16:22.168+09:00", "2022-10-20Z", "2022-10-20T23:16:22,168"},
+			[]string{"2022-1-20", "20221020", "2022-10-20T23", "2022-10-20T23:16:22.1", "2022-10-20 23:16"},
+		},
+		{
+			`w, D MMM YYYY HH:mm:ss (MST|-0700)`,
+			[]string{"Thu, 20 Oct 2022 23:16:22 JST", "thu, 2 oct 2022 23:16:22 +0900"},
+			[]string{"Thx, 20 Oct 2022 23:16:22 JST", "Thu, 20 Oct 2022 23:16:22 +09:00"},
+		},
+		{
+			`MMMM Do[,] YYYY[ h:mm a]`,
+			[]string{"October 20th, 2022", "October 1st 2022 9:05 pm"},
+			[]string{"October 20 2022", "Octobre 20th 2022", "October 20th 2022 9:05 PM"},
+		},
+		{
+			// literals and quoted brackets are escaped.
+			`'[x.y]' HH\(mm\)[ sod]`,
+			[]string{"[x.y] 23(16)", "[x.y] 23(16) 83782"},
+			[]string{"[xzy] 23(16)", "[x.y] 23:16"},
+		},
+		{
+			`GGGG-'W'WW-e`,
+			[]string{"2022-W42-4"},
+			[]string{"2022-W42-8", "2022-W4-4"},
+		},
+	}
+
+	for _, testCase := range cases {
+		l := flextime.MustCompile(testCase.flexLayout)
+		re := anchored(t, l)
+		for _, value := range testCase.matches {
+			assert.True(t, re.MatchString(value), "layout = %s, value = %s, re = %s", testCase.flexLayout, value, re)
+			// values Parse accepts match.
+			_, err := l.Parse(value)
+			assert.NoError(t, err, "layout = %s, value = %s", testCase.flexLayout, value)
+		}
+		for _, value := range testCase.rejects {
+			assert.False(t, re.MatchString(value), "layout = %s, value = %s, re = %s", testCase.flexLayout, value, re)
+		}
+	}
+}
+
+func TestLayoutRegexpGroups(t *testing.T) {
+	re, err := flextime.MustCompile(`YYYY-MM-DD['T'HH:mm[:ss[.999]]][Z]`).Regexp()
+	require.NoError(t, err)
+
+	submatches := re.FindStringSubmatch("at 2022-10-20T23:16:22.5+09:00 done")
+	require.NotNil(t, submatches)
+	for name, expected := range map[string]string{
+		"year":     "2022",
+		"month":    "10",
+		"day":      "20",
+		"hour":     "23",
+		"minute":   "16",
+		"second":   "22",
+		"fraction": "5",
+		"zone":     "+09:00",
+	} {
+		assert.Equal(t, expected, submatches[re.SubexpIndex(name)], "name = %s", name)
+	}
+
+	submatches = re.FindStringSubmatch("2022-10-20")
+	require.NotNil(t, submatches)
+	assert.Equal(t, "", submatches[re.SubexpIndex("hour")])
+
+	// numeric weekdays are numbered differently, thus named differently.
+	re, err = flextime.MustCompile(`w E c`).Regexp()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"", "weekday", "weekdaynumber", "weekdayindex"}, re.SubexpNames())
+	submatches = re.FindStringSubmatch("Sun 7 0")
+	require.NotNil(t, submatches)
+	assert.Equal(t, "Sun", submatches[re.SubexpIndex("weekday")])
+	assert.Equal(t, "7", submatches[re.SubexpIndex("weekdaynumber")])
+	assert.Equal(t, "0", submatches[re.SubexpIndex("weekdayindex")])
+}
+
+func TestLayoutRegexpOptions(t *testing.T) {
+	re := anchored(t, flextime.MustCompile(`D MMMM YYYY h:mm A`, flextime.WithLocale(flextime.LocaleFrench), flextime.CaseInsensitiveMeridiem()))
+	assert.True(t, re.MatchString("20 octobre 2022 11:16 pm"))
+	assert.False(t, re.MatchString("20 October 2022 11:16 PM"))
+
+	re = anchored(t, flextime.MustCompile(`HH:mmZ`, flextime.AllowOffsetPrefix()))
+	assert.True(t, re.MatchString("23:16UTC+09:00"))
+	assert.True(t, re.MatchString("23:16+09:00"))
+
+	re = anchored(t, flextime.MustCompile(`ww YYYY-MM-DD`, flextime.IgnoreWeekday()))
+	assert.True(t, re.MatchString("Funday 2022-10-20"))
+}
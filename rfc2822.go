@@ -0,0 +1,45 @@
+package flextime
+
+import "strings"
+
+// RFC2822Zones maps zone names of RFC 2822 obsolete syntax to their offsets in seconds east of UTC.
+// It is used by the MST token when the layout is compiled with WithRFC2822Zones.
+// Names are upper case and matched case insensitively.
+//
+// Military zones, single letters except J, are all mapped to 0,
+// since RFC 2822 says they SHOULD be considered equivalent to -0000
+// because RFC 822 defined them with the wrong signs.
+//
+// Entries may be added or replaced, but not concurrently with parsing.
+var RFC2822Zones = map[string]int{
+	"UT":  0,
+	"GMT": 0,
+	"EST": -5 * 60 * 60,
+	"EDT": -4 * 60 * 60,
+	"CST": -6 * 60 * 60,
+	"CDT": -5 * 60 * 60,
+	"MST": -7 * 60 * 60,
+	"MDT": -6 * 60 * 60,
+	"PST": -8 * 60 * 60,
+	"PDT": -7 * 60 * 60,
+	"A":   0, "B": 0, "C": 0, "D": 0, "E": 0, "F": 0, "G": 0, "H": 0, "I": 0,
+	"K": 0, "L": 0, "M": 0, "N": 0, "O": 0, "P": 0, "Q": 0, "R": 0, "S": 0,
+	"T": 0, "U": 0, "V": 0, "W": 0, "X": 0, "Y": 0, "Z": 0,
+}
+
+// parseRFC2822Zone reads a zone name in RFC2822Zones.
+// The whole run of letters must be a name, e.g. ESTX is not EST followed by X.
+// Otherwise it falls back to the MST token.
+func (f *parsedFields) parseRFC2822Zone(value string) (rest string, err error) {
+	n := 0
+	for n < len(value) && ('A' <= value[n] && value[n] <= 'Z' || 'a' <= value[n] && value[n] <= 'z') {
+		n++
+	}
+	name := strings.ToUpper(value[:n])
+	offset, ok := RFC2822Zones[name]
+	if !ok {
+		return f.parseStd("MST", value, false)
+	}
+	f.zoneName, f.zoneOffset = name, offset
+	return value[n:], nil
+}
@@ -0,0 +1,57 @@
+package flextime_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ngicks/flextime"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRFC2822Zones(t *testing.T) {
+	l, err := flextime.Compile("w, DD MMM YYYY HH:mm:ss MST", flextime.WithRFC2822Zones())
+	require.NoError(t, err)
+
+	cases := []struct {
+		value  string
+		offset int
+	}{
+		{"Thu, 20 Oct 2022 23:16:22 EDT", -4 * 60 * 60},
+		{"Thu, 20 Oct 2022 23:16:22 edt", -4 * 60 * 60},
+		{"Thu, 20 Oct 2022 23:16:22 PST", -8 * 60 * 60},
+		{"Thu, 20 Oct 2022 23:16:22 UT", 0},
+		{"Thu, 20 Oct 2022 23:16:22 Z", 0},
+		// military zones other than Z are also -0000, as RFC 2822 recommends.
+		{"Thu, 20 Oct 2022 23:16:22 A", 0},
+	}
+	for _, testCase := range cases {
+		parsed, err := l.Parse(testCase.value)
+		require.NoError(t, err, "value = %s", testCase.value)
+		_, offset := parsed.Zone()
+		assert.Equal(t, testCase.offset, offset, "value = %s", testCase.value)
+		expected := time.Date(2022, time.October, 20, 23, 16, 22, 0, time.FixedZone("", testCase.offset))
+		assert.True(t, expected.Equal(parsed), "value = %s, parsed = %s", testCase.value, parsed)
+	}
+
+	// names not in the table are parsed as usual.
+	parsed, err := l.Parse("Thu, 20 Oct 2022 23:16:22 JST")
+	require.NoError(t, err)
+	name, _ := parsed.Zone()
+	assert.Equal(t, "JST", name)
+
+	// without the option, EDT has an unknown offset.
+	parsed, err = flextime.Parse("w, DD MMM YYYY HH:mm:ss MST", "Thu, 20 Oct 2022 23:16:22 EDT")
+	require.NoError(t, err)
+	if _, offset := parsed.Zone(); offset != -4*60*60 {
+		// unless the local zone happens to know EDT.
+		assert.Equal(t, 0, offset)
+	}
+
+	// the table is extensible.
+	flextime.RFC2822Zones["JST"] = 9 * 60 * 60
+	defer delete(flextime.RFC2822Zones, "JST")
+	parsed, err = l.Parse("Thu, 20 Oct 2022 23:16:22 JST")
+	require.NoError(t, err)
+	assert.True(t, time.Date(2022, time.October, 20, 23, 16, 22, 0, jst).Equal(parsed))
+}
@@ -0,0 +1,114 @@
+package flextime
+
+import (
+	"bufio"
+	"io"
+	"time"
+)
+
+// Scanner reads lines from an io.Reader and parses a time at the head of each line,
+// e.g. timestamps of log lines.
+// It wraps bufio.Scanner and is used in the same way:
+//
+//	s := flextime.NewScanner(r, "YYYY-MM-DD HH:mm:ss[.SSS]")
+//	for s.Scan() {
+//		if s.ParseErr() != nil {
+//			continue // e.g. a continuation line of a stack trace.
+//		}
+//		fmt.Println(s.Time(), s.Rest())
+//	}
+//	if err := s.Err(); err != nil {
+//		// handle the read error.
+//	}
+//
+// Lines whose head does not parse are yielded with ParseErr set, or skipped by SkipUnparsed.
+type Scanner struct {
+	scanner      *bufio.Scanner
+	layout       *Layout
+	skipUnparsed bool
+	// err is the error of compiling the layout, if any.
+	err error
+
+	line     int
+	time     time.Time
+	rest     string
+	parseErr error
+}
+
+// NewScanner returns a new Scanner reading r, which parses lines by flexLayout configured by opts.
+// If flexLayout is invalid, the first call to Scan returns false and Err reports the error.
+func NewScanner(r io.Reader, flexLayout string, opts ...Option) *Scanner {
+	l, err := Compile(flexLayout, opts...)
+	return &Scanner{
+		scanner: bufio.NewScanner(r),
+		layout:  l,
+		err:     err,
+	}
+}
+
+// SkipUnparsed makes Scan skip lines whose head does not parse, instead of yielding them with ParseErr set.
+// It must be called before the first call to Scan.
+func (s *Scanner) SkipUnparsed() {
+	s.skipUnparsed = true
+}
+
+// Buffer sets the buffer to read lines, see bufio.Scanner.Buffer.
+// It must be called before the first call to Scan.
+func (s *Scanner) Buffer(buf []byte, max int) {
+	s.scanner.Buffer(buf, max)
+}
+
+// Scan advances the Scanner to the next line, which is then available through Time, Rest and ParseErr.
+// It returns false when the scan stops, either by reaching the end of the input or an error.
+// After Scan returns false, Err returns the error, if any.
+func (s *Scanner) Scan() bool {
+	if s.err != nil {
+		return false
+	}
+	for s.scanner.Scan() {
+		s.line++
+		s.time, s.rest, s.parseErr = s.layout.parsePrefix(s.scanner.Text())
+		if s.parseErr != nil && s.skipUnparsed {
+			continue
+		}
+		return true
+	}
+	return false
+}
+
+// Time returns the time parsed at the head of the current line.
+// It is the zero time if ParseErr is non nil.
+func (s *Scanner) Time() time.Time {
+	return s.time
+}
+
+// Rest returns the current line after the parsed time, e.g. a log message.
+// It is the whole line if ParseErr is non nil.
+func (s *Scanner) Rest() string {
+	return s.rest
+}
+
+// Text returns the current line, without the line terminator.
+func (s *Scanner) Text() string {
+	return s.scanner.Text()
+}
+
+// Line returns the 1-based line number of the current line, counting skipped ones.
+func (s *Scanner) Line() int {
+	return s.line
+}
+
+// ParseErr returns the error of parsing the current line, as *ParseError, or nil if it is parsed.
+func (s *Scanner) ParseErr() error {
+	return s.parseErr
+}
+
+// Err returns the first error that was encountered by the Scanner:
+// the error of compiling the layout or of reading the input.
+// Errors of parsing lines are not reported here but by ParseErr.
+func (s *Scanner) Err() error {
+	if s.err != nil {
+		return s.err
+	}
+	return s.scanner.Err()
+}
@@ -0,0 +1,103 @@
+package flextime_test
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"time"
+
+	"github.com/ngicks/flextime"
+	optionalstring "github.com/ngicks/flextime/optional_string"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const scannerInput = `2022-10-20 23:16:22.168 INFO started
+2022-10-20 23:16:23 WARN slow
+panic: something
+	at main.go:10
+
+2022-10-20 23:16:24.500 ERROR failed
+2022-10-20 23:16:25`
+
+func TestScanner(t *testing.T) {
+	type scanned struct {
+		line   int
+		time   time.Time
+		rest   string
+		failed bool
+	}
+	at := func(sec, nsec int) time.Time {
+		return time.Date(2022, time.October, 20, 23, 16, sec, nsec, time.UTC)
+	}
+
+	s := flextime.NewScanner(strings.NewReader(scannerInput), "YYYY-MM-DD HH:mm:ss[.SSS]")
+	var results []scanned
+	for s.Scan() {
+		var parseErr *flextime.ParseError
+		if s.ParseErr() != nil {
+			require.ErrorAs(t, s.ParseErr(), &parseErr)
+			assert.Equal(t, s.Text(), s.Rest())
+			assert.True(t, s.Time().IsZero())
+		}
+		results = append(results, scanned{s.Line(), s.Time(), s.Rest(), s.ParseErr() != nil})
+	}
+	require.NoError(t, s.Err())
+	assert.Equal(t, []scanned{
+		{1, at(22, 168000000), " INFO started", false},
+		{2, at(23, 0), " WARN slow", false},
+		{3, time.Time{}, "panic: something", true},
+		{4, time.Time{}, "\tat main.go:10", true},
+		{5, time.Time{}, "", true},
+		{6, at(24, 500000000), " ERROR failed", false},
+		{7, at(25, 0), "", false},
+	}, results)
+
+	s = flextime.NewScanner(strings.NewReader(scannerInput), "YYYY-MM-DD HH:mm:ss[.SSS]")
+	s.SkipUnparsed()
+	var lines []int
+	for s.Scan() {
+		require.NoError(t, s.ParseErr())
+		lines = append(lines, s.Line())
+	}
+	require.NoError(t, s.Err())
+	assert.Equal(t, []int{1, 2, 6, 7}, lines)
+}
+
+func TestScannerOptions(t *testing.T) {
+	s := flextime.NewScanner(
+		strings.NewReader("20-Oct-2022 11:16 pm done\n"),
+		"DD-MMM-YYYY hh:mm A",
+		flextime.CaseInsensitiveMeridiem(),
+	)
+	require.True(t, s.Scan())
+	require.NoError(t, s.ParseErr())
+	assert.True(t, time.Date(2022, time.October, 20, 23, 16, 0, 0, time.UTC).Equal(s.Time()))
+	assert.Equal(t, " done", s.Rest())
+	assert.False(t, s.Scan())
+}
+
+func TestScannerError(t *testing.T) {
+	s := flextime.NewScanner(strings.NewReader(scannerInput), "YYYY-MM-DD[ HH")
+	assert.False(t, s.Scan())
+	var syntaxErr *optionalstring.SyntaxError
+	assert.ErrorAs(t, s.Err(), &syntaxErr)
+
+	readErr := errors.New("read failure")
+	s = flextime.NewScanner(
+		io.MultiReader(strings.NewReader("2022-10-20 first\n"), iotest.ErrReader(readErr)),
+		"YYYY-MM-DD",
+	)
+	require.True(t, s.Scan())
+	assert.Equal(t, " first", s.Rest())
+	assert.False(t, s.Scan())
+	assert.ErrorIs(t, s.Err(), readErr)
+
+	s = flextime.NewScanner(strings.NewReader(strings.Repeat("a", 100)), "YYYY-MM-DD")
+	s.Buffer(make([]byte, 10), 10)
+	assert.False(t, s.Scan())
+	assert.ErrorIs(t, s.Err(), bufio.ErrTooLong)
+}
@@ -0,0 +1,131 @@
+package flextime
+
+import (
+	"fmt"
+	"time"
+)
+
+// strftimeTable maps strftime conversion specifiers to flextime tokens.
+var strftimeTable = map[byte]timeFormatToken{
+	'Y': "YYYY",
+	'y': "YY",
+	'm': "MM",
+	'b': "MMM",
+	'h': "MMM",
+	'B': "MMMM",
+	'd': "DD",
+	'e': "_D",
+	'j': "DDD",
+	'a': "w",
+	'A': "ww",
+	'H': "HH",
+	'I': "hh",
+	'M': "mm",
+	'S': "ss",
+	'p': "A",
+	'z': "-0700",
+	'Z': "MST",
+}
+
+// strftimeChunks splits strftime style format into chunks.
+// Text other than conversion specifiers is a quoted literal.
+func strftimeChunks(format string) ([]layoutChunk, error) {
+	var chunks []layoutChunk
+	appendLiteral := func(offset int, literal string) {
+		if n := len(chunks); n > 0 && !chunks[n-1].isToken() {
+			chunks[n-1].literal += literal
+			return
+		}
+		chunks = append(chunks, layoutChunk{offset: offset, literal: literal, quoted: true})
+	}
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' {
+			appendLiteral(i, format[i:i+1])
+			continue
+		}
+		if i+1 >= len(format) {
+			return nil, &FormatError{
+				layout:   format,
+				idx:      i,
+				expected: "must be followed by a conversion specifier",
+				actual:   format[i:],
+				msg:      "use %% for a literal %.",
+			}
+		}
+		i++
+		if format[i] == '%' {
+			appendLiteral(i-1, "%")
+			continue
+		}
+		token, ok := strftimeTable[format[i]]
+		if !ok {
+			return nil, &FormatError{
+				layout:   format,
+				idx:      i - 1,
+				expected: "must be a known conversion specifier",
+				actual:   format[i-1:],
+				msg:      fmt.Sprintf("unknown specifier %%%c.", format[i]),
+			}
+		}
+		chunks = append(chunks, layoutChunk{offset: i - 1, token: token, goFmt: string(tokenTable[token])})
+	}
+	return chunks, nil
+}
+
+// StrftimeToGoLayout converts strftime style format, e.g. `%Y-%m-%d %H:%M:%S`, into Go reference layout.
+//
+// Supported conversion specifiers are
+// %Y %y %m %b %h %B %d %e %j %a %A %H %I %M %S %p %z %Z and %%.
+// It returns *FormatError if format contains an unknown specifier,
+// or literal text which the time package would read as reference layout tokens, e.g. 1 of `1%H`,
+// since Go reference layouts can not escape them. ParseStrftime accepts such format.
+func StrftimeToGoLayout(format string) (string, error) {
+	chunks, err := strftimeChunks(format)
+	if err != nil {
+		return "", err
+	}
+	goLayout, err := chunksToGoLayout(format, chunks)
+	if err != nil {
+		return "", err
+	}
+	if !goLayoutMatches(goLayout, chunks) {
+		idx := misreadLiteral(chunks)
+		return "", &FormatError{
+			layout:   format,
+			idx:      idx,
+			expected: "must be literal text which is not a Go reference layout token",
+			actual:   format[idx:],
+			msg:      "Go reference layouts can not escape it. use ParseStrftime.",
+		}
+	}
+	return goLayout, nil
+}
+
+// misreadLiteral returns the offset of the literal chunk which makes the Go reference layout of chunks misread,
+// see goLayoutMatches.
+func misreadLiteral(chunks []layoutChunk) int {
+	for k := 1; k <= len(chunks); k++ {
+		prefix := chunks[:k]
+		goLayout, _ := chunksToGoLayout("", prefix)
+		if goLayoutMatches(goLayout, prefix) {
+			continue
+		}
+		if c := chunks[k-1]; !c.isToken() || k == 1 {
+			return c.offset
+		}
+		return chunks[k-2].offset
+	}
+	return 0
+}
+
+// ParseStrftime parses value by strftime style format.
+// Unlike time.Parse with the layout returned from StrftimeToGoLayout,
+// literal text of format always matches itself, e.g. 1 of `1%H`.
+// It returns *time.ParseError if value does not match format.
+func ParseStrftime(format, value string) (time.Time, error) {
+	chunks, err := strftimeChunks(format)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return parseChunks(format, chunks, value, time.UTC, time.Local, parseOptions{})
+}
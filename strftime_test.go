@@ -0,0 +1,71 @@
+package flextime_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ngicks/flextime"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStrftimeToGoLayout(t *testing.T) {
+	cases := []replaceTimeTokenTestCase{
+		{
+			input:    "%Y-%m-%d %H:%M:%S",
+			expected: "2006-01-02 15:04:05",
+		},
+		{
+			input:    "%a, %d %b %Y %I:%M:%S %p %z",
+			expected: "Mon, 02 Jan 2006 03:04:05 PM -0700",
+		},
+		{
+			input:    "%A %B %e %y %j %Z",
+			expected: "Monday January _2 06 002 MST",
+		},
+		{
+			input:    "%%%H at %M",
+			expected: "%15 at 04",
+		},
+	}
+
+	for _, testCase := range cases {
+		out, err := flextime.StrftimeToGoLayout(testCase.input)
+		require.NoError(t, err)
+		assert.Equal(t, testCase.expected, out)
+	}
+}
+
+func TestStrftimeToGoLayoutError(t *testing.T) {
+	for _, invalid := range []string{"%Y-%Q", "%Y-%"} {
+		_, err := flextime.StrftimeToGoLayout(invalid)
+		var formatErr *flextime.FormatError
+		require.ErrorAs(t, err, &formatErr)
+		assert.Contains(t, err.Error(), "index [3]")
+	}
+
+	// literal text read as Go reference layout tokens can not be converted.
+	for _, misread := range []string{"%H:%M 100%%", "%H:%M Monday"} {
+		_, err := flextime.StrftimeToGoLayout(misread)
+		var formatErr *flextime.FormatError
+		require.ErrorAs(t, err, &formatErr, "format = %s", misread)
+		assert.Contains(t, err.Error(), "index [5]", "format = %s", misread)
+	}
+}
+
+func TestParseStrftime(t *testing.T) {
+	parsed, err := flextime.ParseStrftime("%Y-%m-%d %H:%M:%S %z", "2022-10-20 23:16:22 +0900")
+	require.NoError(t, err)
+	assert.True(t, time.Date(2022, time.October, 20, 23, 16, 22, 0, jst).Equal(parsed))
+
+	// literal digits and words match themselves.
+	parsed, err = flextime.ParseStrftime("100%% at %H:%M on day %j of %Y", "100% at 23:16 on day 293 of 2022")
+	require.NoError(t, err)
+	assert.True(t, time.Date(2022, time.October, 20, 23, 16, 0, 0, time.UTC).Equal(parsed), "parsed = %s", parsed)
+	_, err = flextime.ParseStrftime("100%% at %H", "200% at 23")
+	assert.Error(t, err)
+
+	_, err = flextime.ParseStrftime("%Y-%m-%d", "2022/10/20")
+	var parseErr *time.ParseError
+	assert.ErrorAs(t, err, &parseErr)
+}
@@ -0,0 +1,91 @@
+package flextime
+
+import optionalstring "github.com/ngicks/flextime/optional_string"
+
+// Token is a piece of a flextime layout returned from Tokenize.
+type Token struct {
+	// Raw is the source text of the token in the layout, including quotes and backslashes.
+	Raw string
+	// Value is the time token itself if IsTimeToken is true,
+	// or the unescaped literal string otherwise.
+	Value string
+	// IsTimeToken is true if the token is a time token, false if it is a literal string.
+	IsTimeToken bool
+	// Computed is true if the token is a time token which has no Go reference layout equivalent.
+	Computed bool
+	// GoLayout is the Go reference layout equivalent of the time token.
+	// It is empty for literal strings and computed tokens.
+	GoLayout string
+	// Offset is the byte offset of Raw in the layout.
+	Offset int
+}
+
+// Tokenize splits flexLayout into tokens.
+// Optional parts and alternations are not interpreted:
+// `[`, `]`, `(`, `|` and `)` are left as parts of literal strings.
+// It returns *FormatError if flexLayout contains an invalid token.
+func Tokenize(flexLayout string) ([]Token, error) {
+	tables := defaultTables()
+	var tokens []Token
+	var offset int
+	input := flexLayout
+	for len(input) > 0 {
+		prefix, found, suffix, isToken, err := tables.nextChunk(input)
+		if err != nil {
+			return nil, relocateFormatError(err, offset, flexLayout)
+		}
+		if prefix != "" {
+			tokens = append(tokens, Token{Raw: prefix, Value: prefix, Offset: offset})
+		}
+		foundOffset := offset + len(prefix)
+		foundRaw := flexLayout[foundOffset : len(flexLayout)-len(suffix)]
+		switch {
+		case isToken:
+			token := timeFormatToken(found)
+			t := Token{
+				Raw:         foundRaw,
+				Value:       found,
+				IsTimeToken: true,
+				Computed:    tables.isComputed(token),
+				Offset:      foundOffset,
+			}
+			if !t.Computed {
+				t.GoLayout = tables.toGoFmt(token)
+			}
+			tokens = append(tokens, t)
+		case foundRaw != "":
+			tokens = append(tokens, Token{Raw: foundRaw, Value: found, Offset: foundOffset})
+		}
+		offset += len(input) - len(suffix)
+		input = suffix
+	}
+	return tokens, nil
+}
+
+// ValidateFlexLayout returns the first *FormatError found by tokenizing flexLayout, or nil.
+// It is cheaper than Compile since it neither enumerates optional parts nor converts tokens.
+//
+// It only checks tokenization.
+// It does not check the syntax of optional parts and alternations,
+// nor semantic validity, e.g. conflicting tokens. See Lint for the latter.
+func ValidateFlexLayout(flexLayout string) error {
+	_, err := Tokenize(flexLayout)
+	return err
+}
+
+// Explain splits flexLayout into the always present part and top level optional parts,
+// e.g. `YYYY-MM-DD[THH[:mm]]` is explained as `YYYY-MM-DD` and [`THH[:mm]`].
+// See optionalstring.Explain for details.
+//
+// It returns *optionalstring.SyntaxError if flexLayout has unbalanced optional parts,
+// or *FormatError if it contains an invalid token.
+func Explain(flexLayout string) (required string, optionals []string, err error) {
+	required, optionals, err = optionalstring.Explain(flexLayout)
+	if err != nil {
+		return "", nil, err
+	}
+	if err := ValidateFlexLayout(flexLayout); err != nil {
+		return "", nil, err
+	}
+	return required, optionals, nil
+}
@@ -0,0 +1,82 @@
+package flextime_test
+
+import (
+	"testing"
+
+	"github.com/ngicks/flextime"
+	optionalstring "github.com/ngicks/flextime/optional_string"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTokenize(t *testing.T) {
+	tokens, err := flextime.Tokenize(`YYYY-MM-DD'T'HH\:mm.SSS Do`)
+	require.NoError(t, err)
+	assert.Equal(t, []flextime.Token{
+		{Raw: "YYYY", Value: "YYYY", IsTimeToken: true, GoLayout: "2006", Offset: 0},
+		{Raw: "-", Value: "-", Offset: 4},
+		{Raw: "MM", Value: "MM", IsTimeToken: true, GoLayout: "01", Offset: 5},
+		{Raw: "-", Value: "-", Offset: 7},
+		{Raw: "DD", Value: "DD", IsTimeToken: true, GoLayout: "02", Offset: 8},
+		{Raw: "'T'", Value: "T", Offset: 10},
+		{Raw: "HH", Value: "HH", IsTimeToken: true, GoLayout: "15", Offset: 13},
+		{Raw: `\:`, Value: ":", Offset: 15},
+		{Raw: "mm", Value: "mm", IsTimeToken: true, GoLayout: "04", Offset: 17},
+		{Raw: ".SSS", Value: ".SSS", IsTimeToken: true, GoLayout: ".000", Offset: 19},
+		{Raw: " ", Value: " ", Offset: 23},
+		{Raw: "Do", Value: "Do", IsTimeToken: true, Computed: true, Offset: 24},
+	}, tokens)
+
+	var raw string
+	for _, token := range tokens {
+		raw += token.Raw
+	}
+	assert.Equal(t, `YYYY-MM-DD'T'HH\:mm.SSS Do`, raw)
+
+	tokens, err = flextime.Tokenize(`HH[:mm]`)
+	require.NoError(t, err)
+	assert.Equal(t, []flextime.Token{
+		{Raw: "HH", Value: "HH", IsTimeToken: true, GoLayout: "15", Offset: 0},
+		{Raw: "[:", Value: "[:", Offset: 2},
+		{Raw: "mm", Value: "mm", IsTimeToken: true, GoLayout: "04", Offset: 4},
+		{Raw: "]", Value: "]", Offset: 6},
+	}, tokens)
+
+	_, err = flextime.Tokenize("YYYY-MM-DD SSSS")
+	var formatErr *flextime.FormatError
+	require.ErrorAs(t, err, &formatErr)
+}
+
+func TestValidateFlexLayout(t *testing.T) {
+	for _, valid := range []string{
+		"",
+		"YYYY-MM-DDTHH:mm:ss.SSSZ",
+		"YYYY-MM-DD[THH[:mm]]",
+		"'YYY'",
+		// not checked
+		"YYYY[",
+		"YYYY YY",
+	} {
+		assert.NoError(t, flextime.ValidateFlexLayout(valid), "layout = %s", valid)
+	}
+
+	var formatErr *flextime.FormatError
+	for _, invalid := range []string{"YYY", "YYYY-MM-DD[TSSSS]", "Y"} {
+		assert.ErrorAs(t, flextime.ValidateFlexLayout(invalid), &formatErr, "layout = %s", invalid)
+	}
+}
+
+func TestExplain(t *testing.T) {
+	required, optionals, err := flextime.Explain(`YYYY-MM-DD[THH:mm[:ss]][Z]`)
+	require.NoError(t, err)
+	assert.Equal(t, "YYYY-MM-DD", required)
+	assert.Equal(t, []string{"THH:mm[:ss]", "Z"}, optionals)
+
+	_, _, err = flextime.Explain(`YYYY-MM-DD[THH`)
+	var syntaxErr *optionalstring.SyntaxError
+	assert.ErrorAs(t, err, &syntaxErr)
+
+	_, _, err = flextime.Explain(`YYYY-MM-DD[TSSSS]`)
+	var formatErr *flextime.FormatError
+	assert.ErrorAs(t, err, &formatErr)
+}
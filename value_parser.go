@@ -0,0 +1,942 @@
+package flextime
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// errBad is returned from field parsers when value does not match to the token.
+var errBad = errors.New("bad value for field")
+
+// rangeError is returned from field parsers when a parsed field is out of range.
+type rangeError string
+
+func (e rangeError) Error() string {
+	return string(e) + " out of range"
+}
+
+// mismatchError is returned from parsedFields.time when parsed fields contradict each other.
+// Its message contains "does not match", as the standard time package reports the same kind of error.
+type mismatchError string
+
+func (e mismatchError) Error() string {
+	return string(e)
+}
+
+// isMismatch reports whether err is caused by contradicting fields, e.g. day-of-year and month.
+// The standard time package does not export its error,
+// so it is detected by the message shared with mismatchError.
+func isMismatch(err error) bool {
+	var parseErr *time.ParseError
+	return errors.As(err, &parseErr) && strings.Contains(parseErr.Message, "does not match")
+}
+
+var (
+	longMonthNames = []string{
+		"January", "February", "March", "April", "May", "June",
+		"July", "August", "September", "October", "November", "December",
+	}
+	shortMonthNames = []string{
+		"Jan", "Feb", "Mar", "Apr", "May", "Jun",
+		"Jul", "Aug", "Sep", "Oct", "Nov", "Dec",
+	}
+	longDayNames = []string{
+		"Sunday", "Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday",
+	}
+	shortDayNames = []string{
+		"Sun", "Mon", "Tue", "Wed", "Thu", "Fri", "Sat",
+	}
+)
+
+// parsedFields holds fields of time read from a value.
+// Negative values mean unset.
+type parsedFields struct {
+	year       int
+	month      int
+	day        int
+	yday       int
+	quarter    int
+	isoYear    int
+	isoYearSet bool
+	isoWeek    int
+	isoWeekday int
+	// ydayToken is the flextime token which set yday, used in error messages.
+	ydayToken timeFormatToken
+	// weekday is set by numeric weekday tokens, E and c, and checked against the date.
+	weekday int
+	// namedWeekday is set by weekday name tokens, ww and w.
+	// Like the standard time package, it is not checked against the date.
+	namedWeekday int
+	// weekOfMonth is set by W. With a day of week, it decides the day if no day is given.
+	weekOfMonth int
+	// bc is set by era token G. year is then counted backward from 1 BC.
+	bc bool
+	// epoch is Unix time, in unit of epochUnit.
+	epoch      int64
+	epochUnit  time.Duration
+	hour       int
+	min        int
+	sec        int
+	nsec       int
+	pmSet      bool
+	amSet      bool
+	z          *time.Location
+	zoneOffset int
+	zoneName   string
+	// allowLeapSecond makes second tokens accept 60, see AcceptLeapSecond.
+	allowLeapSecond bool
+	// leapSecond is set if second tokens read 60.
+	leapSecond bool
+}
+
+func newParsedFields() *parsedFields {
+	return &parsedFields{
+		month:        -1,
+		day:          -1,
+		yday:         -1,
+		quarter:      -1,
+		isoWeek:      -1,
+		isoWeekday:   -1,
+		weekday:      -1,
+		namedWeekday: -1,
+		weekOfMonth:  -1,
+		zoneOffset:   -1,
+	}
+}
+
+// parseChunks parses value by chunks.
+// Unlike time.Parse, it is able to parse computed tokens.
+// defaultLoc and local have same meanings as the standard time package's ones:
+// defaultLoc is used when value has no zone information,
+// local is used to look up a zone matching the parsed offset or abbreviation.
+//
+// Errors are reported as *time.ParseError whose Layout is flexLayout and LayoutElem is the flextime token.
+func parseChunks(
+	flexLayout string,
+	chunks []layoutChunk,
+	value string,
+	defaultLoc, local *time.Location,
+	opts parseOptions,
+) (time.Time, error) {
+	f := newParsedFields()
+	f.allowLeapSecond = opts.leapSecond
+	rest := value
+	for i, c := range chunks {
+		var err error
+		if !c.isToken() {
+			switch {
+			case opts.flexibleSpace && c.quoted:
+				rest, err = skipExact(rest, c.literal)
+			case opts.flexibleSpace:
+				rest, err = skipFlexibleSpace(rest, c.literal)
+			default:
+				rest, err = skip(rest, c.literal)
+			}
+			if err != nil {
+				return time.Time{}, newParseError(flexLayout, value, c.literal, rest, err)
+			}
+			continue
+		}
+		hold := rest
+		if opts.ignoreWeekday && isWeekdayChunk(c) {
+			rest, err = skipWeekday(rest, c.token == "E" || c.token == "c")
+		} else if computed, ok := c.computedToken(); ok {
+			rest, err = computed.parse(rest, f)
+		} else if opts.caseInsensitiveMeridiem && (c.token == "A" || c.token == "a") {
+			rest, err = f.parseMeridiemFold(rest)
+		} else if opts.rfc2822Zones && c.goFmt == "MST" {
+			rest, err = f.parseRFC2822Zone(rest)
+		} else if opts.locale != nil && opts.locale.names(c.goFmt) != nil {
+			rest, err = f.parseName(opts.locale, c.goFmt, rest)
+		} else {
+			nextIsFrac := i+1 < len(chunks) && isFracToken(chunks[i+1].token)
+			goFmt := c.goFmt
+			if goFmt == "002" || goFmt == "__2" {
+				f.ydayToken = c.token
+			}
+			if opts.offsetPrefix && isNumericOffset(goFmt) {
+				rest = trimOffsetPrefix(rest)
+			}
+			rest, err = f.parseStd(goFmt, rest, nextIsFrac)
+		}
+		if err != nil {
+			return time.Time{}, newParseError(flexLayout, value, string(c.token), hold, err)
+		}
+	}
+	if len(rest) > 0 {
+		return time.Time{}, &time.ParseError{
+			Layout:    flexLayout,
+			Value:     value,
+			ValueElem: rest,
+			Message:   ": extra text: " + quote(rest),
+		}
+	}
+	t, err := f.time(defaultLoc, local)
+	if err != nil {
+		return time.Time{}, newParseError(flexLayout, value, "", rest, err)
+	}
+	return t, nil
+}
+
+// isWeekdayChunk reports whether c is a day of week token, ww, w, WEEKDAY, E or c, or an alias of them.
+func isWeekdayChunk(c layoutChunk) bool {
+	switch c.token {
+	case "WEEKDAY", "E", "c":
+		return true
+	}
+	return c.goFmt == "Monday" || c.goFmt == "Mon"
+}
+
+// skipWeekday skips a digit if numeric is true, a run of letters otherwise.
+func skipWeekday(value string, numeric bool) (rest string, err error) {
+	if numeric {
+		if !isDigit(value, 0) {
+			return value, errBad
+		}
+		return value[1:], nil
+	}
+	i := 0
+	for i < len(value) {
+		r, size := utf8.DecodeRuneInString(value[i:])
+		if !unicode.IsLetter(r) {
+			break
+		}
+		i += size
+	}
+	if i == 0 {
+		return value, errBad
+	}
+	return value[i:], nil
+}
+
+func newParseError(layout, value, layoutElem, valueElem string, err error) *time.ParseError {
+	parseErr := &time.ParseError{
+		Layout:     layout,
+		Value:      value,
+		LayoutElem: layoutElem,
+		ValueElem:  valueElem,
+	}
+	if err != errBad {
+		parseErr.Message = ": " + err.Error()
+	}
+	return parseErr
+}
+
+func quote(s string) string {
+	return `"` + s + `"`
+}
+
+func isFracToken(token timeFormatToken) bool {
+	return strings.HasPrefix(string(token), ".") || strings.HasPrefix(string(token), ",")
+}
+
+// parseStd parses value by a Go reference layout chunk goFmt and stores the result to f.
+// The logic is same as the standard time package.
+func (f *parsedFields) parseStd(goFmt string, value string, nextIsFrac bool) (rest string, err error) {
+	_, std, _ := NextStdChunk(goFmt)
+	switch std {
+	case StdYear:
+		if len(value) < 2 {
+			return value, errBad
+		}
+		var year int
+		year, err = atoi(value[0:2])
+		if err != nil {
+			return value, err
+		}
+		if year >= 69 { // Unix time starts Dec 31 1969 in some time zones
+			year += 1900
+		} else {
+			year += 2000
+		}
+		f.year = year
+		return value[2:], nil
+	case StdLongYear:
+		f.year, rest, err = parseLongYear(value)
+		return rest, err
+	case StdMonth:
+		f.month, rest, err = lookup(shortMonthNames, value)
+		f.month++
+		return rest, err
+	case StdLongMonth:
+		f.month, rest, err = lookup(longMonthNames, value)
+		f.month++
+		return rest, err
+	case StdNumMonth, StdZeroMonth:
+		f.month, rest, err = getnum(value, std == StdZeroMonth)
+		if err == nil && (f.month <= 0 || 12 < f.month) {
+			err = rangeError("month")
+		}
+		return rest, err
+	case StdWeekDay:
+		// Ignore weekday except for error checking and week of month.
+		f.namedWeekday, rest, err = lookup(shortDayNames, value)
+		return rest, err
+	case StdLongWeekDay:
+		f.namedWeekday, rest, err = lookup(longDayNames, value)
+		return rest, err
+	case StdDay, StdUnderDay, StdZeroDay:
+		if std == StdUnderDay && len(value) > 0 && value[0] == ' ' {
+			value = value[1:]
+		}
+		// Note that we allow any one- or two-digit day here.
+		// The month, day, year combination is validated after we've completed parsing.
+		f.day, rest, err = getnum(value, std == StdZeroDay)
+		return rest, err
+	case StdUnderYearDay, StdZeroYearDay:
+		for i := 0; i < 2; i++ {
+			if std == StdUnderYearDay && len(value) > 0 && value[0] == ' ' {
+				value = value[1:]
+			}
+		}
+		f.yday, rest, err = getnum3(value, std == StdZeroYearDay)
+		// Note that we allow any one-, two-, or three-digit year-day here.
+		// The year-day, year combination is validated after we've completed parsing.
+		if err == nil && (f.yday < 1 || 366 < f.yday) {
+			err = rangeError("day-of-year")
+		}
+		return rest, err
+	case StdHour:
+		f.hour, rest, err = getnum(value, false)
+		if err == nil && (f.hour < 0 || 24 <= f.hour) {
+			err = rangeError("hour")
+		}
+		return rest, err
+	case StdHour12, StdZeroHour12:
+		f.hour, rest, err = getnum(value, std == StdZeroHour12)
+		if err == nil && (f.hour < 0 || 12 < f.hour) {
+			err = rangeError("hour")
+		}
+		return rest, err
+	case StdMinute, StdZeroMinute:
+		f.min, rest, err = getnum(value, std == StdZeroMinute)
+		if err == nil && (f.min < 0 || 60 <= f.min) {
+			err = rangeError("minute")
+		}
+		return rest, err
+	case StdSecond, StdZeroSecond:
+		f.sec, rest, err = getnum(value, std == StdZeroSecond)
+		if err != nil {
+			return rest, err
+		}
+		if f.sec == 60 && f.allowLeapSecond {
+			f.leapSecond = true
+		} else if f.sec < 0 || 60 <= f.sec {
+			return rest, rangeError("second")
+		}
+		// Special case: do we have a fractional second but no
+		// fractional second in the format?
+		if !nextIsFrac && len(rest) >= 2 && commaOrPeriod(rest[0]) && isDigit(rest, 1) {
+			n := 2
+			for ; n < len(rest) && isDigit(rest, n); n++ {
+			}
+			f.nsec, err = parseNanoseconds(rest, n)
+			return rest[n:], err
+		}
+		return rest, nil
+	case StdPM:
+		if len(value) < 2 {
+			return value, errBad
+		}
+		switch value[0:2] {
+		case "PM":
+			f.pmSet = true
+		case "AM":
+			f.amSet = true
+		default:
+			return value, errBad
+		}
+		return value[2:], nil
+	case StdLowerPM:
+		if len(value) < 2 {
+			return value, errBad
+		}
+		switch value[0:2] {
+		case "pm":
+			f.pmSet = true
+		case "am":
+			f.amSet = true
+		default:
+			return value, errBad
+		}
+		return value[2:], nil
+	case StdISO8601TZ, StdISO8601ShortTZ, StdISO8601ColonTZ, StdISO8601SecondsTZ, StdISO8601ColonSecondsTZ,
+		StdNumTZ, StdNumShortTZ, StdNumColonTZ, StdNumSecondsTZ, StdNumColonSecondsTZ:
+		if (std == StdISO8601TZ || std == StdISO8601ShortTZ || std == StdISO8601ColonTZ ||
+			std == StdISO8601SecondsTZ || std == StdISO8601ColonSecondsTZ) &&
+			len(value) >= 1 && value[0] == 'Z' {
+			f.z = time.UTC
+			return value[1:], nil
+		}
+		return f.parseNumTZ(std, value)
+	case StdTZ:
+		// Does it look like a time zone?
+		if len(value) >= 3 && value[0:3] == "UTC" {
+			f.z = time.UTC
+			return value[3:], nil
+		}
+		n, ok := parseTimeZone(value)
+		if !ok {
+			return value, errBad
+		}
+		f.zoneName, rest = value[:n], value[n:]
+		return rest, nil
+	case StdFracSecond0:
+		// StdFracSecond0 requires the exact number of digits as
+		// specified in the layout.
+		ndigit := len(goFmt)
+		if len(value) < ndigit {
+			return value, errBad
+		}
+		f.nsec, err = parseNanoseconds(value, ndigit)
+		return value[ndigit:], err
+	case StdFracSecond9:
+		if len(value) < 2 || !commaOrPeriod(value[0]) || value[1] < '0' || '9' < value[1] {
+			// Fractional second omitted.
+			return value, nil
+		}
+		// Take any number of digits, even more than asked for,
+		// because it is what the StdSecond case would do.
+		i := 0
+		for i+1 < len(value) && '0' <= value[i+1] && value[i+1] <= '9' {
+			i++
+		}
+		f.nsec, err = parseNanoseconds(value, 1+i)
+		return value[1+i:], err
+	}
+	return value, errBad
+}
+
+// isNumericOffset reports whether goFmt is one of numeric offset layouts, e.g. Z07:00 or -0700.
+func isNumericOffset(goFmt string) bool {
+	_, std, _ := NextStdChunk(goFmt)
+	switch std {
+	case StdISO8601TZ, StdISO8601ShortTZ, StdISO8601ColonTZ, StdISO8601SecondsTZ, StdISO8601ColonSecondsTZ,
+		StdNumTZ, StdNumShortTZ, StdNumColonTZ, StdNumSecondsTZ, StdNumColonSecondsTZ:
+		return true
+	}
+	return false
+}
+
+// trimOffsetPrefix removes UTC or GMT from value if it is followed by a sign of a numeric offset.
+func trimOffsetPrefix(value string) string {
+	if len(value) > 3 && (value[:3] == "UTC" || value[:3] == "GMT") && (value[3] == '+' || value[3] == '-') {
+		return value[3:]
+	}
+	return value
+}
+
+// parseMeridiemFold reads AM or PM in any case.
+func (f *parsedFields) parseMeridiemFold(value string) (rest string, err error) {
+	if len(value) < 2 {
+		return value, errBad
+	}
+	switch {
+	case strings.EqualFold(value[0:2], "PM"):
+		f.pmSet = true
+	case strings.EqualFold(value[0:2], "AM"):
+		f.amSet = true
+	default:
+		return value, errBad
+	}
+	return value[2:], nil
+}
+
+func (f *parsedFields) parseNumTZ(std int, value string) (rest string, err error) {
+	var sign, hour, min, seconds string
+	switch std {
+	case StdISO8601ColonTZ, StdNumColonTZ:
+		if len(value) < 6 || value[3] != ':' {
+			return value, errBad
+		}
+		sign, hour, min, seconds, rest = value[0:1], value[1:3], value[4:6], "00", value[6:]
+	case StdNumShortTZ, StdISO8601ShortTZ:
+		if len(value) < 3 {
+			return value, errBad
+		}
+		sign, hour, min, seconds, rest = value[0:1], value[1:3], "00", "00", value[3:]
+	case StdISO8601ColonSecondsTZ, StdNumColonSecondsTZ:
+		if len(value) < 9 || value[3] != ':' || value[6] != ':' {
+			return value, errBad
+		}
+		sign, hour, min, seconds, rest = value[0:1], value[1:3], value[4:6], value[7:9], value[9:]
+	case StdISO8601SecondsTZ, StdNumSecondsTZ:
+		if len(value) < 7 {
+			return value, errBad
+		}
+		sign, hour, min, seconds, rest = value[0:1], value[1:3], value[3:5], value[5:7], value[7:]
+	default:
+		if len(value) < 5 {
+			return value, errBad
+		}
+		sign, hour, min, seconds, rest = value[0:1], value[1:3], value[3:5], "00", value[5:]
+	}
+	var hr, mm, ss int
+	hr, _, err = getnum(hour, true)
+	if err == nil {
+		mm, _, err = getnum(min, true)
+	}
+	if err == nil {
+		ss, _, err = getnum(seconds, true)
+	}
+	if err != nil {
+		return value, errBad
+	}
+	// The range test use > rather than >=,
+	// as some people do write offsets of 24 hours
+	// or 60 minutes or 60 seconds.
+	if hr > 24 {
+		return value, rangeError("time zone offset hour")
+	}
+	if mm > 60 {
+		return value, rangeError("time zone offset minute")
+	}
+	if ss > 60 {
+		return value, rangeError("time zone offset second")
+	}
+	f.zoneOffset = (hr*60+mm)*60 + ss // offset is in seconds
+	switch sign[0] {
+	case '+':
+	case '-':
+		f.zoneOffset = -f.zoneOffset
+	default:
+		return value, errBad
+	}
+	return rest, nil
+}
+
+// time builds time.Time from parsed fields, validating them.
+func (f *parsedFields) time(defaultLoc, local *time.Location) (time.Time, error) {
+	switch f.epochUnit {
+	case time.Second:
+		return time.Unix(f.epoch, 0).In(defaultLoc), nil
+	case time.Millisecond:
+		return time.UnixMilli(f.epoch).In(defaultLoc), nil
+	}
+
+	if f.leapSecond {
+		// time.Time can not represent leap seconds. Clamp to the last instant of the preceding second.
+		f.sec, f.nsec = 59, int(time.Second-1)
+	}
+
+	year, month, day, hour := f.year, f.month, f.day, f.hour
+	if f.bc {
+		year = 1 - year
+	}
+	if f.pmSet && hour < 12 {
+		hour += 12
+	} else if f.amSet && hour == 12 {
+		hour = 0
+	}
+
+	// Convert yday to day, month.
+	if f.yday >= 0 {
+		d := time.Date(year, time.January, f.yday, 0, 0, 0, 0, time.UTC)
+		if d.Year() != year {
+			return time.Time{}, rangeError("day-of-year")
+		}
+		// If month, day already seen, yday's m, d must match.
+		if month >= 0 && month != int(d.Month()) {
+			return time.Time{}, mismatchError(fmt.Sprintf("day-of-year %s does not match month", f.ydayToken))
+		}
+		month = int(d.Month())
+		if day >= 0 && day != d.Day() {
+			return time.Time{}, mismatchError(fmt.Sprintf("day-of-year %s does not match day", f.ydayToken))
+		}
+		day = d.Day()
+	}
+
+	if f.isoWeek >= 0 {
+		d, err := f.isoWeekDate(year)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if (month >= 0 && month != int(d.Month())) || (day >= 0 && day != d.Day()) {
+			return time.Time{}, mismatchError("ISO week date does not match month and day")
+		}
+		year, month, day = d.Year(), int(d.Month()), d.Day()
+	}
+
+	if f.quarter >= 0 {
+		if month >= 0 && (month-1)/3+1 != f.quarter {
+			return time.Time{}, mismatchError("quarter does not match month")
+		}
+		if month < 0 {
+			month = (f.quarter-1)*3 + 1
+		}
+	}
+
+	if f.yday < 0 {
+		if month < 0 {
+			month = int(time.January)
+		}
+		if day < 0 && f.weekOfMonth >= 0 && f.isoWeek < 0 {
+			var err error
+			if day, err = f.dayOfWeekOfMonth(year, time.Month(month)); err != nil {
+				return time.Time{}, err
+			}
+		}
+		if day < 0 {
+			day = 1
+		}
+	}
+
+	// Validate the day of the month.
+	if day < 1 || day > daysIn(time.Month(month), year) {
+		return time.Time{}, rangeError("day")
+	}
+
+	// Validate the day of the week if the date is given.
+	if f.weekday >= 0 && (f.day >= 0 || f.yday >= 0 || f.isoWeek >= 0) {
+		if time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC).Weekday() != time.Weekday(f.weekday) {
+			return time.Time{}, mismatchError("day-of-week does not match")
+		}
+	}
+
+	if f.z != nil {
+		return time.Date(year, time.Month(month), day, hour, f.min, f.sec, f.nsec, f.z), nil
+	}
+
+	if f.zoneOffset != -1 {
+		t := time.Date(year, time.Month(month), day, hour, f.min, f.sec, f.nsec, time.UTC)
+		t = t.Add(-time.Duration(f.zoneOffset) * time.Second)
+
+		// Look for local zone with the given offset.
+		// If that zone was in effect at the given time, use it.
+		name, offset := t.In(local).Zone()
+		if offset == f.zoneOffset && (f.zoneName == "" || name == f.zoneName) {
+			return t.In(local), nil
+		}
+
+		// Otherwise create fake zone to record offset.
+		return t.In(time.FixedZone(f.zoneName, f.zoneOffset)), nil
+	}
+
+	if f.zoneName != "" {
+		// Look for local zone with the given abbreviation.
+		t := time.Date(year, time.Month(month), day, hour, f.min, f.sec, f.nsec, local)
+		if name, _ := t.Zone(); name == f.zoneName {
+			return t, nil
+		}
+
+		// Otherwise, create fake zone with unknown offset.
+		var offset int
+		if len(f.zoneName) > 3 && f.zoneName[:3] == "GMT" {
+			offset, _ = atoi(f.zoneName[3:]) // Guaranteed OK by parseGMT.
+			offset *= 3600
+		}
+		// The standard time package does not adjust the instant by the offset here.
+		// Do the same so that both parsers agree.
+		t = time.Date(year, time.Month(month), day, hour, f.min, f.sec, f.nsec, time.UTC)
+		return t.In(time.FixedZone(f.zoneName, offset)), nil
+	}
+
+	// Otherwise, fall back to default.
+	return time.Date(year, time.Month(month), day, hour, f.min, f.sec, f.nsec, defaultLoc), nil
+}
+
+// isoWeekDate returns the date specified by ISO week-numbering year, week and weekday.
+// year is used when ISO week-numbering year is not parsed.
+// Weekday defaults to Monday.
+func (f *parsedFields) isoWeekDate(year int) (time.Time, error) {
+	isoYear, weekday := f.isoYear, f.isoWeekday
+	if !f.isoYearSet {
+		isoYear = year
+	}
+	if weekday < 0 && f.weekday >= 0 {
+		weekday = isoWeekday(time.Weekday(f.weekday))
+	}
+	if weekday < 0 {
+		weekday = 1
+	}
+	// January 4th is always in the first week.
+	jan4 := time.Date(isoYear, time.January, 4, 0, 0, 0, 0, time.UTC)
+	firstMonday := jan4.AddDate(0, 0, -((int(jan4.Weekday()) + 6) % 7))
+	d := firstMonday.AddDate(0, 0, (f.isoWeek-1)*7+weekday-1)
+	if y, w := d.ISOWeek(); y != isoYear || w != f.isoWeek {
+		return time.Time{}, rangeError("ISO week")
+	}
+	return d, nil
+}
+
+// dayOfWeekOfMonth returns the day in the week of month f.weekOfMonth which falls on the day of week parsed,
+// or -1 if no day of week is parsed.
+func (f *parsedFields) dayOfWeekOfMonth(year int, month time.Month) (int, error) {
+	weekday := f.weekday
+	if weekday < 0 {
+		weekday = f.namedWeekday
+	}
+	if weekday < 0 {
+		return -1, nil
+	}
+	start := (f.weekOfMonth-1)*7 + 1
+	first := time.Date(year, month, start, 0, 0, 0, 0, time.UTC).Weekday()
+	day := start + (weekday-int(first)+7)%7
+	if day > daysIn(month, year) {
+		return -1, rangeError("week of month")
+	}
+	return day, nil
+}
+
+func daysIn(m time.Month, year int) int {
+	return time.Date(year, m+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+func commaOrPeriod(b byte) bool {
+	return b == '.' || b == ','
+}
+
+// match reports whether s1 and s2 match ignoring case.
+// It is assumed s1 and s2 are the same length.
+func match(s1, s2 string) bool {
+	for i := 0; i < len(s1); i++ {
+		c1 := s1[i]
+		c2 := s2[i]
+		if c1 != c2 {
+			// Switch to lower-case; 'a'-'A' is known to be a single bit.
+			c1 |= 'a' - 'A'
+			c2 |= 'a' - 'A'
+			if c1 != c2 || c1 < 'a' || c1 > 'z' {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func lookup(tab []string, val string) (int, string, error) {
+	for i, v := range tab {
+		if len(val) >= len(v) && match(val[:len(v)], v) {
+			return i, val[len(v):], nil
+		}
+	}
+	return -1, val, errBad
+}
+
+// parseLongYear reads a 4 digit year.
+// Unlike the time package, a leading '-' is accepted
+// since time.Time.Format formats negative years like -0044.
+func parseLongYear(value string) (year int, rest string, err error) {
+	return parseYearWidth(value, 4)
+}
+
+// parseYearWidth reads a year of exactly width digits, optionally preceded by '-'.
+// The sign is not counted in width.
+func parseYearWidth(value string, width int) (year int, rest string, err error) {
+	digits := strings.TrimPrefix(value, "-")
+	if len(digits) < width || !isDigit(digits, 0) {
+		return 0, value, errBad
+	}
+	n := len(value) - len(digits) + width
+	year, err = atoi(value[:n])
+	if err != nil {
+		return 0, value, err
+	}
+	return year, value[n:], nil
+}
+
+// atoi parses s as a possibly signed decimal integer.
+func atoi(s string) (x int, err error) {
+	neg := false
+	if s != "" && (s[0] == '-' || s[0] == '+') {
+		neg = s[0] == '-'
+		s = s[1:]
+	}
+	if s == "" {
+		return 0, errBad
+	}
+	for i := 0; i < len(s); i++ {
+		if !isDigit(s, i) {
+			return 0, errBad
+		}
+		x = x*10 + int(s[i]-'0')
+		if x > 1<<31 {
+			return 0, errBad
+		}
+	}
+	if neg {
+		x = -x
+	}
+	return x, nil
+}
+
+// getnum parses s[0:1] or s[0:2] (fixed forces s[0:2])
+// as a decimal integer and returns the integer and the
+// remainder of the string.
+func getnum(s string, fixed bool) (int, string, error) {
+	if !isDigit(s, 0) {
+		return 0, s, errBad
+	}
+	if !isDigit(s, 1) {
+		if fixed {
+			return 0, s, errBad
+		}
+		return int(s[0] - '0'), s[1:], nil
+	}
+	return int(s[0]-'0')*10 + int(s[1]-'0'), s[2:], nil
+}
+
+// getnum3 parses s[0:1], s[0:2], or s[0:3] (fixed forces s[0:3])
+// as a decimal integer and returns the integer and the remainder
+// of the string.
+func getnum3(s string, fixed bool) (int, string, error) {
+	var n, i int
+	for i = 0; i < 3 && isDigit(s, i); i++ {
+		n = n*10 + int(s[i]-'0')
+	}
+	if i == 0 || fixed && i != 3 {
+		return 0, s, errBad
+	}
+	return n, s[i:], nil
+}
+
+func cutspace(s string) string {
+	for len(s) > 0 && s[0] == ' ' {
+		s = s[1:]
+	}
+	return s
+}
+
+// skip removes the given prefix from value,
+// treating runs of space characters as equivalent.
+func skip(value, prefix string) (string, error) {
+	for len(prefix) > 0 {
+		if prefix[0] == ' ' {
+			if len(value) > 0 && value[0] != ' ' {
+				return value, errBad
+			}
+			prefix = cutspace(prefix)
+			value = cutspace(value)
+			continue
+		}
+		if len(value) == 0 || value[0] != prefix[0] {
+			return value, errBad
+		}
+		prefix = prefix[1:]
+		value = value[1:]
+	}
+	return value, nil
+}
+
+// skipExact removes prefix from value. It does not treat spaces specially.
+func skipExact(value, prefix string) (string, error) {
+	if !strings.HasPrefix(value, prefix) {
+		return value, errBad
+	}
+	return value[len(prefix):], nil
+}
+
+const whitespaces = " \t\n\v\f\r"
+
+// skipFlexibleSpace is like skip but treats runs of any whitespace characters as equivalent.
+func skipFlexibleSpace(value, prefix string) (string, error) {
+	for len(prefix) > 0 {
+		if strings.IndexByte(whitespaces, prefix[0]) >= 0 {
+			if len(value) > 0 && strings.IndexByte(whitespaces, value[0]) < 0 {
+				return value, errBad
+			}
+			prefix = strings.TrimLeft(prefix, whitespaces)
+			value = strings.TrimLeft(value, whitespaces)
+			continue
+		}
+		if len(value) == 0 || value[0] != prefix[0] {
+			return value, errBad
+		}
+		prefix = prefix[1:]
+		value = value[1:]
+	}
+	return value, nil
+}
+
+func parseNanoseconds(value string, nbytes int) (ns int, err error) {
+	if !commaOrPeriod(value[0]) {
+		return 0, errBad
+	}
+	if nbytes > 10 {
+		value = value[:10]
+		nbytes = 10
+	}
+	if ns, err = atoi(value[1:nbytes]); err != nil {
+		return 0, err
+	}
+	if ns < 0 {
+		return 0, rangeError("fractional second")
+	}
+	// We need nanoseconds, which means scaling by the number
+	// of missing digits in the format, maximum length 10.
+	scaleDigits := 10 - nbytes
+	for i := 0; i < scaleDigits; i++ {
+		ns *= 10
+	}
+	return ns, nil
+}
+
+// parseTimeZone parses a time zone string and returns its length.
+func parseTimeZone(value string) (length int, ok bool) {
+	if len(value) < 3 {
+		return 0, false
+	}
+	// Special case 1: ChST and MeST are the only zones with a lower-case letter.
+	if len(value) >= 4 && (value[:4] == "ChST" || value[:4] == "MeST") {
+		return 4, true
+	}
+	// Special case 2: GMT may have an hour offset; treat it specially.
+	if value[:3] == "GMT" {
+		return 3 + parseSignedOffset(value[3:]), true
+	}
+	// Special Case 3: Some time zones are not named, but have +/-00 format
+	if value[0] == '+' || value[0] == '-' {
+		length = parseSignedOffset(value)
+		return length, length > 0
+	}
+	// How many upper-case letters are there? Need at least three, at most five.
+	var nUpper int
+	for nUpper = 0; nUpper < 6; nUpper++ {
+		if nUpper >= len(value) {
+			break
+		}
+		if c := value[nUpper]; c < 'A' || 'Z' < c {
+			break
+		}
+	}
+	switch nUpper {
+	case 0, 1, 2, 6:
+		return 0, false
+	case 5: // Must end in T to match.
+		if value[4] == 'T' {
+			return 5, true
+		}
+	case 4:
+		// Must end in T, except one special case.
+		if value[3] == 'T' || value[:4] == "WITA" {
+			return 4, true
+		}
+	case 3:
+		return 3, true
+	}
+	return 0, false
+}
+
+// parseSignedOffset parses a signed timezone offset (e.g. "+03" or "-04").
+// The function checks for a signed number in the range -23 through +23 excluding zero.
+// Returns length of the found offset string or 0 otherwise.
+func parseSignedOffset(value string) int {
+	if len(value) == 0 || (value[0] != '-' && value[0] != '+') {
+		return 0
+	}
+	i := 1
+	for ; i < len(value) && isDigit(value, i); i++ {
+	}
+	if i == 1 {
+		return 0
+	}
+	x, err := atoi(value[1:i])
+	if err != nil || x > 23 {
+		return 0
+	}
+	return i
+}